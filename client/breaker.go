@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default circuit breaker parameters, see CircuitBreaker.
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 30 * time.Second
+)
+
+// ErrCircuitOpen is the error returned for the requests made to a host while its circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// timeNow is the clock used by the circuit breakers and the token sources, tests override it.
+var timeNow = time.Now
+
+// CircuitBreaker stops sending requests to the hosts that keep failing. A request fails if it
+// returns a transport error or a 5xx response. The circuit of a host opens after FailureThreshold
+// consecutive failures: the requests made to the host fail with ErrCircuitOpen without being sent.
+// After OpenTimeout the circuit is half-open: a single trial request is sent, its success closes
+// the circuit and its failure opens it again. The zero value of each field selects the default
+// value. A CircuitBreaker must not be copied after first use.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit of a host.
+	FailureThreshold int
+	// OpenTimeout is the duration the circuit stays open before a trial request is sent.
+	OpenTimeout time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+// circuit is the state of the circuit of a host.
+type circuit struct {
+	failures int
+	openedAt time.Time // zero if closed
+	trial    bool      // true while the trial request of a half-open circuit is in flight
+}
+
+// CircuitBreak returns a middleware that breaks the circuits of the failing hosts with cb.
+func CircuitBreak(cb *CircuitBreaker) Middleware {
+	return func(d Doer) Doer {
+		return DoFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			host := req.URL.Host
+			if !cb.allow(host) {
+				return nil, ErrCircuitOpen
+			}
+			resp, err := d.Do(ctx, req)
+			cb.record(host, err == nil && resp.StatusCode < 500)
+			return resp, err
+		})
+	}
+}
+
+// allow reports whether a request may be sent to host.
+func (cb *CircuitBreaker) allow(host string) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(host)
+	if c.openedAt.IsZero() {
+		return true
+	}
+	timeout := cb.OpenTimeout
+	if timeout <= 0 {
+		timeout = DefaultOpenTimeout
+	}
+	if c.trial || timeNow().Sub(c.openedAt) < timeout {
+		return false
+	}
+	c.trial = true
+	return true
+}
+
+// record updates the circuit of host with the outcome of a request.
+func (cb *CircuitBreaker) record(host string, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(host)
+	if success {
+		*c = circuit{}
+		return
+	}
+	threshold := cb.FailureThreshold
+	if threshold <= 0 {
+		threshold = DefaultFailureThreshold
+	}
+	c.failures++
+	if c.trial || c.failures >= threshold {
+		c.openedAt = timeNow()
+		c.trial = false
+	}
+}
+
+// circuit returns the circuit of host, cb.mu must be held.
+func (cb *CircuitBreaker) circuit(host string) *circuit {
+	if cb.hosts == nil {
+		cb.hosts = make(map[string]*circuit)
+	}
+	c, ok := cb.hosts[host]
+	if !ok {
+		c = &circuit{}
+		cb.hosts[host] = c
+	}
+	return c
+}
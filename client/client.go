@@ -6,10 +6,10 @@ import (
 	"io"
 	"net/http"
 	"net/http/httputil"
-	"time"
 
 	"github.com/shogo82148/shogoa"
 	"github.com/shogo82148/shogoa/internal/randid"
+	"github.com/shogo82148/shogoa/tracing"
 )
 
 // Doer defines the Do method of the http client.
@@ -29,6 +29,16 @@ type Client struct {
 	UserAgent string
 	// Dump indicates whether to dump request response.
 	Dump bool
+	// Tracer creates a client span for each request if not nil. The trace of the request context
+	// is propagated via the traceparent and tracestate headers regardless.
+	Tracer *tracing.Tracer
+	// Retry retries the failed requests according to the policy if not nil.
+	Retry *RetryPolicy
+	// CircuitBreaker stops sending requests to the failing hosts if not nil. It is shared by
+	// the clients that should share the state of the hosts.
+	CircuitBreaker *CircuitBreaker
+
+	middleware []Middleware
 }
 
 // New creates a new API client that wraps c.
@@ -42,50 +52,56 @@ func New(c Doer) *Client {
 
 // HTTPClientDoer turns a stdlib http.Client into a Doer. Use it to enable to call New() with an http.Client.
 func HTTPClientDoer(hc *http.Client) Doer {
-	return doFunc(func(_ context.Context, req *http.Request) (*http.Response, error) {
+	return DoFunc(func(_ context.Context, req *http.Request) (*http.Response, error) {
 		return hc.Do(req)
 	})
 }
 
-// doFunc is the type definition of the Doer.Do method. It implements Doer.
-type doFunc func(context.Context, *http.Request) (*http.Response, error)
+// DoFunc is an adapter to allow the use of ordinary functions as Doer.
+type DoFunc func(context.Context, *http.Request) (*http.Response, error)
 
 // Do implements Doer.Do
-func (f doFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+func (f DoFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
 	return f(ctx, req)
 }
 
-// Do wraps the underlying http client Do method and adds logging.
-// The logger should be in the context.
+// Use adds middlewares to the client. The middlewares run in the order they are added, before the
+// built-in middlewares configured by the client fields so that the latter see the final requests.
+func (c *Client) Use(m ...Middleware) {
+	c.middleware = append(c.middleware, m...)
+}
+
+// Do makes the request with the underlying http client through the client middlewares followed by
+// the built-in middlewares: RequestID, UserAgent if the UserAgent field is set, Retry and
+// CircuitBreak if the Retry and CircuitBreaker fields are set, Trace with the Tracer field, Log,
+// and Dump if the Dump field is true. Each attempt of a retried request is traced and logged. The
+// logger should be in the context.
 func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
-	// TODO: setting the request ID should be done via client middleware. For now only set it if the
-	// caller provided one in the ctx.
-	if ctxreqid := ContextRequestID(ctx); ctxreqid != "" {
-		req.Header.Set("X-Request-Id", ctxreqid)
-	}
+	chain := make([]Middleware, 0, len(c.middleware)+7)
+	chain = append(chain, c.middleware...)
+	chain = append(chain, RequestID())
 	if c.UserAgent != "" {
-		req.Header.Set("User-Agent", c.UserAgent)
+		chain = append(chain, UserAgent(c.UserAgent))
 	}
-	startedAt := time.Now()
-	ctx, id := ContextWithRequestID(ctx)
-	shogoa.LogInfo(ctx, "started", "id", id, req.Method, req.URL.String())
-	if c.Dump {
-		c.dumpRequest(ctx, req)
+	if c.Retry != nil {
+		chain = append(chain, Retry(c.Retry))
 	}
-	resp, err := c.Doer.Do(ctx, req)
-	if err != nil {
-		shogoa.LogError(ctx, "failed", "err", err)
-		return nil, err
+	if c.CircuitBreaker != nil {
+		chain = append(chain, CircuitBreak(c.CircuitBreaker))
 	}
-	shogoa.LogInfo(ctx, "completed", "id", id, "status", resp.StatusCode, "time", time.Since(startedAt).String())
+	chain = append(chain, Trace(c.Tracer), Log())
 	if c.Dump {
-		c.dumpResponse(ctx, resp)
+		chain = append(chain, Dump())
+	}
+	d := c.Doer
+	for i := len(chain) - 1; i >= 0; i-- {
+		d = chain[i](d)
 	}
-	return resp, err
+	return d.Do(ctx, req)
 }
 
-// Dump request if needed.
-func (c *Client) dumpRequest(ctx context.Context, req *http.Request) {
+// dumpRequest dumps the request.
+func dumpRequest(ctx context.Context, req *http.Request) {
 	reqBody, err := dumpReqBody(req)
 	if err != nil {
 		shogoa.LogError(ctx, "Failed to load request body for dump", "err", err.Error())
@@ -96,8 +112,8 @@ func (c *Client) dumpRequest(ctx context.Context, req *http.Request) {
 	}
 }
 
-// dumpResponse dumps the response and the request.
-func (c *Client) dumpResponse(ctx context.Context, resp *http.Response) {
+// dumpResponse dumps the response.
+func dumpResponse(ctx context.Context, resp *http.Response) {
 	respBody, _ := dumpRespBody(resp)
 	shogoa.LogInfo(ctx, "response headers", headersToSlice(resp.Header)...)
 	if respBody != nil {
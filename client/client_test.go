@@ -2,9 +2,12 @@ package client_test
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/shogo82148/shogoa/client"
+	"github.com/shogo82148/shogoa/tracing"
 )
 
 func TestContextRequestID(t *testing.T) {
@@ -45,3 +48,46 @@ func TestSetContextRequestID(t *testing.T) {
 		t.Errorf("expected request ID %s, got %s", customID, reqID)
 	}
 }
+
+func TestClientDoTracing(t *testing.T) {
+	exporter := tracing.NewInMemoryExporter()
+	tracer := tracing.NewTracer(exporter)
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header = req.Header.Clone()
+	}))
+	defer ts.Close()
+	c := client.New(client.HTTPClientDoer(ts.Client()))
+	c.Tracer = tracer
+
+	ctx, parent := tracer.Start(context.Background(), "parent", tracing.SpanKindInternal)
+	req, err := http.NewRequestWithContext(ctx, "GET", ts.URL+"/bottles", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("unexpected number of spans: %d", len(spans))
+	}
+	span := spans[0]
+	if span.Kind != tracing.SpanKindClient || span.ParentSpanID != parent.SpanContext.SpanID {
+		t.Errorf("unexpected client span: %+v", span)
+	}
+	if span.Attributes["http.status_code"] != http.StatusOK {
+		t.Errorf("unexpected status code: %v", span.Attributes["http.status_code"])
+	}
+	sc, ok := tracing.Extract(header)
+	if !ok {
+		t.Fatalf("missing traceparent header: %v", header)
+	}
+	if sc.TraceID != parent.SpanContext.TraceID || sc.SpanID != span.SpanContext.SpanID {
+		t.Errorf("unexpected propagated span context: %+v", sc)
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/shogo82148/shogoa"
+)
+
+// DecodeError decodes the error response in resp body. It returns a *shogoa.ProblemDetails if the
+// response content type is application/problem+json and a *shogoa.ErrorResponse otherwise. The
+// status of the returned error defaults to the response status code if the body does not set it.
+func DecodeError(decoder *shogoa.HTTPDecoder, resp *http.Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == shogoa.ProblemMediaIdentifier {
+		var p shogoa.ProblemDetails
+		if err := decoder.Decode(&p, resp.Body, contentType); err != nil {
+			return fmt.Errorf("failed to decode problem details: %w", err)
+		}
+		if p.Status == 0 {
+			p.Status = resp.StatusCode
+		}
+		return &p
+	}
+	var e shogoa.ErrorResponse
+	if err := decoder.Decode(&e, resp.Body, contentType); err != nil {
+		return fmt.Errorf("failed to decode error response: %w", err)
+	}
+	if e.Status == 0 {
+		e.Status = resp.StatusCode
+	}
+	return &e
+}
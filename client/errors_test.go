@@ -0,0 +1,63 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shogo82148/shogoa"
+	"github.com/shogo82148/shogoa/client"
+)
+
+func newErrorResponse(status int, contentType, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	decoder := shogoa.NewHTTPDecoder()
+	decoder.Register(shogoa.NewJSONDecoder, "application/json", "*/*")
+
+	t.Run("error response", func(t *testing.T) {
+		resp := newErrorResponse(404, "application/vnd.shogoa.error", `{"id":"abc","code":"not_found","detail":"no bottle"}`)
+		err := client.DecodeError(decoder, resp)
+		var e *shogoa.ErrorResponse
+		if !errors.As(err, &e) {
+			t.Fatalf("unexpected error type: %T", err)
+		}
+		if e.ID != "abc" || e.Detail != "no bottle" || e.Status != 404 {
+			t.Errorf("unexpected error: %+v", e)
+		}
+		if code := shogoa.ErrorCode(err); code != "not_found" {
+			t.Errorf("unexpected code: %q", code)
+		}
+	})
+
+	t.Run("problem details", func(t *testing.T) {
+		resp := newErrorResponse(409, "application/problem+json; charset=utf-8", `{"type":"urn:shogoa:error:sold_out","status":409,"code":"sold_out","id":"abc"}`)
+		err := client.DecodeError(decoder, resp)
+		var p *shogoa.ProblemDetails
+		if !errors.As(err, &p) {
+			t.Fatalf("unexpected error type: %T", err)
+		}
+		if p.Status != 409 || p.Token() != "abc" {
+			t.Errorf("unexpected problem details: %+v", p)
+		}
+		if code := shogoa.ErrorCode(err); code != "sold_out" {
+			t.Errorf("unexpected code: %q", code)
+		}
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		resp := newErrorResponse(500, "application/json", `not json`)
+		err := client.DecodeError(decoder, resp)
+		if err == nil || shogoa.ErrorCode(err) != "" {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// SetSleep replaces the function used to wait between retries and returns a function that
+// restores it.
+func SetSleep(f func(context.Context, time.Duration) error) func() {
+	orig := sleep
+	sleep = f
+	return func() { sleep = orig }
+}
+
+// SetTimeNow replaces the clock of the circuit breakers and returns a function that restores it.
+func SetTimeNow(f func() time.Time) func() {
+	orig := timeNow
+	timeNow = f
+	return func() { timeNow = orig }
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+	"github.com/shogo82148/shogoa/tracing"
+)
+
+// Middleware wraps a Doer to add behavior to the requests made by a client, e.g. authentication,
+// tracing, metrics or header injection. Middlewares are added to clients with the Client Use
+// method.
+type Middleware func(Doer) Doer
+
+// RequestID returns a middleware that sets the X-Request-Id header of the requests to the request
+// ID of the request context if any, see SetContextRequestID.
+func RequestID() Middleware {
+	return func(d Doer) Doer {
+		return DoFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if reqID := ContextRequestID(ctx); reqID != "" {
+				req.Header.Set("X-Request-Id", reqID)
+			}
+			return d.Do(ctx, req)
+		})
+	}
+}
+
+// UserAgent returns a middleware that sets the User-Agent header of the requests.
+func UserAgent(ua string) Middleware {
+	return Header("User-Agent", ua)
+}
+
+// Header returns a middleware that sets the header with the given name of the requests.
+func Header(name, value string) Middleware {
+	return func(d Doer) Doer {
+		return DoFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			req.Header.Set(name, value)
+			return d.Do(ctx, req)
+		})
+	}
+}
+
+// Trace returns a middleware that propagates the trace of the request context via the
+// traceparent and tracestate headers. It also creates a client span for each request if tracer
+// is not nil.
+func Trace(tracer *tracing.Tracer) Middleware {
+	return func(d Doer) Doer {
+		return DoFunc(func(ctx context.Context, req *http.Request) (resp *http.Response, err error) {
+			if tracer != nil {
+				var span *tracing.Span
+				ctx, span = tracer.Start(ctx, req.Method+" "+req.URL.Host, tracing.SpanKindClient)
+				defer span.End()
+				span.SetAttribute("http.method", req.Method)
+				span.SetAttribute("http.url", req.URL.String())
+				defer func() {
+					if err != nil {
+						span.SetError(err)
+					} else {
+						span.SetAttribute("http.status_code", resp.StatusCode)
+					}
+				}()
+			}
+			tracing.Inject(tracing.ContextSpanContext(ctx), req.Header)
+			return d.Do(ctx, req)
+		})
+	}
+}
+
+// Log returns a middleware that logs the requests and the response statuses with the logger of
+// the request context.
+func Log() Middleware {
+	return func(d Doer) Doer {
+		return DoFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			startedAt := time.Now()
+			ctx, id := ContextWithRequestID(ctx)
+			shogoa.LogInfo(ctx, "started", "id", id, req.Method, req.URL.String())
+			resp, err := d.Do(ctx, req)
+			if err != nil {
+				shogoa.LogError(ctx, "failed", "err", err)
+				return nil, err
+			}
+			shogoa.LogInfo(ctx, "completed", "id", id, "status", resp.StatusCode, "time", time.Since(startedAt).String())
+			return resp, nil
+		})
+	}
+}
+
+// Dump returns a middleware that logs the headers and bodies of the requests and responses with
+// the logger of the request context.
+func Dump() Middleware {
+	return func(d Doer) Doer {
+		return DoFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			dumpRequest(ctx, req)
+			resp, err := d.Do(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			dumpResponse(ctx, resp)
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/shogo82148/shogoa/client"
+)
+
+func TestClientUse(t *testing.T) {
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header = req.Header.Clone()
+	}))
+	defer ts.Close()
+
+	var calls []string
+	trace := func(name string) client.Middleware {
+		return func(d client.Doer) client.Doer {
+			return client.DoFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return d.Do(ctx, req)
+			})
+		}
+	}
+	c := client.New(client.HTTPClientDoer(ts.Client()))
+	c.UserAgent = "test/1.0"
+	c.Use(trace("first"), client.Header("Authorization", "Bearer token"))
+	c.Use(trace("second"))
+
+	ctx := client.SetContextRequestID(context.Background(), "req-id")
+	req, err := http.NewRequestWithContext(ctx, "GET", ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if want := []string{"first", "second"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("unexpected middleware calls: want %v, got %v", want, calls)
+	}
+	want := map[string]string{
+		"Authorization": "Bearer token",
+		"User-Agent":    "test/1.0",
+		"X-Request-Id":  "req-id",
+	}
+	for k, v := range want {
+		if got := header.Get(k); got != v {
+			t.Errorf("unexpected %s header: want %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var header http.Header
+	d := client.RequestID()(client.DoFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		header = req.Header
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+
+	req := httptest.NewRequest("GET", "http://example.com", nil)
+	if _, err := d.Do(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("X-Request-Id"); got != "" {
+		t.Errorf("unexpected request ID %q", got)
+	}
+}
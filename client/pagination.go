@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PageFunc fetches the page of a paginated action that starts at cursor, the first page if cursor
+// is empty. It returns the page items and the cursor of the next page, empty on the last page.
+type PageFunc[T any] func(ctx context.Context, cursor string) (items []T, next string, err error)
+
+// Paginate returns an iterator over the items of all the pages fetched with fetch. The pages are
+// fetched as the iteration progresses, the iteration stops after the last page or after yielding
+// the first error. The iteration also stops if the next page cursor does not change to avoid
+// looping forever on misbehaving servers.
+func Paginate[T any](ctx context.Context, fetch PageFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var cursor string
+		for {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			items, next, err := fetch(ctx, cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" || next == cursor {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// NextPageCursor returns the value of the query string parameter named param of the target of
+// the Link header with the "next" relation of resp, see RFC 8288. It returns an empty string if
+// there is no such link, i.e. on the last page.
+func NextPageCursor(resp *http.Response, param string) string {
+	for _, header := range resp.Header.Values("Link") {
+		for link := range splitLinks(header) {
+			target, params, ok := strings.Cut(link, ">")
+			target, found := strings.CutPrefix(strings.TrimSpace(target), "<")
+			if !ok || !found || !isNextLink(params) {
+				continue
+			}
+			u, err := url.Parse(target)
+			if err != nil {
+				continue
+			}
+			return u.Query().Get(param)
+		}
+	}
+	return ""
+}
+
+// splitLinks returns an iterator over the comma separated links of a Link header value. Commas
+// that appear in link targets or quoted parameter values do not separate links.
+func splitLinks(header string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		var inTarget, inQuote bool
+		start := 0
+		for i, r := range header {
+			switch {
+			case r == '<' && !inQuote:
+				inTarget = true
+			case r == '>' && !inQuote:
+				inTarget = false
+			case r == '"' && !inTarget:
+				inQuote = !inQuote
+			case r == ',' && !inTarget && !inQuote:
+				if !yield(header[start:i]) {
+					return
+				}
+				start = i + 1
+			}
+		}
+		yield(header[start:])
+	}
+}
+
+// isNextLink reports whether the link parameters include the "next" relation.
+func isNextLink(params string) bool {
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+			if strings.EqualFold(rel, "next") {
+				return true
+			}
+		}
+	}
+	return false
+}
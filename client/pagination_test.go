@@ -0,0 +1,98 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/shogo82148/shogoa/client"
+)
+
+func TestPaginate(t *testing.T) {
+	pages := map[string]struct {
+		items []int
+		next  string
+	}{
+		"":  {[]int{1, 2}, "b"},
+		"b": {[]int{3}, "c"},
+		"c": {[]int{4, 5}, ""},
+	}
+	var cursors []string
+	fetch := func(ctx context.Context, cursor string) ([]int, string, error) {
+		cursors = append(cursors, cursor)
+		if cursor == "fail" {
+			return nil, "", errors.New("boom")
+		}
+		p := pages[cursor]
+		return p.items, p.next, nil
+	}
+
+	t.Run("iterates over all the pages", func(t *testing.T) {
+		cursors = nil
+		var got []int
+		for item, err := range client.Paginate(context.Background(), fetch) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, item)
+		}
+		if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected items: want %v, got %v", want, got)
+		}
+		if want := []string{"", "b", "c"}; !reflect.DeepEqual(cursors, want) {
+			t.Errorf("unexpected cursors: want %q, got %q", want, cursors)
+		}
+	})
+
+	t.Run("fetches pages lazily", func(t *testing.T) {
+		cursors = nil
+		for item := range client.Paginate(context.Background(), fetch) {
+			if item == 2 {
+				break
+			}
+		}
+		if len(cursors) != 1 {
+			t.Errorf("unexpected cursors %q", cursors)
+		}
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		pages["c"] = struct {
+			items []int
+			next  string
+		}{[]int{4}, "fail"}
+		var errs int
+		for _, err := range client.Paginate(context.Background(), fetch) {
+			if err != nil {
+				errs++
+			}
+		}
+		if errs != 1 {
+			t.Errorf("expected a single error, got %d", errs)
+		}
+	})
+}
+
+func TestNextPageCursor(t *testing.T) {
+	cases := []struct {
+		name  string
+		links []string
+		want  string
+	}{
+		{"none", nil, ""},
+		{"next", []string{`</bottles?cursor=abc&limit=10>; rel="next"`}, "abc"},
+		{"several relations", []string{`</bottles?cursor=a,b>; rel="prev", </bottles?cursor=d%2Ff>; rel="last next"`}, "d/f"},
+		{"several headers", []string{`</bottles?cursor=a>; rel=prev`, `</bottles?cursor=b>; rel=next`}, "b"},
+		{"no next", []string{`</bottles?cursor=a>; rel="prev"; title="a, b"`}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{"Link": c.links}}
+			if got := client.NextPageCursor(resp, "cursor"); got != c.want {
+				t.Errorf("want %q, got %q", c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Default retry policy parameters, see RetryPolicy.
+const (
+	DefaultMaxAttempts    = 3
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 10 * time.Second
+	DefaultBackoffFactor  = 2.0
+	DefaultJitter         = 0.2
+)
+
+// DefaultRetryStatuses lists the response status codes retried by default.
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// sleep waits for d or until ctx is done, tests override it.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RetryPolicy describes how failed requests are retried. The zero value of each field selects the
+// default value.
+//
+// Only the requests with an idempotent method (GET, HEAD, OPTIONS, TRACE, PUT and DELETE) and the
+// requests with an Idempotency-Key header are retried. Requests with a body are only retried if
+// their GetBody field is set, which is the case of the requests created with http.NewRequest and
+// a bytes.Buffer, bytes.Reader or strings.Reader body.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between two attempts.
+	MaxBackoff time.Duration
+	// BackoffFactor is the factor the delay is multiplied by after each retry.
+	BackoffFactor float64
+	// Jitter is the fraction of the delay that is randomized, between 0 and 1.
+	Jitter float64
+	// RetryStatuses lists the response status codes that are retried, DefaultRetryStatuses if
+	// nil. The delay given by the Retry-After header of the responses is honored, the responses
+	// whose Retry-After delay exceeds MaxBackoff are returned without being retried.
+	RetryStatuses []int
+	// RetryError reports whether a request that failed with the given transport error is
+	// retried. All the errors but the cancellation of the request context are retried if nil.
+	RetryError func(error) bool
+}
+
+// Retry returns a middleware that retries the failed requests according to the given policy,
+// the default policy if p is nil.
+func Retry(p *RetryPolicy) Middleware {
+	if p == nil {
+		p = &RetryPolicy{}
+	}
+	return func(d Doer) Doer {
+		return DoFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if !retryable(req) {
+				return d.Do(ctx, req)
+			}
+			maxAttempts := p.MaxAttempts
+			if maxAttempts <= 0 {
+				maxAttempts = DefaultMaxAttempts
+			}
+			for attempt := 1; ; attempt++ {
+				resp, err := d.Do(ctx, req)
+				if attempt >= maxAttempts || ctx.Err() != nil {
+					return resp, err
+				}
+				var wait time.Duration
+				if err != nil {
+					if !p.retryError(err) {
+						return resp, err
+					}
+					wait = p.backoff(attempt)
+				} else {
+					if !p.retryStatus(resp.StatusCode) {
+						return resp, err
+					}
+					wait = retryAfter(resp.Header.Get("Retry-After"))
+					if wait > p.maxBackoff() {
+						return resp, err
+					}
+					if wait < 0 {
+						wait = p.backoff(attempt)
+					}
+					// Release the connection of the failed attempt.
+					io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+					resp.Body.Close()
+				}
+				if req, err = rewind(req); err != nil {
+					return nil, err
+				}
+				if err := sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+			}
+		})
+	}
+}
+
+// retryable reports whether req may be sent more than once.
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// rewind returns a copy of req with a fresh body.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = body
+	return req, nil
+}
+
+// retryError reports whether a request that failed with err is retried.
+func (p *RetryPolicy) retryError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	if p.RetryError != nil {
+		return p.RetryError(err)
+	}
+	return true
+}
+
+// retryStatus reports whether a response with the given status code is retried.
+func (p *RetryPolicy) retryStatus(status int) bool {
+	statuses := p.RetryStatuses
+	if statuses == nil {
+		statuses = DefaultRetryStatuses
+	}
+	return slices.Contains(statuses, status)
+}
+
+// maxBackoff returns the maximum delay between two attempts.
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return DefaultMaxBackoff
+	}
+	return p.MaxBackoff
+}
+
+// backoff returns the delay before the retry following the given attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	initial, maxBackoff, factor, jitter := p.InitialBackoff, p.maxBackoff(), p.BackoffFactor, p.Jitter
+	if initial <= 0 {
+		initial = DefaultInitialBackoff
+	}
+	if factor < 1 {
+		factor = DefaultBackoffFactor
+	}
+	if jitter <= 0 || jitter > 1 {
+		jitter = DefaultJitter
+	}
+	d := math.Min(float64(initial)*math.Pow(factor, float64(attempt-1)), float64(maxBackoff))
+	d -= d * jitter * rand.Float64()
+	return time.Duration(d)
+}
+
+// retryAfter parses the value of a Retry-After header, it returns a negative duration if the
+// value is empty or invalid.
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return -1
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return -1
+}
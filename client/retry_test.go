@@ -0,0 +1,212 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shogo82148/shogoa/client"
+)
+
+// fakeDoer is a Doer that returns the given responses in order and records the request bodies.
+type fakeDoer struct {
+	responses []any // *http.Response or error
+	bodies    []string
+}
+
+func (d *fakeDoer) Do(_ context.Context, req *http.Request) (*http.Response, error) {
+	var body string
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+	}
+	d.bodies = append(d.bodies, body)
+	r := d.responses[0]
+	if len(d.responses) > 1 {
+		d.responses = d.responses[1:]
+	}
+	if err, ok := r.(error); ok {
+		return nil, err
+	}
+	resp := *r.(*http.Response)
+	resp.Body = io.NopCloser(strings.NewReader(""))
+	return &resp, nil
+}
+
+func status(code int, header ...string) *http.Response {
+	h := make(http.Header)
+	for i := 0; i+1 < len(header); i += 2 {
+		h.Set(header[i], header[i+1])
+	}
+	return &http.Response{StatusCode: code, Header: h}
+}
+
+func TestRetry(t *testing.T) {
+	var waits []time.Duration
+	defer client.SetSleep(func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	})()
+	policy := &client.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Jitter: 0.1}
+
+	do := func(doer *fakeDoer, method string, body string, header ...string) (*http.Response, error) {
+		waits = nil
+		var r io.Reader
+		if body != "" {
+			r = strings.NewReader(body)
+		}
+		req, err := http.NewRequest(method, "http://example.com/bottles", r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		return client.Retry(policy)(doer).Do(context.Background(), req)
+	}
+
+	t.Run("retries network errors and retryable statuses with backoff", func(t *testing.T) {
+		doer := &fakeDoer{responses: []any{errors.New("connection reset"), status(503), status(200)}}
+		resp, err := do(doer, "GET", "")
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("unexpected result %v, %v", resp, err)
+		}
+		if len(waits) != 2 {
+			t.Fatalf("unexpected waits %v", waits)
+		}
+		if waits[0] < 900*time.Millisecond || waits[0] > time.Second || waits[1] < 1800*time.Millisecond || waits[1] > 2*time.Second {
+			t.Errorf("unexpected backoff %v", waits)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		doer := &fakeDoer{responses: []any{status(502)}}
+		resp, err := do(doer, "GET", "")
+		if err != nil || resp.StatusCode != 502 || len(doer.bodies) != client.DefaultMaxAttempts {
+			t.Errorf("unexpected result %v, %v after %d attempts", resp, err, len(doer.bodies))
+		}
+	})
+
+	t.Run("does not retry other statuses", func(t *testing.T) {
+		doer := &fakeDoer{responses: []any{status(500), status(200)}}
+		resp, _ := do(doer, "GET", "")
+		if resp.StatusCode != 500 || len(doer.bodies) != 1 {
+			t.Errorf("unexpected status %d after %d attempts", resp.StatusCode, len(doer.bodies))
+		}
+	})
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		doer := &fakeDoer{responses: []any{status(429, "Retry-After", "2"), status(200)}}
+		if _, err := do(doer, "GET", ""); err != nil {
+			t.Fatal(err)
+		}
+		if len(waits) != 1 || waits[0] != 2*time.Second {
+			t.Errorf("unexpected waits %v", waits)
+		}
+	})
+
+	t.Run("does not wait for Retry-After longer than MaxBackoff", func(t *testing.T) {
+		for _, v := range []string{"86400", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)} {
+			doer := &fakeDoer{responses: []any{status(503, "Retry-After", v), status(200)}}
+			resp, err := do(doer, "GET", "")
+			if err != nil || resp.StatusCode != 503 || len(doer.bodies) != 1 || len(waits) != 0 {
+				t.Errorf("Retry-After %s: unexpected result %v, %v after %d attempts and waits %v", v, resp, err, len(doer.bodies), waits)
+			}
+		}
+	})
+
+	t.Run("does not retry non idempotent requests", func(t *testing.T) {
+		doer := &fakeDoer{responses: []any{status(503), status(200)}}
+		resp, _ := do(doer, "POST", `{"name":"wine"}`)
+		if resp.StatusCode != 503 || len(doer.bodies) != 1 {
+			t.Errorf("unexpected status %d after %d attempts", resp.StatusCode, len(doer.bodies))
+		}
+	})
+
+	t.Run("retries requests with an idempotency key and rewinds bodies", func(t *testing.T) {
+		doer := &fakeDoer{responses: []any{status(503), status(201)}}
+		resp, _ := do(doer, "POST", `{"name":"wine"}`, "Idempotency-Key", "key")
+		if resp.StatusCode != 201 || len(doer.bodies) != 2 {
+			t.Fatalf("unexpected status %d after %d attempts", resp.StatusCode, len(doer.bodies))
+		}
+		for i, body := range doer.bodies {
+			if body != `{"name":"wine"}` {
+				t.Errorf("attempt %d: unexpected body %q", i, body)
+			}
+		}
+	})
+
+	t.Run("does not retry canceled requests", func(t *testing.T) {
+		doer := &fakeDoer{responses: []any{context.Canceled, status(200)}}
+		if _, err := do(doer, "GET", ""); !errors.Is(err, context.Canceled) || len(doer.bodies) != 1 {
+			t.Errorf("unexpected error %v after %d attempts", err, len(doer.bodies))
+		}
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	defer client.SetTimeNow(func() time.Time { return now })()
+	cb := &client.CircuitBreaker{FailureThreshold: 2, OpenTimeout: time.Minute}
+	doer := &fakeDoer{responses: []any{errors.New("connection refused")}}
+	d := client.CircuitBreak(cb)(doer)
+	do := func(host string) error {
+		req, err := http.NewRequest("GET", "http://"+host+"/bottles", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = d.Do(context.Background(), req)
+		return err
+	}
+
+	for range 2 {
+		if err := do("a.example.com"); err == nil || errors.Is(err, client.ErrCircuitOpen) {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	if err := do("a.example.com"); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Errorf("expected the circuit to open, got %v", err)
+	}
+	if err := do("b.example.com"); errors.Is(err, client.ErrCircuitOpen) {
+		t.Error("expected the circuits to be per host")
+	}
+	if len(doer.bodies) != 3 {
+		t.Errorf("unexpected number of requests sent: %d", len(doer.bodies))
+	}
+
+	now = now.Add(time.Minute)
+	if err := do("a.example.com"); errors.Is(err, client.ErrCircuitOpen) {
+		t.Error("expected a trial request")
+	}
+	if err := do("a.example.com"); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Errorf("expected the failed trial to open the circuit, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	doer.responses = []any{status(200)}
+	for range 2 {
+		if err := do("a.example.com"); err != nil {
+			t.Errorf("expected the successful trial to close the circuit, got %v", err)
+		}
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	defer client.SetSleep(func(context.Context, time.Duration) error { return nil })()
+	doer := &fakeDoer{responses: []any{status(503), status(200)}}
+	c := client.New(doer)
+	c.Retry = &client.RetryPolicy{}
+	c.CircuitBreaker = &client.CircuitBreaker{}
+	req, err := http.NewRequest("GET", "http://example.com/bottles", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(context.Background(), req)
+	if err != nil || resp.StatusCode != 200 || len(doer.bodies) != 2 {
+		t.Errorf("unexpected result %v, %v after %d attempts", resp, err, len(doer.bodies))
+	}
+}
@@ -1,10 +1,26 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
+// DefaultExpiryDelta is how long before their expiry the OAuth2 access tokens are considered
+// expired so that they are refreshed before the requests they sign reach the server.
+const DefaultExpiryDelta = 10 * time.Second
+
+// DefaultTokenTimeout is the timeout of the requests sent to the OAuth2 token endpoints, it
+// bounds the time the requests signed with a token source wait for a new token.
+const DefaultTokenTimeout = 30 * time.Second
+
 // Signer is the common interface implemented by all signers.
 type Signer interface {
 	// Sign adds required headers, cookies etc.
@@ -43,8 +59,9 @@ type JWTSigner struct {
 // OAuth2Signer adds a authorization header to the request using the given OAuth2 token
 // source to produce the header value.
 type OAuth2Signer struct {
-	// TokenSource is an OAuth2 access token source.
-	// See package golang/oauth2 and its subpackage for implementations of token
+	// TokenSource is an OAuth2 access token source, e.g. a ClientCredentialsTokenSource,
+	// PasswordTokenSource or RefreshTokenSource wrapped with ReuseTokenSource.
+	// See also package golang/oauth2 and its subpackage for implementations of token
 	// sources.
 	TokenSource TokenSource
 }
@@ -66,6 +83,92 @@ type TokenSource interface {
 	Token() (Token, error)
 }
 
+// OAuth2Token is an access token returned by an OAuth2 token endpoint.
+type OAuth2Token struct {
+	// AccessToken is the token that authorizes the requests.
+	AccessToken string
+	// TokenType is the type of the token, defaults to "Bearer".
+	TokenType string
+	// RefreshToken is the token used to obtain a new access token if any.
+	RefreshToken string
+	// Expiry is the expiration time of the access token, zero if it does not expire.
+	Expiry time.Time
+}
+
+// TokenError is the error returned by the token sources when the token endpoint rejects a
+// token request.
+type TokenError struct {
+	// StatusCode is the status code of the token endpoint response.
+	StatusCode int
+	// Code is the OAuth2 error code, e.g. "invalid_grant".
+	Code string
+	// Description is the human readable description of the error if any.
+	Description string
+}
+
+// ClientCredentialsTokenSource implements a token source that requests access tokens with the
+// OAuth2 client credentials flow ("application" flow in the design).
+type ClientCredentialsTokenSource struct {
+	// TokenURL is the URL of the token endpoint.
+	TokenURL string
+	// ClientID is the client identifier.
+	ClientID string
+	// ClientSecret is the client secret.
+	ClientSecret string
+	// Scopes lists the requested scopes if any.
+	Scopes []string
+	// Doer sends the token requests, defaults to http.DefaultClient. The requests time out after
+	// DefaultTokenTimeout.
+	Doer Doer
+}
+
+// PasswordTokenSource implements a token source that requests access tokens with the OAuth2
+// resource owner password credentials flow ("password" flow in the design).
+type PasswordTokenSource struct {
+	// TokenURL is the URL of the token endpoint.
+	TokenURL string
+	// ClientID is the client identifier.
+	ClientID string
+	// ClientSecret is the client secret.
+	ClientSecret string
+	// Username is the resource owner username.
+	Username string
+	// Password is the resource owner password.
+	Password string
+	// Scopes lists the requested scopes if any.
+	Scopes []string
+	// Doer sends the token requests, defaults to http.DefaultClient. The requests time out after
+	// DefaultTokenTimeout.
+	Doer Doer
+}
+
+// RefreshTokenSource implements a token source that requests access tokens with an OAuth2
+// refresh token, e.g. one obtained with the "accessCode" flow of the design. The refresh token is
+// replaced by the one returned by the token endpoint if any.
+type RefreshTokenSource struct {
+	// TokenURL is the URL of the token endpoint.
+	TokenURL string
+	// ClientID is the client identifier.
+	ClientID string
+	// ClientSecret is the client secret.
+	ClientSecret string
+	// RefreshToken is the refresh token.
+	RefreshToken string
+	// Doer sends the token requests, defaults to http.DefaultClient. The requests time out after
+	// DefaultTokenTimeout.
+	Doer Doer
+
+	mu sync.Mutex
+}
+
+// reuseTokenSource is the token source returned by ReuseTokenSource.
+type reuseTokenSource struct {
+	source TokenSource
+
+	mu    sync.Mutex
+	token Token
+}
+
 // StaticTokenSource implements a token source that always returns the same token.
 type StaticTokenSource struct {
 	StaticToken *StaticToken
@@ -147,3 +250,179 @@ func (t *StaticToken) SetAuthHeader(r *http.Request) {
 
 // Valid reports whether Token can be used to properly sign requests.
 func (t *StaticToken) Valid() bool { return true }
+
+// SetAuthHeader sets the Authorization header to r.
+func (t *OAuth2Token) SetAuthHeader(r *http.Request) {
+	typ := t.TokenType
+	if typ == "" || strings.EqualFold(typ, "bearer") {
+		typ = "Bearer"
+	}
+	r.Header.Set("Authorization", typ+" "+t.AccessToken)
+}
+
+// Valid reports whether the token is set and does not expire in the next DefaultExpiryDelta.
+func (t *OAuth2Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || timeNow().Add(DefaultExpiryDelta).Before(t.Expiry)
+}
+
+// Error returns the error message.
+func (e *TokenError) Error() string {
+	msg := fmt.Sprintf("oauth2: token request failed with status %d", e.StatusCode)
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	return msg
+}
+
+// Token requests a new access token from the token endpoint.
+func (s *ClientCredentialsTokenSource) Token() (Token, error) {
+	params := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.Scopes) > 0 {
+		params.Set("scope", strings.Join(s.Scopes, " "))
+	}
+	token, err := retrieveToken(s.Doer, s.TokenURL, s.ClientID, s.ClientSecret, params)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Token requests a new access token from the token endpoint.
+func (s *PasswordTokenSource) Token() (Token, error) {
+	params := url.Values{
+		"grant_type": {"password"},
+		"username":   {s.Username},
+		"password":   {s.Password},
+	}
+	if len(s.Scopes) > 0 {
+		params.Set("scope", strings.Join(s.Scopes, " "))
+	}
+	token, err := retrieveToken(s.Doer, s.TokenURL, s.ClientID, s.ClientSecret, params)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Token requests a new access token from the token endpoint.
+func (s *RefreshTokenSource) Token() (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.RefreshToken == "" {
+		return nil, fmt.Errorf("oauth2: refresh token is not set")
+	}
+	params := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {s.RefreshToken},
+	}
+	token, err := retrieveToken(s.Doer, s.TokenURL, s.ClientID, s.ClientSecret, params)
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken != "" {
+		s.RefreshToken = token.RefreshToken
+	}
+	return token, nil
+}
+
+// ReuseTokenSource returns a token source that returns the same token as long as it is valid
+// and requests a new one from src otherwise. Wrap the sources that request the tokens from
+// token endpoints with ReuseTokenSource so that the tokens are refreshed before they expire
+// instead of being requested for each request. The returned token source is safe for concurrent
+// use, a single token is requested at a time.
+func ReuseTokenSource(src TokenSource) TokenSource {
+	if rts, ok := src.(*reuseTokenSource); ok {
+		return rts
+	}
+	return &reuseTokenSource{source: src}
+}
+
+// Token returns the cached token if it is valid or a new token from the wrapped source.
+func (s *reuseTokenSource) Token() (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && s.token.Valid() {
+		return s.token, nil
+	}
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// retrieveToken sends a token request with the given parameters to the token endpoint, see
+// https://tools.ietf.org/html/rfc6749#section-4. The client authenticates with basic auth. The
+// request times out after DefaultTokenTimeout.
+func retrieveToken(doer Doer, tokenURL, clientID, clientSecret string, params url.Values) (*OAuth2Token, error) {
+	if doer == nil {
+		doer = HTTPClientDoer(http.DefaultClient)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTokenTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+	resp, err := doer.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to read token response: %w", err)
+	}
+
+	var tr struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		RefreshToken     string `json:"refresh_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt == "application/x-www-form-urlencoded" || mt == "text/plain" {
+		vals, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: failed to decode token response: %w", err)
+		}
+		tr.AccessToken = vals.Get("access_token")
+		tr.TokenType = vals.Get("token_type")
+		tr.RefreshToken = vals.Get("refresh_token")
+		tr.Error = vals.Get("error")
+		tr.ErrorDescription = vals.Get("error_description")
+		if v := vals.Get("expires_in"); v != "" {
+			fmt.Sscan(v, &tr.ExpiresIn)
+		}
+	} else if err := json.Unmarshal(body, &tr); err != nil && resp.StatusCode < 300 {
+		return nil, fmt.Errorf("oauth2: failed to decode token response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || tr.Error != "" {
+		return nil, &TokenError{StatusCode: resp.StatusCode, Code: tr.Error, Description: tr.ErrorDescription}
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: token response does not contain an access token")
+	}
+	token := &OAuth2Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = timeNow().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
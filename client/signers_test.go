@@ -0,0 +1,167 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shogo82148/shogoa/client"
+)
+
+// tokenServer returns a token endpoint that issues tokens expiring in expiresIn seconds and
+// records the form of the last token request.
+func tokenServer(t *testing.T, expiresIn int) (*httptest.Server, *int32, func() (http.Header, map[string]string)) {
+	t.Helper()
+	var (
+		count  int32
+		mu     sync.Mutex
+		header http.Header
+		form   map[string]string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&count, 1)
+		if err := req.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		header = req.Header.Clone()
+		form = make(map[string]string)
+		for k := range req.PostForm {
+			form[k] = req.PostForm.Get(k)
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if req.PostForm.Get("refresh_token") == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant","error_description":"revoked"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d,"refresh_token":"refresh-%d"}`, n, expiresIn, n)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &count, func() (http.Header, map[string]string) {
+		mu.Lock()
+		defer mu.Unlock()
+		return header, form
+	}
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	ts, _, last := tokenServer(t, 3600)
+	src := &client.ClientCredentialsTokenSource{
+		TokenURL:     ts.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	}
+	token, err := src.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "http://example.com", nil)
+	token.SetAuthHeader(req)
+	if got := req.Header.Get("Authorization"); got != "Bearer token-1" {
+		t.Errorf("unexpected Authorization header %q", got)
+	}
+	if !token.Valid() {
+		t.Error("expected a valid token")
+	}
+
+	header, form := last()
+	if user, pass, ok := (&http.Request{Header: header}).BasicAuth(); !ok || user != "id" || pass != "secret" {
+		t.Errorf("unexpected client authentication %q %q", user, pass)
+	}
+	if form["grant_type"] != "client_credentials" || form["scope"] != "read write" {
+		t.Errorf("unexpected token request %v", form)
+	}
+}
+
+func TestClientCredentialsTokenSource_Failure(t *testing.T) {
+	var deadline time.Time
+	src := &client.ClientCredentialsTokenSource{
+		TokenURL: "http://example.com/token",
+		Doer: client.DoFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			deadline, _ = ctx.Deadline()
+			return nil, errors.New("token endpoint unavailable")
+		}),
+	}
+	token, err := src.Token()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if token != nil {
+		t.Errorf("expected a nil token, got %#v", token)
+	}
+	if deadline.IsZero() || time.Until(deadline) > client.DefaultTokenTimeout {
+		t.Errorf("expected the token request to time out after %v, got deadline %v", client.DefaultTokenTimeout, deadline)
+	}
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	ts, _, last := tokenServer(t, 3600)
+	src := &client.RefreshTokenSource{TokenURL: ts.URL, RefreshToken: "initial"}
+	if _, err := src.Token(); err != nil {
+		t.Fatal(err)
+	}
+	if _, form := last(); form["grant_type"] != "refresh_token" || form["refresh_token"] != "initial" {
+		t.Errorf("unexpected token request %v", form)
+	}
+	if src.RefreshToken != "refresh-1" {
+		t.Errorf("expected the refresh token to be rotated, got %q", src.RefreshToken)
+	}
+
+	src.RefreshToken = "revoked"
+	_, err := src.Token()
+	var terr *client.TokenError
+	if !errors.As(err, &terr) || terr.StatusCode != http.StatusBadRequest || terr.Code != "invalid_grant" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestReuseTokenSource(t *testing.T) {
+	now := time.Unix(1000, 0)
+	defer client.SetTimeNow(func() time.Time { return now })()
+	ts, count, _ := tokenServer(t, 60)
+	signer := &client.OAuth2Signer{
+		TokenSource: client.ReuseTokenSource(&client.PasswordTokenSource{
+			TokenURL: ts.URL,
+			Username: "user",
+			Password: "pass",
+		}),
+	}
+	sign := func() string {
+		req := httptest.NewRequest("GET", "http://example.com", nil)
+		if err := signer.Sign(req); err != nil {
+			t.Fatal(err)
+		}
+		return req.Header.Get("Authorization")
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sign()
+		}()
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(count); got != 1 {
+		t.Errorf("expected a single token request, got %d", got)
+	}
+
+	now = now.Add(40 * time.Second)
+	if got := sign(); got != "Bearer token-1" {
+		t.Errorf("expected the token to be reused, got %q", got)
+	}
+	now = now.Add(client.DefaultExpiryDelta + time.Second)
+	if got := sign(); got != "Bearer token-2" {
+		t.Errorf("expected the token to be refreshed before its expiry, got %q", got)
+	}
+}
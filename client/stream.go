@@ -0,0 +1,98 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"iter"
+	"mime"
+	"net/http"
+
+	"github.com/shogo82148/shogoa"
+)
+
+// maxStreamValueSize is the maximum size of a single value read from a stream.
+const maxStreamValueSize = 16 << 20
+
+// DecodeStream returns an iterator over the values streamed in the body of resp. The response
+// content type must be newline delimited JSON (application/x-ndjson) or server-sent events
+// (text/event-stream) whose data fields hold JSON values. Each value is decoded with decoder. The
+// iterator stops at the first error and closes the response body when done.
+func DecodeStream[T any](decoder *shogoa.HTTPDecoder, resp *http.Response) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer resp.Body.Close()
+
+		var zero T
+		contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if err != nil {
+			yield(zero, fmt.Errorf("invalid stream content type: %s", err))
+			return
+		}
+		var next func(*bufio.Scanner) ([]byte, bool)
+		switch contentType {
+		case shogoa.NDJSONContentType:
+			next = nextLine
+		case shogoa.EventStreamContentType:
+			next = nextEvent
+		default:
+			yield(zero, fmt.Errorf("unsupported stream content type %#v", contentType))
+			return
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(nil, maxStreamValueSize)
+		for {
+			data, ok := next(scanner)
+			if !ok {
+				break
+			}
+			var v T
+			if err := decoder.Decode(&v, bytes.NewReader(data), "application/json"); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// nextLine returns the next non-empty line of a newline delimited JSON stream.
+func nextLine(scanner *bufio.Scanner) ([]byte, bool) {
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			return line, true
+		}
+	}
+	return nil, false
+}
+
+// nextEvent returns the data of the next server-sent event that has any.
+func nextEvent(scanner *bufio.Scanner) ([]byte, bool) {
+	var data []byte
+	var found bool
+	for scanner.Scan() {
+		line := bytes.TrimSuffix(scanner.Bytes(), []byte("\r"))
+		if len(line) == 0 {
+			if found {
+				return data, true
+			}
+			continue
+		}
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		if string(field) != "data" {
+			// comments and the event, id and retry fields carry no value
+			continue
+		}
+		if found {
+			data = append(data, '\n')
+		}
+		data = append(data, bytes.TrimPrefix(value, []byte(" "))...)
+		found = true
+	}
+	return data, found
+}
@@ -0,0 +1,75 @@
+package client_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shogo82148/shogoa"
+	"github.com/shogo82148/shogoa/client"
+)
+
+type streamItem struct {
+	Name string `json:"name"`
+}
+
+func newStreamResponse(contentType, body string) *http.Response {
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func collectStream(t *testing.T, resp *http.Response) ([]string, error) {
+	t.Helper()
+	decoder := shogoa.NewHTTPDecoder()
+	decoder.Register(shogoa.NewJSONDecoder, "application/json")
+	var names []string
+	for item, err := range client.DecodeStream[*streamItem](decoder, resp) {
+		if err != nil {
+			return names, err
+		}
+		names = append(names, item.Name)
+	}
+	return names, nil
+}
+
+func TestDecodeStream(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"ndjson", "application/x-ndjson", "{\"name\":\"foo\"}\n\n{\"name\":\"bar\"}"},
+		{"server-sent events", "text/event-stream; charset=utf-8", ": comment\nevent: item\ndata: {\"name\":\"foo\"}\n\nid: 2\r\ndata: {\"name\":\r\ndata: \"bar\"}\r\n\r\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			names, err := collectStream(t, newStreamResponse(c.contentType, c.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(names, ",") != "foo,bar" {
+				t.Errorf("unexpected values: %v", names)
+			}
+		})
+	}
+
+	t.Run("invalid value", func(t *testing.T) {
+		names, err := collectStream(t, newStreamResponse("application/x-ndjson", "{\"name\":\"foo\"}\n{\n"))
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if len(names) != 1 {
+			t.Errorf("unexpected values: %v", names)
+		}
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		if _, err := collectStream(t, newStreamResponse("application/json", "{}")); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
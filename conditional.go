@@ -0,0 +1,158 @@
+package shogoa
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag computes a strong entity tag from the given representation bytes.
+func ETag(b []byte) string {
+	sum := sha256.Sum256(b)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// SetETag sets the ETag header of the response to the given entity tag, SendCacheable uses it
+// instead of computing the entity tag from the encoded body. The entity tag must be quoted, e.g.
+// `"v42"` or `W/"v42"`.
+func SetETag(ctx context.Context, etag string) {
+	if r := ContextResponse(ctx); r != nil {
+		r.Header().Set("ETag", etag)
+	}
+}
+
+// SetLastModified sets the Last-Modified header of the response, SendCacheable uses it to handle
+// the If-Modified-Since request header.
+func SetLastModified(ctx context.Context, t time.Time) {
+	if r := ContextResponse(ctx); r != nil {
+		r.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// SendCacheable is similar to Send but handles conditional GET and HEAD requests: it sets the
+// ETag header to the entity tag computed from the encoded body unless one was set already, and
+// writes a 304 Not Modified response without body instead of the response if the If-None-Match
+// or If-Modified-Since request headers match the ETag or Last-Modified response headers. The
+// generated response methods of the actions declared cacheable in the design use SendCacheable
+// for their 200 responses.
+func (service *Service) SendCacheable(ctx context.Context, code int, body any) error {
+	r := ContextResponse(ctx)
+	if r == nil {
+		return fmt.Errorf("no response data in context")
+	}
+	req := ContextRequest(ctx)
+	if req == nil {
+		return service.Send(ctx, code, body)
+	}
+	p, contentType, err := service.Encoder.negotiate(req.Header.Get("Accept"))
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := p.Encode(body, &buf); err != nil {
+		return err
+	}
+
+	header := r.Header()
+	etag := header.Get("ETag")
+	if etag == "" {
+		etag = ETag(buf.Bytes())
+		header.Set("ETag", etag)
+	}
+	if (req.Method == http.MethodGet || req.Method == http.MethodHead) && notModified(req.Request, etag, header.Get("Last-Modified")) {
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		r.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	if contentType != "" && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", contentType)
+	}
+	r.WriteHeader(code)
+	_, err = r.Write(buf.Bytes())
+	return err
+}
+
+// CheckPreconditions evaluates the If-Match, If-Unmodified-Since and If-None-Match request headers
+// against the current entity tag and modification time of the target resource, as described in
+// RFC 9110 section 13.2.2. etag is empty if the resource does not exist and lastModified is the
+// zero time if it is unknown. It returns an error of class ErrPreconditionFailed if a precondition
+// fails. Controllers of actions that modify resources, e.g. PUT, PATCH or DELETE actions, call it
+// before applying the changes:
+//
+//	if err := shogoa.CheckPreconditions(ctx, bottle.ETag(), bottle.UpdatedAt); err != nil {
+//		return err
+//	}
+//
+// The If-None-Match header of GET and HEAD requests is handled by SendCacheable instead.
+func CheckPreconditions(ctx context.Context, etag string, lastModified time.Time) error {
+	req := ContextRequest(ctx)
+	if req == nil {
+		return nil
+	}
+	if im := req.Header.Get("If-Match"); im != "" {
+		if etag == "" || !matchETag(im, etag, false) {
+			return ErrPreconditionFailed("If-Match precondition failed", "etag", etag)
+		}
+	} else if ius := req.Header.Get("If-Unmodified-Since"); ius != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ius); err == nil && lastModified.Truncate(time.Second).After(t) {
+			return ErrPreconditionFailed("If-Unmodified-Since precondition failed", "last-modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+	}
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return nil
+	}
+	if inm := req.Header.Get("If-None-Match"); inm != "" && etag != "" && matchETag(inm, etag, true) {
+		return ErrPreconditionFailed("If-None-Match precondition failed", "etag", etag)
+	}
+	return nil
+}
+
+// notModified returns true if the If-None-Match or If-Modified-Since headers of req match the
+// given ETag and Last-Modified response header values.
+func notModified(req *http.Request, etag, lastModified string) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		return matchETag(inm, etag, true)
+	}
+	ims := req.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !lm.After(t)
+}
+
+// matchETag returns true if the given list of entity tags, the value of an If-Match or
+// If-None-Match header, matches etag. Weak entity tags only match with the weak comparison.
+func matchETag(list, etag string, weak bool) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	etag, etagWeak := strings.CutPrefix(etag, "W/")
+	if etagWeak && !weak {
+		return false
+	}
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		candidate, candidateWeak := strings.CutPrefix(candidate, "W/")
+		if candidateWeak && !weak {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package shogoa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendCacheable(t *testing.T) {
+	service := New("test")
+	service.Encoder.Register(NewJSONEncoder, "*/*")
+	body := map[string]string{"name": "wine"}
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	send := func(method string, header http.Header, setup func(ctx context.Context)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "http://example.com/bottles/1", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rw := httptest.NewRecorder()
+		ctx := NewContext(rw, req, nil)
+		if setup != nil {
+			setup(ctx)
+		}
+		if err := service.SendCacheable(ctx, http.StatusOK, body); err != nil {
+			t.Fatal(err)
+		}
+		return rw
+	}
+
+	rw := send(http.MethodGet, nil, nil)
+	etag := rw.Header().Get("ETag")
+	if rw.Code != http.StatusOK || rw.Body.Len() == 0 {
+		t.Fatalf("unexpected response %d %q", rw.Code, rw.Body.String())
+	}
+	if etag != ETag(rw.Body.Bytes()) {
+		t.Errorf("unexpected ETag %q", etag)
+	}
+
+	t.Run("should send 304 when If-None-Match matches", func(t *testing.T) {
+		for _, inm := range []string{etag, `"other", W/` + etag, "*"} {
+			rw := send(http.MethodGet, http.Header{"If-None-Match": {inm}}, nil)
+			if rw.Code != http.StatusNotModified || rw.Body.Len() != 0 {
+				t.Errorf("If-None-Match %s: unexpected response %d %q", inm, rw.Code, rw.Body.String())
+			}
+			if rw.Header().Get("ETag") != etag {
+				t.Errorf("If-None-Match %s: unexpected ETag %q", inm, rw.Header().Get("ETag"))
+			}
+		}
+	})
+
+	t.Run("should send the response when If-None-Match does not match", func(t *testing.T) {
+		rw := send(http.MethodGet, http.Header{"If-None-Match": {`"other"`}}, nil)
+		if rw.Code != http.StatusOK {
+			t.Errorf("unexpected status %d", rw.Code)
+		}
+	})
+
+	t.Run("should use the ETag set by the controller", func(t *testing.T) {
+		setup := func(ctx context.Context) { SetETag(ctx, `W/"v1"`) }
+		rw := send(http.MethodGet, http.Header{"If-None-Match": {`"v1"`}}, setup)
+		if rw.Code != http.StatusNotModified || rw.Header().Get("ETag") != `W/"v1"` {
+			t.Errorf("unexpected response %d %v", rw.Code, rw.Header())
+		}
+	})
+
+	t.Run("should handle If-Modified-Since", func(t *testing.T) {
+		setup := func(ctx context.Context) { SetLastModified(ctx, lastModified) }
+		cases := map[time.Time]int{
+			lastModified:                 http.StatusNotModified,
+			lastModified.Add(time.Hour):  http.StatusNotModified,
+			lastModified.Add(-time.Hour): http.StatusOK,
+		}
+		for since, code := range cases {
+			rw := send(http.MethodGet, http.Header{"If-Modified-Since": {since.Format(http.TimeFormat)}}, setup)
+			if rw.Code != code {
+				t.Errorf("If-Modified-Since %s: got status %d, want %d", since, rw.Code, code)
+			}
+		}
+	})
+
+	t.Run("should ignore conditional headers of unsafe requests", func(t *testing.T) {
+		rw := send(http.MethodPut, http.Header{"If-None-Match": {etag}}, nil)
+		if rw.Code != http.StatusOK {
+			t.Errorf("unexpected status %d", rw.Code)
+		}
+	})
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		name   string
+		method string
+		header http.Header
+		etag   string
+		ok     bool
+	}{
+		{"no precondition", http.MethodPut, nil, `"v1"`, true},
+		{"matching If-Match", http.MethodPut, http.Header{"If-Match": {`"v0", "v1"`}}, `"v1"`, true},
+		{"mismatching If-Match", http.MethodPut, http.Header{"If-Match": {`"v0"`}}, `"v1"`, false},
+		{"weak If-Match", http.MethodPatch, http.Header{"If-Match": {`W/"v1"`}}, `"v1"`, false},
+		{"If-Match any", http.MethodDelete, http.Header{"If-Match": {"*"}}, `"v1"`, true},
+		{"If-Match any without resource", http.MethodPut, http.Header{"If-Match": {"*"}}, "", false},
+		{"If-None-Match any", http.MethodPut, http.Header{"If-None-Match": {"*"}}, `"v1"`, false},
+		{"If-None-Match any without resource", http.MethodPut, http.Header{"If-None-Match": {"*"}}, "", true},
+		{"If-None-Match on GET", http.MethodGet, http.Header{"If-None-Match": {`"v1"`}}, `"v1"`, true},
+		{"If-Unmodified-Since", http.MethodPut, http.Header{"If-Unmodified-Since": {lastModified.Format(http.TimeFormat)}}, `"v1"`, true},
+		{"If-Unmodified-Since before", http.MethodPut, http.Header{"If-Unmodified-Since": {lastModified.Add(-time.Hour).Format(http.TimeFormat)}}, `"v1"`, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, "http://example.com/bottles/1", nil)
+			for k, v := range c.header {
+				req.Header[k] = v
+			}
+			ctx := NewContext(httptest.NewRecorder(), req, nil)
+			err := CheckPreconditions(ctx, c.etag, lastModified)
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && ErrorCode(err) != "precondition_failed" {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ var (
 	logKey            = &contextKey{"logger"}
 	errKey            = &contextKey{"error"}
 	securityScopesKey = &contextKey{"security-scope"}
+	rateLimitKey      = &contextKey{"rate-limit"}
 )
 
 // contextKey is a value for use with context.WithValue. It's used as
@@ -142,6 +143,12 @@ func (r *ResponseData) SwitchWriter(rw http.ResponseWriter) http.ResponseWriter
 	return rwo
 }
 
+// Unwrap returns the underlying response writer, it makes it possible to use the
+// response data with http.ResponseController.
+func (r *ResponseData) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // Written returns true if the response was written, false otherwise.
 func (r *ResponseData) Written() bool {
 	return r.Status != 0
@@ -7,6 +7,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/shogo82148/shogoa"
 	"github.com/shogo82148/shogoa/dslengine"
 )
 
@@ -64,6 +65,14 @@ const (
 	HTTPVersionNotSupported = "HTTPVersionNotSupported"
 )
 
+// List of the content types used to stream response bodies.
+const (
+	// StreamNDJSON streams the response values as newline delimited JSON.
+	StreamNDJSON = shogoa.NDJSONContentType
+	// StreamSSE streams the response values as server-sent events.
+	StreamSSE = shogoa.EventStreamContentType
+)
+
 var (
 	// Design being built by DSL.
 	Design *APIDefinition
@@ -186,6 +195,84 @@ var (
 		AttributeDefinition: &AttributeDefinition{Type: errorMediaType},
 		Name:                "default",
 	}
+
+	// ProblemMediaIdentifier is the media type identifier used for RFC 9457 problem details
+	// error responses.
+	ProblemMediaIdentifier = "application/problem+json"
+
+	// ProblemMedia is the built-in media type for RFC 9457 problem details error responses.
+	ProblemMedia = &MediaTypeDefinition{
+		UserTypeDefinition: &UserTypeDefinition{
+			AttributeDefinition: &AttributeDefinition{
+				Type:        problemMediaType,
+				Description: "Problem details error response media type (RFC 9457)",
+				Example: map[string]any{
+					"type":   "urn:shogoa:error:invalid_request",
+					"title":  "Bad Request",
+					"status": 400,
+					"detail": "Value of ID must be an integer",
+					"id":     "3F1FKVRR",
+					"code":   "invalid_request",
+				},
+			},
+			TypeName: "problem",
+		},
+		Identifier: ProblemMediaIdentifier,
+		Views:      map[string]*ViewDefinition{"default": problemMediaView},
+	}
+
+	problemMediaType = Object{
+		"type": &AttributeDefinition{
+			Type:        String,
+			Description: "a URI reference that identifies the problem type.",
+			Example:     "urn:shogoa:error:invalid_request",
+		},
+		"title": &AttributeDefinition{
+			Type:        String,
+			Description: "a short, human-readable summary of the problem type.",
+			Example:     "Bad Request",
+		},
+		"status": &AttributeDefinition{
+			Type:        Integer,
+			Description: "the HTTP status code applicable to this problem, expressed as a int value.",
+			Example:     400,
+		},
+		"detail": &AttributeDefinition{
+			Type:        String,
+			Description: "a human-readable explanation specific to this occurrence of the problem.",
+			Example:     "Value of ID must be an integer",
+		},
+		"instance": &AttributeDefinition{
+			Type:        String,
+			Description: "a URI reference that identifies the specific occurrence of the problem.",
+		},
+		"id": &AttributeDefinition{
+			Type:        String,
+			Description: "a unique identifier for this particular occurrence of the problem.",
+			Example:     "3F1FKVRR",
+		},
+		"code": &AttributeDefinition{
+			Type:        String,
+			Description: "an application-specific error code, expressed as a string value.",
+			Example:     "invalid_request",
+		},
+		"errors": &AttributeDefinition{
+			Type: &Array{ElemType: &AttributeDefinition{Type: Object{
+				"type":   &AttributeDefinition{Type: String},
+				"title":  &AttributeDefinition{Type: String},
+				"status": &AttributeDefinition{Type: Integer},
+				"detail": &AttributeDefinition{Type: String},
+				"id":     &AttributeDefinition{Type: String},
+				"code":   &AttributeDefinition{Type: String},
+			}}},
+			Description: "the individual problems when the request produced more than one error.",
+		},
+	}
+
+	problemMediaView = &ViewDefinition{
+		AttributeDefinition: &AttributeDefinition{Type: problemMediaType},
+		Name:                "default",
+	}
 )
 
 func init() {
@@ -201,6 +288,7 @@ func init() {
 		{MIMETypes: GobContentTypes, PackagePath: shogoa, Function: "NewGobDecoder"},
 	}
 	errorMediaView.Parent = ErrorMedia
+	problemMediaView.Parent = ProblemMedia
 }
 
 // CanonicalIdentifier returns the media type identifier sans suffix
@@ -457,6 +457,126 @@ func MultipartForm() {
 	}
 }
 
+// Cacheable can be used in: Action
+//
+// Cacheable indicates that the action supports conditional requests. The generated response
+// method of the action 200 response sets the ETag header to a strong entity tag computed from the
+// encoded response body unless the controller sets it with shogoa.SetETag, and sends a 304 Not
+// Modified response when the If-None-Match request header or the If-Modified-Since request header
+// and the Last-Modified header set with shogoa.SetLastModified match. Example:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		Cacheable()
+//		Response(OK, BottleMedia)
+//	})
+//
+// Controllers of actions that modify resources check the If-Match request header with
+// shogoa.CheckPreconditions.
+func Cacheable() {
+	if a, ok := actionDefinition(); ok {
+		a.Cacheable = true
+	}
+}
+
+// CacheControl can be used in: Resource, Action
+//
+// CacheControl sets the Cache-Control header of the successful responses of the action, or of all
+// the GET and HEAD actions of the resource. The generated response methods set the header unless the controller
+// sets it, the cache middleware uses it to decide which responses to cache and for how long, and
+// the header is documented in the generated Swagger specification. Example:
+//
+//	Resource("bottle", func() {
+//		CacheControl("public, max-age=60")
+//		Action("show", func() {
+//			Routing(GET("/:id"))
+//			CacheControl("private, max-age=10")
+//			Response(OK, BottleMedia)
+//		})
+//	})
+func CacheControl(directives string) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		def.CacheControl = directives
+	case *design.ActionDefinition:
+		def.CacheControl = directives
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// Paginated can be used in: Action
+//
+// Paginated declares that the action returns its results in pages. cursor is the name of the
+// string query parameter that holds the cursor of the requested page, the first page is requested
+// without cursor. pageSize is the name of the integer query parameter that holds the maximum
+// number of items per page. Both parameters must be declared with Params.
+//
+// By default the OK response media type is a collection of the page items and the cursor of the
+// next page is given by the Link header with the "next" relation. The optional DSL declares the
+// OK response attributes that hold the page items and the next page cursor instead with PageItems
+// and NextCursor. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Params(func() {
+//			Param("cursor", String)
+//			Param("limit", Integer)
+//		})
+//		Paginated("cursor", "limit")
+//		Response(OK, CollectionOf(BottleMedia))
+//	})
+//
+// The generated client has a method that returns an iterator over the items of all the pages,
+// e.g. ListBottleAll. When the next page cursor is given by the Link header the generated action
+// context also has a SetNextPage method that adds the header to the response.
+func Paginated(cursor, pageSize string, dsl ...func()) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	p := &design.PaginationDefinition{
+		CursorParam:   cursor,
+		PageSizeParam: pageSize,
+		Parent:        a,
+	}
+	if len(dsl) > 0 {
+		if !dslengine.Execute(dsl[0], p) {
+			return
+		}
+	}
+	a.Pagination = p
+}
+
+// NextCursor can be used in: Paginated
+//
+// NextCursor sets the name of the string attribute of the OK response media type that holds the
+// cursor of the next page. The attribute is empty or absent on the last page. Example:
+//
+//	Paginated("cursor", "limit", func() {
+//		NextCursor("next")
+//		PageItems("bottles")
+//	})
+func NextCursor(name string) {
+	if p, ok := dslengine.CurrentDefinition().(*design.PaginationDefinition); ok {
+		p.NextCursor = name
+		return
+	}
+	dslengine.IncompatibleDSL()
+}
+
+// PageItems can be used in: Paginated
+//
+// PageItems sets the name of the array attribute of the OK response media type that holds the
+// page items, see NextCursor.
+func PageItems(name string) {
+	if p, ok := dslengine.CurrentDefinition().(*design.PaginationDefinition); ok {
+		p.Items = name
+		return
+	}
+	dslengine.IncompatibleDSL()
+}
+
 // newAttribute creates a new attribute definition using the media type with the given identifier
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
@@ -61,6 +61,30 @@ func TestAction(t *testing.T) {
 		}
 	})
 
+	t.Run("declared cacheable", func(t *testing.T) {
+		dslengine.Reset()
+		apidsl.Resource("res", func() {
+			apidsl.Action("foo", func() {
+				apidsl.Routing(apidsl.GET("/:id"))
+				apidsl.Cacheable()
+			})
+			apidsl.Action("bar", func() {
+				apidsl.Routing(apidsl.GET("/"))
+			})
+		})
+		if err := dslengine.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		res := design.Design.Resources["res"]
+		if !res.Actions["foo"].Cacheable {
+			t.Error("expected action foo to be cacheable")
+		}
+		if res.Actions["bar"].Cacheable {
+			t.Error("expected action bar not to be cacheable")
+		}
+	})
+
 	t.Run("with a metadata", func(t *testing.T) {
 		dslengine.Reset()
 		apidsl.Resource("res", func() {
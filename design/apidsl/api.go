@@ -113,6 +113,8 @@ func Description(lines ...string) {
 		def.Description = d
 	case *design.ResponseDefinition:
 		def.Description = d
+	case *design.ErrorDefinition:
+		def.Description = d
 	case *design.DocsDefinition:
 		def.Description = d
 	case *design.SecuritySchemeDefinition:
@@ -0,0 +1,62 @@
+package apidsl
+
+import (
+	"github.com/shogo82148/shogoa/design"
+	"github.com/shogo82148/shogoa/dslengine"
+)
+
+// Error declares a named error that an action may return. The first argument is the error code,
+// e.g. "not_found", and the second argument the name of the response used to render the error,
+// typically one of the standard response names such as NotFound. The response status is the
+// status of the error. An action that does not define the response explicitly gets one that
+// renders the error with the ErrorMedia media type.
+//
+// Error accepts an optional media type to render the error with as third argument: ErrorMedia (the
+// default), ProblemMedia or a custom media type defined in the design. It also accepts an optional
+// anonymous function as last argument that may call Description and Metadata:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		Error("not_found", NotFound, func() {
+//			Description("The bottle does not exist")
+//		})
+//		Error("bottle_sold_out", Conflict, ProblemMedia)
+//		Error("out_of_stock", UnprocessableEntity, StockErrorMedia)
+//	})
+//
+// shogoagen generates an error class for each error code in the app package, client helpers that
+// decode error responses into errors typed after the error code and lists the codes in the
+// Swagger, OpenAPI and JSON schema link descriptions. Errors rendered with a custom media type are
+// sent with the generated response methods of the action context, the client decodes them from
+// the responses with the error status and media type. Errors with the same code must use the same status
+// across all actions.
+func Error(name, response string, args ...any) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	e := &design.ErrorDefinition{
+		Name:      name,
+		Response:  response,
+		MediaType: design.ErrorMediaIdentifier,
+		Parent:    a,
+	}
+	var dsl func()
+	for _, arg := range args {
+		switch actual := arg.(type) {
+		case *design.MediaTypeDefinition:
+			e.MediaType = actual.Identifier
+		case string:
+			e.MediaType = actual
+		case func():
+			dsl = actual
+		default:
+			dslengine.ReportError("invalid Error argument %#v, must be a media type or a function", arg)
+			return
+		}
+	}
+	if dsl != nil && !dslengine.Execute(dsl, e) {
+		return
+	}
+	a.Errors = append(a.Errors, e)
+}
@@ -0,0 +1,185 @@
+package apidsl_test
+
+import (
+	"testing"
+
+	"github.com/shogo82148/shogoa/design"
+	"github.com/shogo82148/shogoa/design/apidsl"
+	"github.com/shogo82148/shogoa/dslengine"
+)
+
+func TestError(t *testing.T) {
+	t.Run("with a standard response", func(t *testing.T) {
+		dslengine.Reset()
+		apidsl.Resource("res", func() {
+			apidsl.Action("action", func() {
+				apidsl.Routing(apidsl.GET("/"))
+				apidsl.Error("not_found", design.NotFound, func() {
+					apidsl.Description("desc")
+					apidsl.Metadata("key", "value")
+				})
+			})
+		})
+		if err := dslengine.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		action := design.Design.Resources["res"].Actions["action"]
+		if len(action.Errors) != 1 {
+			t.Fatalf("unexpected errors: %v", action.Errors)
+		}
+		e := action.Errors[0]
+		if e.Name != "not_found" || e.Response != design.NotFound || e.Status != 404 {
+			t.Errorf("unexpected error: %+v", e)
+		}
+		if e.Description != "desc" || e.Metadata["key"][0] != "value" {
+			t.Errorf("unexpected error description or metadata: %+v", e)
+		}
+		if e.MediaType != design.ErrorMediaIdentifier || e.Parent != action {
+			t.Errorf("unexpected error media type or parent: %+v", e)
+		}
+		resp := action.Responses[design.NotFound]
+		if resp == nil {
+			t.Fatal("missing implicit error response")
+		}
+		if resp.Status != 404 || resp.MediaType != design.ErrorMediaIdentifier {
+			t.Errorf("unexpected implicit error response: %+v", resp)
+		}
+		if design.Design.MediaTypeWithIdentifier(design.ErrorMediaIdentifier) == nil {
+			t.Error("ErrorMedia is not registered")
+		}
+	})
+
+	t.Run("with problem details", func(t *testing.T) {
+		dslengine.Reset()
+		apidsl.Resource("res", func() {
+			apidsl.Action("action", func() {
+				apidsl.Routing(apidsl.GET("/"))
+				apidsl.Error("sold_out", design.Conflict, design.ProblemMedia)
+			})
+		})
+		if err := dslengine.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		action := design.Design.Resources["res"].Actions["action"]
+		if resp := action.Responses[design.Conflict]; resp == nil || resp.MediaType != design.ProblemMediaIdentifier {
+			t.Errorf("unexpected implicit error response: %+v", resp)
+		}
+	})
+
+	t.Run("with a custom media type", func(t *testing.T) {
+		dslengine.Reset()
+		stock := apidsl.MediaType("application/vnd.stock-error+json", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("available", design.Integer)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("available")
+			})
+		})
+		apidsl.Resource("res", func() {
+			apidsl.Action("action", func() {
+				apidsl.Routing(apidsl.GET("/"))
+				apidsl.Error("out_of_stock", design.UnprocessableEntity, stock)
+			})
+		})
+		if err := dslengine.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		action := design.Design.Resources["res"].Actions["action"]
+		if resp := action.Responses[design.UnprocessableEntity]; resp == nil || resp.MediaType != stock.Identifier {
+			t.Errorf("unexpected implicit error response: %+v", resp)
+		}
+	})
+
+	t.Run("with an explicit response", func(t *testing.T) {
+		dslengine.Reset()
+		apidsl.Resource("res", func() {
+			apidsl.Action("action", func() {
+				apidsl.Routing(apidsl.GET("/"))
+				apidsl.Error("not_found", design.NotFound)
+				apidsl.Error("gone", design.NotFound)
+				apidsl.Response(design.NotFound, func() {
+					apidsl.Media("text/plain")
+				})
+			})
+		})
+		if err := dslengine.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		action := design.Design.Resources["res"].Actions["action"]
+		if resp := action.Responses[design.NotFound]; resp.MediaType != "text/plain" {
+			t.Errorf("explicit response was overridden: %+v", resp)
+		}
+		if action.Errors[1].Status != 404 {
+			t.Errorf("unexpected status: %d", action.Errors[1].Status)
+		}
+	})
+
+	invalid := map[string]func(){
+		"with a success response": func() {
+			apidsl.Error("ok", design.OK)
+		},
+		"with an unknown response": func() {
+			apidsl.Error("unknown", "Unknown")
+		},
+		"with an unknown media type": func() {
+			apidsl.Error("not_found", design.NotFound, "application/json")
+		},
+		"defined twice": func() {
+			apidsl.Error("not_found", design.NotFound)
+			apidsl.Error("not_found", design.NotFound)
+		},
+	}
+	for name, dsl := range invalid {
+		t.Run(name, func(t *testing.T) {
+			dslengine.Reset()
+			apidsl.Resource("res", func() {
+				apidsl.Action("action", func() {
+					apidsl.Routing(apidsl.GET("/"))
+					dsl()
+				})
+			})
+			if err := dslengine.Run(); err == nil {
+				t.Error("expected a validation error")
+			}
+		})
+	}
+
+	t.Run("with inconsistent statuses", func(t *testing.T) {
+		dslengine.Reset()
+		apidsl.Resource("res", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/"))
+				apidsl.Error("invalid", design.BadRequest)
+			})
+			apidsl.Action("update", func() {
+				apidsl.Routing(apidsl.PUT("/"))
+				apidsl.Error("invalid", design.UnprocessableEntity)
+			})
+		})
+		if err := dslengine.Run(); err == nil {
+			t.Error("expected a validation error")
+		}
+	})
+
+	t.Run("with inconsistent media types", func(t *testing.T) {
+		dslengine.Reset()
+		apidsl.Resource("res", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/"))
+				apidsl.Error("invalid", design.BadRequest)
+			})
+			apidsl.Action("update", func() {
+				apidsl.Routing(apidsl.PUT("/"))
+				apidsl.Error("invalid", design.BadRequest, design.ProblemMedia)
+			})
+		})
+		if err := dslengine.Run(); err == nil {
+			t.Error("expected a validation error")
+		}
+	})
+}
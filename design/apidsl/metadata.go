@@ -99,6 +99,8 @@ func Metadata(name string, value ...string) {
 		def.Metadata = appendMetadata(def.Metadata, name, value...)
 	case *design.ResponseDefinition:
 		def.Metadata = appendMetadata(def.Metadata, name, value...)
+	case *design.ErrorDefinition:
+		def.Metadata = appendMetadata(def.Metadata, name, value...)
 	case *design.APIDefinition:
 		def.Metadata = appendMetadata(def.Metadata, name, value...)
 	case *design.RouteDefinition:
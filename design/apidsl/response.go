@@ -118,6 +118,30 @@ func Status(status int) {
 	}
 }
 
+// Stream sets the response body to stream a sequence of values encoded as newline delimited JSON
+// (application/x-ndjson) instead of a single value. Each value is rendered using the response media
+// type or type. Stream must appear in a Response DSL:
+//
+//	Response(OK, BottleMedia, func() {
+//		Stream()
+//	})
+//
+// The generated context response helpers accept an iterator of values and flush each value as it is
+// written, the generated client <Action><Resource>Stream method decodes the stream into an iterator.
+func Stream() {
+	if r, ok := responseDefinition(); ok {
+		r.Stream = design.StreamNDJSON
+	}
+}
+
+// ServerSentEvents is similar to Stream but streams the values as server-sent events
+// (text/event-stream), each value being sent as the JSON data of an event.
+func ServerSentEvents() {
+	if r, ok := responseDefinition(); ok {
+		r.Stream = design.StreamSSE
+	}
+}
+
 func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.ResponseDefinition {
 	var params []string
 	var dsl func()
@@ -209,4 +209,64 @@ func TestResponse(t *testing.T) {
 			t.Error("Standard = false; want true")
 		}
 	})
+
+	t.Run("with a stream", func(t *testing.T) {
+		dslengine.Reset()
+		apidsl.Resource("res", func() {
+			apidsl.Action("action", func() {
+				apidsl.Response("foo", design.String, func() {
+					apidsl.Status(200)
+					apidsl.Stream()
+				})
+			})
+		})
+		_ = dslengine.Run()
+
+		res := design.Design.Resources["res"].Actions["action"].Responses["foo"]
+		if err := res.Validate(); err != nil {
+			t.Errorf("Validate() = %v; want nil", err)
+		}
+		if res.Stream != design.StreamNDJSON {
+			t.Errorf("Stream = %q; want %q", res.Stream, design.StreamNDJSON)
+		}
+	})
+
+	t.Run("with server-sent events", func(t *testing.T) {
+		dslengine.Reset()
+		apidsl.Resource("res", func() {
+			apidsl.Action("action", func() {
+				apidsl.Response("foo", design.String, func() {
+					apidsl.Status(200)
+					apidsl.ServerSentEvents()
+				})
+			})
+		})
+		_ = dslengine.Run()
+
+		res := design.Design.Resources["res"].Actions["action"].Responses["foo"]
+		if err := res.Validate(); err != nil {
+			t.Errorf("Validate() = %v; want nil", err)
+		}
+		if res.Stream != design.StreamSSE {
+			t.Errorf("Stream = %q; want %q", res.Stream, design.StreamSSE)
+		}
+	})
+
+	t.Run("with a stream and no type", func(t *testing.T) {
+		dslengine.Reset()
+		apidsl.Resource("res", func() {
+			apidsl.Action("action", func() {
+				apidsl.Response("foo", func() {
+					apidsl.Status(200)
+					apidsl.Stream()
+				})
+			})
+		})
+		_ = dslengine.Run()
+
+		res := design.Design.Resources["res"].Actions["action"].Responses["foo"]
+		if err := res.Validate(); err == nil {
+			t.Error("Validate() = nil; want an error")
+		}
+	})
 }
@@ -1,9 +1,9 @@
 package design
 
 import (
-	"errors"
 	"fmt"
 	"iter"
+	"maps"
 	"net/http"
 	"path"
 	"slices"
@@ -145,6 +145,9 @@ type ResourceDefinition struct {
 	// Security defines security requirements for the Resource,
 	// for actions that don't define one themselves.
 	Security *SecurityDefinition
+	// CacheControl is the Cache-Control header value of the responses of the resource GET and
+	// HEAD actions.
+	CacheControl string
 }
 
 // CORSDefinition contains the definition for a specific origin CORS policy.
@@ -197,6 +200,9 @@ type ResponseDefinition struct {
 	MediaType string
 	// Response view name if MediaType is MediaTypeDefinition
 	ViewName string
+	// Stream is the content type used to stream a sequence of values in the response body if
+	// any, see StreamNDJSON and StreamSSE.
+	Stream string
 	// Response header definitions
 	Headers *AttributeDefinition
 	// Parent action or resource
@@ -207,6 +213,27 @@ type ResponseDefinition struct {
 	Standard bool
 }
 
+// ErrorDefinition defines a named error that an action may return. Errors are rendered using the
+// action response with the same name as the error Response field.
+type ErrorDefinition struct {
+	// Name is the error code, e.g. "not_found"
+	Name string
+	// Response is the name of the response used to render the error, e.g. "NotFound"
+	Response string
+	// Status is the HTTP status of the error response, it is initialized from the response
+	// when the design is finalized.
+	Status int
+	// Error description
+	Description string
+	// MediaType is the identifier of the error response media type, ErrorMediaIdentifier by
+	// default, ProblemMediaIdentifier or the identifier of a media type defined in the design.
+	MediaType string
+	// Parent action
+	Parent *ActionDefinition
+	// Metadata is a list of key/value pairs
+	Metadata dslengine.MetadataDefinition
+}
+
 // ResponseTemplateDefinition defines a response template.
 // A response template is a function that takes an arbitrary number
 // of strings and returns a response definition.
@@ -238,6 +265,8 @@ type ActionDefinition struct {
 	Routes []*RouteDefinition
 	// Map of possible response definitions indexed by name
 	Responses map[string]*ResponseDefinition
+	// Errors lists the named errors the action may return in order of definition
+	Errors []*ErrorDefinition
 	// Path and query string parameters
 	Params *AttributeDefinition
 	// Query string parameters only
@@ -248,6 +277,12 @@ type ActionDefinition struct {
 	PayloadOptional bool
 	// PayloadOptional is true if the request payload is multipart, false otherwise.
 	PayloadMultipart bool
+	// Cacheable is true if the action supports conditional requests, false otherwise.
+	Cacheable bool
+	// CacheControl is the Cache-Control header value of the action responses.
+	CacheControl string
+	// Pagination describes how the action results are paginated if they are.
+	Pagination *PaginationDefinition
 	// Request headers that need to be made available to action
 	Headers *AttributeDefinition
 	// Metadata is a list of key/value pairs
@@ -256,6 +291,24 @@ type ActionDefinition struct {
 	Security *SecurityDefinition
 }
 
+// PaginationDefinition describes how the results of a list action are split into pages.
+type PaginationDefinition struct {
+	// CursorParam is the name of the query string parameter that holds the cursor of the
+	// requested page, e.g. "cursor".
+	CursorParam string
+	// PageSizeParam is the name of the query string parameter that holds the maximum number of
+	// items of the requested page, e.g. "limit".
+	PageSizeParam string
+	// NextCursor is the name of the OK response attribute that holds the cursor of the next page.
+	// The next page is given by the Link header with the "next" relation if empty.
+	NextCursor string
+	// Items is the name of the OK response attribute that holds the page items. The OK response
+	// media type is a collection of the items if empty.
+	Items string
+	// Parent action
+	Parent *ActionDefinition
+}
+
 // FileServerDefinition defines an endpoint that servers static assets.
 type FileServerDefinition struct {
 	// Parent resource
@@ -681,6 +734,29 @@ func (a *APIDefinition) MediaTypeWithIdentifier(id string) *MediaTypeDefinition
 	return nil
 }
 
+// AllErrors returns an iterator that yields the errors declared by all the API actions sorted
+// by name. Errors with the same name are yielded once.
+func (a *APIDefinition) AllErrors() iter.Seq[*ErrorDefinition] {
+	byName := make(map[string]*ErrorDefinition)
+	for r := range a.AllResources() {
+		for ac := range r.AllActions() {
+			for _, e := range ac.Errors {
+				if prev, ok := byName[e.Name]; !ok || (prev.Description == "" && e.Description != "") {
+					byName[e.Name] = e
+				}
+			}
+		}
+	}
+	names := slices.Sorted(maps.Keys(byName))
+	return func(yield func(*ErrorDefinition) bool) {
+		for _, name := range names {
+			if !yield(byName[name]) {
+				return
+			}
+		}
+	}
+}
+
 // AllResources returns an iterator over all the resources.
 // If there is a parent-child relationship between resources,
 // they are sorted in the order of parent first and child second.
@@ -776,32 +852,34 @@ func (a *APIDefinition) Finalize() {
 	if len(a.Produces) == 0 {
 		a.Produces = DefaultEncoders
 	}
-	a.IterateResources(func(r *ResourceDefinition) error {
-		returnsError := func(resp *ResponseDefinition) bool {
-			if resp.MediaType == ErrorMediaIdentifier {
-				if a.MediaTypes == nil {
-					a.MediaTypes = make(map[string]*MediaTypeDefinition)
-				}
-				a.MediaTypes[CanonicalIdentifier(ErrorMediaIdentifier)] = ErrorMedia
-				return true
-			}
-			return false
+	register := func(identifier string) {
+		var mt *MediaTypeDefinition
+		switch identifier {
+		case ErrorMediaIdentifier:
+			mt = ErrorMedia
+		case ProblemMediaIdentifier:
+			mt = ProblemMedia
+		default:
+			return
 		}
-		for _, resp := range a.Responses {
-			if returnsError(resp) {
-				return errors.New("done")
-			}
+		if a.MediaTypes == nil {
+			a.MediaTypes = make(map[string]*MediaTypeDefinition)
 		}
+		a.MediaTypes[CanonicalIdentifier(mt.Identifier)] = mt
+	}
+	for _, resp := range a.Responses {
+		register(resp.MediaType)
+	}
+	a.IterateResources(func(r *ResourceDefinition) error {
 		for _, resp := range r.Responses {
-			if returnsError(resp) {
-				return errors.New("done")
-			}
+			register(resp.MediaType)
 		}
 		return r.IterateActions(func(action *ActionDefinition) error {
 			for _, resp := range action.Responses {
-				if returnsError(resp) {
-					return errors.New("done")
-				}
+				register(resp.MediaType)
+			}
+			for _, e := range action.Errors {
+				register(e.MediaType)
 			}
 			return nil
 		})
@@ -1536,6 +1614,7 @@ func (r *ResponseDefinition) Dup() *ResponseDefinition {
 		Description: r.Description,
 		MediaType:   r.MediaType,
 		ViewName:    r.ViewName,
+		Stream:      r.Stream,
 	}
 	if r.Headers != nil {
 		res.Headers = DupAtt(r.Headers)
@@ -1561,6 +1640,9 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 		r.MediaType = other.MediaType
 		r.ViewName = other.ViewName
 	}
+	if r.Stream == "" {
+		r.Stream = other.Stream
+	}
 	if other.Headers != nil {
 		otherHeaders := other.Headers.Type.ToObject()
 		if len(otherHeaders) > 0 {
@@ -1599,6 +1681,14 @@ func (a *ActionDefinition) Context() string {
 	return prefix + suffix
 }
 
+// Context returns the generic definition name used in error messages.
+func (p *PaginationDefinition) Context() string {
+	if p.Parent != nil {
+		return "pagination of " + p.Parent.Context()
+	}
+	return "pagination"
+}
+
 // PathParams returns the path parameters of the action across all its routes.
 func (a *ActionDefinition) PathParams() *AttributeDefinition {
 	obj := make(Object)
@@ -1643,6 +1733,57 @@ func (a *ActionDefinition) HasAbsoluteRoutes() bool {
 	return true
 }
 
+// RateLimit returns the rate limit of the action declared with the "ratelimit" metadata of the
+// action or of its parent resource, e.g. "100/m". It returns an empty string if the action is not
+// rate limited.
+func (a *ActionDefinition) RateLimit() string {
+	if l, ok := a.Metadata[rateLimitMetadata]; ok && len(l) > 0 {
+		return l[0]
+	}
+	if a.Parent != nil {
+		if l, ok := a.Parent.Metadata[rateLimitMetadata]; ok && len(l) > 0 {
+			return l[0]
+		}
+	}
+	return ""
+}
+
+// idempotentMetadata is the name of the metadata that declares actions idempotent.
+const idempotentMetadata = "idempotent"
+
+// Idempotent returns true if the action or its parent resource is declared idempotent with the
+// "idempotent" metadata, such actions replay their first response to the retries that carry the
+// same Idempotency-Key header.
+func (a *ActionDefinition) Idempotent() bool {
+	if _, ok := a.Metadata[idempotentMetadata]; ok {
+		return true
+	}
+	if a.Parent != nil {
+		_, ok := a.Parent.Metadata[idempotentMetadata]
+		return ok
+	}
+	return false
+}
+
+// CachePolicy returns the Cache-Control header value of the successful responses of the action
+// declared with the CacheControl DSL in the action or in its parent resource, e.g.
+// "public, max-age=60". Only the actions whose routes all use the GET or HEAD methods inherit the
+// policy of the resource. It returns an empty string if the action declares no policy.
+func (a *ActionDefinition) CachePolicy() string {
+	if a.CacheControl != "" {
+		return a.CacheControl
+	}
+	if a.Parent == nil || len(a.Routes) == 0 {
+		return ""
+	}
+	for _, r := range a.Routes {
+		if r.Verb != "GET" && r.Verb != "HEAD" {
+			return ""
+		}
+	}
+	return a.Parent.CacheControl
+}
+
 // CanonicalScheme returns the preferred scheme for making requests. Favor secure schemes.
 func (a *ActionDefinition) CanonicalScheme() string {
 	if a.WebSocket() {
@@ -1715,6 +1856,7 @@ func (a *ActionDefinition) Finalize() {
 	}
 
 	a.mergeResponses()
+	a.initErrorResponses()
 	a.initImplicitParams()
 	a.initQueryParams()
 }
@@ -1834,6 +1976,28 @@ func (a *ActionDefinition) mergeResponses() {
 	}
 }
 
+// initErrorResponses creates the responses used by the action errors that the action does not
+// define explicitly and initializes the status of the errors.
+func (a *ActionDefinition) initErrorResponses() {
+	for _, e := range a.Errors {
+		resp, ok := a.Responses[e.Response]
+		if !ok {
+			resp = &ResponseDefinition{Name: e.Response, MediaType: e.MediaType, Parent: a}
+			if ar, ok := Design.Responses[e.Response]; ok {
+				resp.Merge(ar)
+			}
+			if dr, ok := Design.DefaultResponses[e.Response]; ok {
+				resp.Merge(dr)
+			}
+			if a.Responses == nil {
+				a.Responses = make(map[string]*ResponseDefinition)
+			}
+			a.Responses[e.Response] = resp
+		}
+		e.Status = resp.Status
+	}
+}
+
 // initImplicitParams creates params for path segments that don't have one.
 func (a *ActionDefinition) initImplicitParams() {
 	for _, ro := range a.Routes {
@@ -1988,6 +2152,34 @@ func (v *ViewDefinition) Context() string {
 	return prefix + suffix
 }
 
+// Context returns the generic definition name used in error messages.
+func (e *ErrorDefinition) Context() string {
+	if e.Parent != nil {
+		return fmt.Sprintf("error %#v of %s", e.Name, e.Parent.Context())
+	}
+	return fmt.Sprintf("error %#v", e.Name)
+}
+
+// ResponseStatus returns the status of the response used to render the error looking up the
+// response in the action, its parent resource and the API in that order. It returns 0 if there is
+// no such response.
+func (e *ErrorDefinition) ResponseStatus() int {
+	var lookups []map[string]*ResponseDefinition
+	if e.Parent != nil {
+		lookups = append(lookups, e.Parent.Responses)
+		if e.Parent.Parent != nil {
+			lookups = append(lookups, e.Parent.Parent.Responses)
+		}
+	}
+	lookups = append(lookups, Design.Responses, Design.DefaultResponses)
+	for _, responses := range lookups {
+		if r, ok := responses[e.Response]; ok && r.Status != 0 {
+			return r.Status
+		}
+	}
+	return 0
+}
+
 // Context returns the generic definition name used in error messages.
 func (r *RouteDefinition) Context() string {
 	return fmt.Sprintf(`route %s "%s" of %s`, r.Verb, r.Path, r.Parent.Context())
@@ -120,6 +120,25 @@ func TestActionDefinition_Finalize(t *testing.T) {
 	}
 }
 
+func TestActionDefinition_Idempotent(t *testing.T) {
+	resource := &ResourceDefinition{}
+	action := &ActionDefinition{Parent: resource}
+	if action.Idempotent() {
+		t.Error("action without metadata must not be idempotent")
+	}
+
+	action.Metadata = dslengine.MetadataDefinition{"idempotent": nil}
+	if !action.Idempotent() {
+		t.Error("does not use the action metadata")
+	}
+
+	action.Metadata = nil
+	resource.Metadata = dslengine.MetadataDefinition{"idempotent": nil}
+	if !action.Idempotent() {
+		t.Error("does not use the resource metadata")
+	}
+}
+
 func TestRouteDefinition_FullPath(t *testing.T) {
 	Design.Reset()
 
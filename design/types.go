@@ -745,12 +745,23 @@ func (m *MediaTypeDefinition) Kind() Kind { return MediaTypeKind }
 
 // IsError returns true if the media type is implemented via a shogoa struct.
 func (m *MediaTypeDefinition) IsError() bool {
+	id := m.baseIdentifier()
+	return id == ErrorMedia.Identifier || id == ProblemMedia.Identifier
+}
+
+// IsProblem returns true if the media type is the built-in RFC 9457 problem details media type.
+func (m *MediaTypeDefinition) IsProblem() bool {
+	return m.baseIdentifier() == ProblemMedia.Identifier
+}
+
+// baseIdentifier returns the media type identifier sans view parameter.
+func (m *MediaTypeDefinition) baseIdentifier() string {
 	base, params, err := mime.ParseMediaType(m.Identifier)
 	if err != nil {
 		panic("invalid media type identifier " + m.Identifier) // bug
 	}
 	delete(params, "view")
-	return mime.FormatMediaType(base, params) == ErrorMedia.Identifier
+	return mime.FormatMediaType(base, params)
 }
 
 // ComputeViews returns the media type views recursing as necessary if the media type is a
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/shogo82148/shogoa/dslengine"
@@ -118,6 +119,7 @@ func (a *APIDefinition) Validate() error {
 	})
 
 	a.validateRoutes(verr, allRoutes)
+	a.validateErrors(verr)
 
 	a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
 		verr.Merge(mt.Validate())
@@ -178,6 +180,30 @@ func (a *APIDefinition) validateRoutes(verr *dslengine.ValidationErrors, routes
 	}
 }
 
+// validateErrors checks that errors with the same code use the same status and media type across
+// actions.
+func (a *APIDefinition) validateErrors(verr *dslengine.ValidationErrors) {
+	defs := make(map[string]*ErrorDefinition)
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			for _, e := range ac.Errors {
+				prev, ok := defs[e.Name]
+				if !ok {
+					defs[e.Name] = e
+					continue
+				}
+				if status, s := e.ResponseStatus(), prev.ResponseStatus(); status != s {
+					verr.Add(e, "error %#v uses status %d but is defined with status %d elsewhere", e.Name, status, s)
+				}
+				if e.MediaType != prev.MediaType {
+					verr.Add(e, "error %#v uses media type %#v but is defined with media type %#v elsewhere", e.Name, e.MediaType, prev.MediaType)
+				}
+			}
+			return nil
+		})
+	})
+}
+
 func (a *APIDefinition) validateContact(verr *dslengine.ValidationErrors) {
 	if a.Contact != nil && a.Contact.URL != "" {
 		if _, err := url.ParseRequestURI(a.Contact.URL); err != nil {
@@ -228,6 +254,8 @@ func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
 	for _, origin := range r.Origins {
 		verr.Merge(origin.Validate())
 	}
+	validateRateLimit(r, r.Metadata, verr)
+	validateCacheControl(r, r.CacheControl, verr)
 	return verr.AsError()
 }
 
@@ -340,7 +368,20 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 			verr.Add(a, "Response %s contains an invalid type, action responses cannot contain a file", i)
 		}
 	}
+	for i, e := range a.Errors {
+		verr.Merge(e.Validate())
+		for _, e2 := range a.Errors[:i] {
+			if e.Name == e2.Name {
+				verr.Add(e, "error %#v is defined twice", e.Name)
+			}
+		}
+	}
 	verr.Merge(a.ValidateParams())
+	validateRateLimit(a, a.Metadata, verr)
+	validateCacheControl(a, a.CacheControl, verr)
+	if a.Pagination != nil {
+		verr.Merge(a.Pagination.Validate())
+	}
 	if a.Payload != nil {
 		verr.Merge(a.Payload.Validate("action payload", a))
 		if HasFile(a.Payload.Type) && !a.PayloadMultipart {
@@ -373,6 +414,65 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	return verr.AsError()
 }
 
+// rateLimitMetadata is the name of the metadata that declares the rate limit of actions.
+const rateLimitMetadata = "ratelimit"
+
+// rateLimitRegex matches the rate limits accepted by the ratelimit middleware, e.g. "100/m" or
+// "10/30s".
+var rateLimitRegex = regexp.MustCompile(`^[1-9][0-9]*/([1-9][0-9]*)?[smhd]$`)
+
+// validateRateLimit checks the "ratelimit" metadata of def if any.
+func validateRateLimit(def dslengine.Definition, md dslengine.MetadataDefinition, verr *dslengine.ValidationErrors) {
+	l, ok := md[rateLimitMetadata]
+	if !ok {
+		return
+	}
+	if len(l) != 1 || !rateLimitRegex.MatchString(l[0]) {
+		verr.Add(def, `invalid "ratelimit" metadata %q, must be of the form <requests>/<period>, e.g. "100/m"`, l)
+	}
+}
+
+// cacheDirectives lists the Cache-Control response directives, the value indicates whether the
+// directive takes a number of seconds as argument.
+var cacheDirectives = map[string]bool{
+	"public":                 false,
+	"private":                false,
+	"no-cache":               false,
+	"no-store":               false,
+	"no-transform":           false,
+	"must-revalidate":        false,
+	"proxy-revalidate":       false,
+	"must-understand":        false,
+	"immutable":              false,
+	"max-age":                true,
+	"s-maxage":               true,
+	"stale-while-revalidate": true,
+	"stale-if-error":         true,
+}
+
+// validateCacheControl checks the Cache-Control value declared with the CacheControl DSL if any.
+func validateCacheControl(def dslengine.Definition, cc string, verr *dslengine.ValidationErrors) {
+	if cc == "" {
+		return
+	}
+	for _, d := range strings.Split(cc, ",") {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(d), "=")
+		name = strings.ToLower(name)
+		seconds, ok := cacheDirectives[name]
+		if !ok {
+			verr.Add(def, "unknown Cache-Control directive %q", name)
+			continue
+		}
+		if seconds {
+			if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+				verr.Add(def, "Cache-Control directive %q requires a number of seconds", name)
+			}
+		} else if hasValue && name != "private" && name != "no-cache" {
+			verr.Add(def, "Cache-Control directive %q does not take a value", name)
+		}
+	}
+}
+
 // Validate checks the file server is properly initialized.
 func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -519,6 +619,100 @@ func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Status == 0 {
 		verr.Add(r, "response status not defined")
 	}
+	if r.Stream != "" && r.Type == nil && Design.MediaTypeWithIdentifier(r.MediaType) == nil {
+		verr.Add(r, "streamed response must define a media type or a type")
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the error definition is consistent: its name is a valid code, its response
+// exists and has an error status and its media type is defined in the design.
+func (e *ErrorDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if e.Name == "" {
+		verr.Add(e, "error name cannot be empty")
+	}
+	if status := e.ResponseStatus(); status == 0 {
+		verr.Add(e, "unknown response %#v, define it with Response or use a response template name", e.Response)
+	} else if status < 400 {
+		verr.Add(e, "response %#v must have an error status, got %d", e.Response, status)
+	}
+	switch e.MediaType {
+	case ErrorMediaIdentifier, ProblemMediaIdentifier:
+	default:
+		if Design.MediaTypeWithIdentifier(e.MediaType) == nil {
+			verr.Add(e, "unknown error media type %#v", e.MediaType)
+		}
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the pagination definition is consistent: its cursor and page size
+// parameters are query string parameters of the action, the cursor is optional and the view used
+// by the action OK response holds the page items and the next page cursor if declared.
+func (p *PaginationDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	a := p.Parent
+	if a == nil || a.Parent == nil {
+		verr.Add(p, "missing pagination parent action")
+		return verr.AsError()
+	}
+	params := a.AllParams().Type.ToObject()
+	pathParams := a.PathParams().Type.ToObject()
+	checkParam := func(name, role string, kind Kind) {
+		param, ok := params[name]
+		if _, isPath := pathParams[name]; !ok || isPath {
+			verr.Add(p, "%s parameter %#v is not a query string parameter of the action", role, name)
+		} else if param.Type.Kind() != kind {
+			verr.Add(p, "%s parameter %#v must be of type %s", role, name, Primitive(kind).Name())
+		}
+	}
+	checkParam(p.CursorParam, "cursor", StringKind)
+	checkParam(p.PageSizeParam, "page size", IntegerKind)
+	if a.AllParams().IsRequired(p.CursorParam) {
+		verr.Add(p, "cursor parameter %#v must not be required, the first page is requested without cursor", p.CursorParam)
+	}
+
+	if (p.NextCursor == "") != (p.Items == "") {
+		verr.Add(p, "the next cursor and the page items attributes must be declared together")
+	}
+	var ok *ResponseDefinition
+	for _, r := range a.Responses {
+		if r.Status == 200 {
+			ok = r
+		}
+	}
+	if ok == nil {
+		verr.Add(p, "paginated action must define an OK response")
+		return verr.AsError()
+	}
+	mt := Design.MediaTypeWithIdentifier(ok.MediaType)
+	switch {
+	case mt == nil || ok.Stream != "":
+		verr.Add(p, "OK response of paginated action must have a media type and must not be streamed")
+	case p.Items == "":
+		if !mt.IsArray() {
+			verr.Add(p, "OK response media type %#v must be a collection, use NextCursor and PageItems otherwise", mt.Identifier)
+		}
+	default:
+		view := ok.ViewName
+		if view == "" {
+			view = DefaultView
+		}
+		v, found := mt.Views[view]
+		if !found {
+			verr.Add(p, "OK response media type %#v has no view %#v", mt.Identifier, view)
+			break
+		}
+		// the attributes must be rendered by the view used by the OK response
+		obj := v.Type.ToObject()
+		if att := obj[p.Items]; att == nil || !att.Type.IsArray() {
+			verr.Add(p, "page items attribute %#v of media type %#v view %#v must be an array", p.Items, mt.Identifier, view)
+		}
+		if att := obj[p.NextCursor]; att == nil || att.Type.Kind() != StringKind {
+			verr.Add(p, "next cursor attribute %#v of media type %#v view %#v must be a string", p.NextCursor, mt.Identifier, view)
+		}
+	}
 	return verr.AsError()
 }
 
@@ -285,6 +285,205 @@ func TestValidation(t *testing.T) {
 
 	t.Run("with an action", func(t *testing.T) {
 
+		t.Run("which has a valid rate limit", func(t *testing.T) {
+			dslengine.Reset()
+			apidsl.Resource("foo", func() {
+				apidsl.Metadata("ratelimit", "1000/h")
+				apidsl.Action("bar", func() {
+					apidsl.Routing(apidsl.GET("/buz"))
+					apidsl.Metadata("ratelimit", "10/30s")
+				})
+				apidsl.Action("baz", func() {
+					apidsl.Routing(apidsl.GET("/baz"))
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST("/baz"))
+				})
+			})
+			if err := dslengine.Run(); err != nil {
+				t.Fatal(err)
+			}
+			res := design.Design.Resources["foo"]
+			if l := res.Actions["bar"].RateLimit(); l != "10/30s" {
+				t.Errorf("unexpected action rate limit: %q", l)
+			}
+			if l := res.Actions["baz"].RateLimit(); l != "1000/h" {
+				t.Errorf("unexpected resource rate limit: %q", l)
+			}
+		})
+
+		t.Run("which has an invalid rate limit", func(t *testing.T) {
+			dslengine.Reset()
+			apidsl.Resource("foo", func() {
+				apidsl.Action("bar", func() {
+					apidsl.Routing(apidsl.GET("/buz"))
+					apidsl.Metadata("ratelimit", "100/week")
+				})
+			})
+			if err := dslengine.Run(); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+
+		t.Run("which has a valid cache policy", func(t *testing.T) {
+			dslengine.Reset()
+			apidsl.Resource("foo", func() {
+				apidsl.CacheControl("public, max-age=60")
+				apidsl.Action("bar", func() {
+					apidsl.Routing(apidsl.GET("/buz"))
+					apidsl.CacheControl("private, max-age=10, stale-while-revalidate=30")
+				})
+				apidsl.Action("baz", func() {
+					apidsl.Routing(apidsl.GET("/baz"))
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST("/baz"))
+				})
+			})
+			if err := dslengine.Run(); err != nil {
+				t.Fatal(err)
+			}
+			res := design.Design.Resources["foo"]
+			if cc := res.Actions["bar"].CachePolicy(); cc != "private, max-age=10, stale-while-revalidate=30" {
+				t.Errorf("unexpected action cache policy: %q", cc)
+			}
+			if cc := res.Actions["baz"].CachePolicy(); cc != "public, max-age=60" {
+				t.Errorf("unexpected resource cache policy: %q", cc)
+			}
+			if cc := res.Actions["create"].CachePolicy(); cc != "" {
+				t.Errorf("unexpected cache policy of an unsafe action: %q", cc)
+			}
+		})
+
+		t.Run("which has an invalid cache policy", func(t *testing.T) {
+			for _, cc := range []string{"max-age", "public, max-age=1m", "cache-forever", "no-store=true"} {
+				dslengine.Reset()
+				apidsl.Resource("foo", func() {
+					apidsl.Action("bar", func() {
+						apidsl.Routing(apidsl.GET("/buz"))
+						apidsl.CacheControl(cc)
+					})
+				})
+				if err := dslengine.Run(); err == nil {
+					t.Errorf("%q: expected an error", cc)
+				}
+			}
+		})
+
+		// paginated defines a paginated action whose OK response is a "collection" of bottles, a "page"
+		// of bottles, a page rendered with the "items" view that omits the next cursor or nothing.
+		paginated := func(cursorType design.DataType, paginate func(), response string) {
+			dslengine.Reset()
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("name", design.String)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("name")
+				})
+			})
+			page := apidsl.MediaType("application/vnd.bottle-page+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("bottles", apidsl.CollectionOf(bottle))
+					apidsl.Attribute("next", design.String)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("bottles")
+					apidsl.Attribute("next")
+				})
+				apidsl.View("items", func() {
+					apidsl.Attribute("bottles")
+				})
+			})
+			apidsl.Resource("foo", func() {
+				apidsl.Action("bar", func() {
+					apidsl.Routing(apidsl.GET("/buz/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+						apidsl.Param("cursor", cursorType)
+						apidsl.Param("limit", design.Integer)
+					})
+					paginate()
+					switch response {
+					case "collection":
+						apidsl.Response(design.OK, apidsl.CollectionOf(bottle))
+					case "page":
+						apidsl.Response(design.OK, page)
+					case "page items":
+						apidsl.Response(design.OK, func() { apidsl.Media(page, "items") })
+					}
+				})
+			})
+		}
+
+		t.Run("which is paginated", func(t *testing.T) {
+			paginated(design.String, func() { apidsl.Paginated("cursor", "limit") }, "collection")
+			if err := dslengine.Run(); err != nil {
+				t.Fatal(err)
+			}
+			p := design.Design.Resources["foo"].Actions["bar"].Pagination
+			if p == nil || p.CursorParam != "cursor" || p.PageSizeParam != "limit" || p.NextCursor != "" || p.Items != "" {
+				t.Errorf("unexpected pagination %+v", p)
+			}
+		})
+
+		t.Run("which is paginated with a next cursor attribute", func(t *testing.T) {
+			paginated(design.String, func() {
+				apidsl.Paginated("cursor", "limit", func() {
+					apidsl.NextCursor("next")
+					apidsl.PageItems("bottles")
+				})
+			}, "page")
+			if err := dslengine.Run(); err != nil {
+				t.Fatal(err)
+			}
+			p := design.Design.Resources["foo"].Actions["bar"].Pagination
+			if p == nil || p.NextCursor != "next" || p.Items != "bottles" {
+				t.Errorf("unexpected pagination %+v", p)
+			}
+		})
+
+		t.Run("which has an invalid pagination", func(t *testing.T) {
+			cases := map[string]struct {
+				cursorType design.DataType
+				paginate   func()
+				response   string
+			}{
+				"unknown cursor":     {design.String, func() { apidsl.Paginated("after", "limit") }, "collection"},
+				"path param cursor":  {design.String, func() { apidsl.Paginated("id", "limit") }, "collection"},
+				"integer cursor":     {design.Integer, func() { apidsl.Paginated("cursor", "limit") }, "collection"},
+				"string page size":   {design.String, func() { apidsl.Paginated("cursor", "cursor") }, "collection"},
+				"not a collection":   {design.String, func() { apidsl.Paginated("cursor", "limit") }, "page"},
+				"missing page items": {design.String, func() { apidsl.Paginated("cursor", "limit", func() { apidsl.NextCursor("next") }) }, "page"},
+				"invalid page items": {design.String, func() {
+					apidsl.Paginated("cursor", "limit", func() {
+						apidsl.NextCursor("next")
+						apidsl.PageItems("next")
+					})
+				}, "page"},
+				"no OK response": {design.String, func() { apidsl.Paginated("cursor", "limit") }, ""},
+				"required cursor": {design.String, func() {
+					apidsl.Params(func() {
+						apidsl.Param("cursor", design.String)
+						apidsl.Required("cursor")
+					})
+					apidsl.Paginated("cursor", "limit")
+				}, "collection"},
+				"next cursor not in view": {design.String, func() {
+					apidsl.Paginated("cursor", "limit", func() {
+						apidsl.NextCursor("next")
+						apidsl.PageItems("bottles")
+					})
+				}, "page items"},
+			}
+			for name, c := range cases {
+				paginated(c.cursorType, c.paginate, c.response)
+				if err := dslengine.Run(); err == nil {
+					t.Errorf("%s: expected an error", name)
+				}
+			}
+		})
+
 		t.Run("which has a file type param", func(t *testing.T) {
 			dslengine.Reset()
 			apidsl.Resource("foo", func() {
@@ -67,6 +67,15 @@ and implements:
 
 The definitions of the Bottle and UpdateBottlePayload data structures are omitted for brevity.
 
+# Lifecycle
+
+Service.Run serves requests on one or more listeners until its context is cancelled or the process
+receives SIGINT or SIGTERM. It then shuts the service down gracefully: requests are still served
+for ShutdownDelay, then new requests are refused, in-flight requests are given ShutdownTimeout to
+complete and the hooks registered with OnStart and OnShutdown let the application open and release
+resources such as database pools. ServeHealth mounts liveness and readiness endpoints that run the
+application health checks, the readiness endpoint fails as soon as the shutdown begins.
+
 # Controllers
 
 There is one controller interface generated per resource defined via the design language. The
@@ -108,7 +117,18 @@ code generated by shogoagen.
 The shogoa design language makes it possible to specify the encodings supported by the API both as
 input (Consumes) and output (Produces). shogoagen uses that information to registered the corresponding
 packages with the service encoders and decoders via their Register methods. The service exposes the
-DecodeRequest and EncodeResponse that implement a simple content type negotiation algorithm for
-picking the right encoder for the "Content-Type" (decoder) or "Accept" (encoder) request header.
+DecodeRequest and EncodeResponse that implement a content type negotiation algorithm for picking
+the right encoder for the "Content-Type" (decoder) or "Accept" (encoder) request header. Encoders
+are negotiated following RFC 7231: quality values and "type/*" media ranges are taken into account
+and requests that accept none of the registered encodings are rejected with a 406 Not Acceptable
+error.
+
+# Streaming
+
+Responses defined with the Stream or ServerSentEvents DSL send a sequence of values with SendStream
+as newline delimited JSON or server-sent events. Handlers that need more control over server-sent
+events such as event types, IDs, heartbeats or resuming from the Last-Event-ID request header use
+an EventStream created with NewEventStream. Streams stop when the request context is cancelled or
+when the service CancelAll method is called.
 */
 package shogoa
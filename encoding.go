@@ -4,9 +4,11 @@ import (
 	"encoding/gob"
 	"encoding/json"
 	"encoding/xml"
-	"fmt"
 	"io"
 	"mime"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -65,7 +67,7 @@ type (
 	// known Content-Type to encoder mapping.
 	HTTPEncoder struct {
 		pools        map[string]*encoderPool // Registered encoders
-		contentTypes []string                // List of content types for type negotiation
+		contentTypes []string                // Registered content types in registration order
 	}
 )
 
@@ -101,7 +103,9 @@ func NewHTTPDecoder() *HTTPDecoder {
 	}
 }
 
-// Decode uses registered Decoders to unmarshal a body based on the contentType.
+// Decode uses registered Decoders to unmarshal a body based on the contentType. Content types with a
+// structured syntax suffix such as "application/problem+json" fall back to the decoder registered
+// for the suffix ("application/json").
 func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType string) error {
 	var p *decoderPool
 	if contentType == "" {
@@ -113,6 +117,12 @@ func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType st
 		}
 	}
 	p = decoder.pools[contentType]
+	if p == nil {
+		typ, subtype, _ := strings.Cut(contentType, "/")
+		if i := strings.LastIndexByte(subtype, '+'); i >= 0 {
+			p = decoder.pools[typ+"/"+subtype[i+1:]]
+		}
+	}
 	if p == nil {
 		p = decoder.pools["*/*"]
 	}
@@ -179,35 +189,132 @@ func (p *decoderPool) Put(d Decoder) {
 	p.pool.Put(d)
 }
 
-// Encode uses the registered encoders and given content type to marshal and write the given value
-// using the given writer.
+// Encode uses the registered encoders and given Accept header value to marshal and write the given
+// value using the given writer. The encoder is selected using the content negotiation algorithm
+// described in RFC 7231 section 5.3.2: each registered content type is given the quality value of
+// the most specific media range that matches it and the content type with the highest quality
+// wins. Media ranges with a structured syntax suffix such as "application/vnd.bottle+json" also
+// match the encoder registered for the suffix ("application/json").
+//
+// The encoder registered for "*/*" is the default encoder, it is used when the Accept header is
+// empty or when the best match is a "*/*" media range, unless the Accept header excludes one of
+// the other content types the default encoder is registered for with "q=0". Encode sets the
+// Content-Type header of the writer if it implements http.ResponseWriter and no Content-Type is
+// set yet. It returns an error of class ErrNotAcceptable if none of the registered encoders is
+// acceptable.
 func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string) error {
-	if accept == "" {
-		accept = "*/*"
-	}
-	var contentType string
-	for _, t := range encoder.contentTypes {
-		if accept == "*/*" || accept == t {
-			contentType = accept
-			break
+	p, contentType, err := encoder.negotiate(accept)
+	if err != nil {
+		return err
+	}
+	if rw, ok := resp.(http.ResponseWriter); ok && contentType != "" {
+		if rw.Header().Get("Content-Type") == "" {
+			rw.Header().Set("Content-Type", contentType)
 		}
 	}
-	p := encoder.pools[contentType]
-	if p == nil && contentType != "*/*" {
-		p = encoder.pools["*/*"]
+	return p.Encode(v, resp)
+}
+
+// ContentType returns the content type of the encoder that Encode selects for the given Accept
+// header value, "*/*" for a default encoder not registered for other content types. It returns an error of class ErrNotAcceptable if
+// none of the registered encoders is acceptable.
+func (encoder *HTTPEncoder) ContentType(accept string) (string, error) {
+	_, contentType, err := encoder.negotiate(accept)
+	if err != nil {
+		return "", err
 	}
-	if p == nil {
-		return fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
+	if contentType == "" {
+		return "*/*", nil
 	}
+	return contentType, nil
+}
 
-	// the encoderPool will handle whether or not a pool is actually in use
-	e := p.Get(resp)
-	if err := e.Encode(v); err != nil {
-		return err
+// negotiate returns the encoder pool and content type that best match the given Accept header
+// value. The content type is empty when a default encoder not registered for other content types
+// is selected.
+func (encoder *HTTPEncoder) negotiate(accept string) (*encoderPool, string, error) {
+	ranges := parseAccept(accept)
+	def := encoder.pools["*/*"]
+
+	var (
+		best        *encoderPool
+		contentType string
+		bestQ       float64
+		bestSpec    int
+	)
+	consider := func(p *encoderPool, ct string, q float64, spec int) {
+		if q <= 0 || spec == 0 {
+			return
+		}
+		if best == nil || q > bestQ || (q == bestQ && spec > bestSpec) {
+			best, contentType, bestQ, bestSpec = p, ct, q, spec
+		}
+	}
+	for _, ct := range encoder.contentTypes {
+		q, spec := acceptQuality(ranges, ct)
+		consider(encoder.pools[ct], ct, q, spec)
+	}
+	for _, r := range ranges {
+		i := strings.LastIndexByte(r.subtype, '+')
+		if i < 0 || r.typ == "*" {
+			continue
+		}
+		ct := r.typ + "/" + r.subtype
+		if _, ok := encoder.pools[ct]; ok {
+			continue
+		}
+		if p, ok := encoder.pools[r.typ+"/"+r.subtype[i+1:]]; ok {
+			consider(p, ct, r.q, specExact)
+		}
 	}
-	p.Put(e)
 
-	return nil
+	if def != nil && (best == nil || bestSpec == specAny) {
+		if q, _ := acceptQuality(ranges, "*/*"); q > 0 && !encoder.excluded(ranges, def) {
+			return def, encoder.registeredType(def), nil
+		}
+	}
+	if best == nil {
+		return nil, "", ErrNotAcceptable("no encoder registered for the accepted media types", "accept", accept)
+	}
+	return best, contentType, nil
+}
+
+// fallback returns the encoder used when the Accept header is disregarded: the default encoder if
+// any, the first registered encoder otherwise.
+func (encoder *HTTPEncoder) fallback() (*encoderPool, string) {
+	if p, ok := encoder.pools["*/*"]; ok {
+		return p, encoder.registeredType(p)
+	}
+	if len(encoder.contentTypes) > 0 {
+		ct := encoder.contentTypes[0]
+		return encoder.pools[ct], ct
+	}
+	return nil, ""
+}
+
+// excluded reports whether the given Accept header media ranges explicitly exclude with "q=0" one
+// of the content types the given encoder pool is registered for.
+func (encoder *HTTPEncoder) excluded(ranges []mediaRange, p *encoderPool) bool {
+	for _, ct := range encoder.contentTypes {
+		if encoder.pools[ct] != p {
+			continue
+		}
+		if q, spec := acceptQuality(ranges, ct); q <= 0 && spec > specAny {
+			return true
+		}
+	}
+	return false
+}
+
+// registeredType returns the first content type the given encoder pool is registered for, the
+// empty string if it is only registered for "*/*".
+func (encoder *HTTPEncoder) registeredType(p *encoderPool) string {
+	for _, ct := range encoder.contentTypes {
+		if encoder.pools[ct] == p {
+			return ct
+		}
+	}
+	return ""
 }
 
 // Register sets a specific encoder to be used for the specified content types. If an encoder is
@@ -219,14 +326,13 @@ func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
 		if err != nil {
 			mediaType = contentType
 		}
+		if _, ok := encoder.pools[mediaType]; !ok && mediaType != "*/*" {
+			// Keep track of the registration order, it is used to break ties during
+			// content negotiation.
+			encoder.contentTypes = append(encoder.contentTypes, mediaType)
+		}
 		encoder.pools[mediaType] = p
 	}
-
-	// Rebuild a unique index of registered content encoders to be used in EncodeResponse
-	encoder.contentTypes = make([]string, 0, len(encoder.pools))
-	for contentType := range encoder.pools {
-		encoder.contentTypes = append(encoder.contentTypes, contentType)
-	}
 }
 
 // newEncodePool checks to see if the EncoderFactory returns reusable encoders and if so, creates
@@ -267,3 +373,89 @@ func (p *encoderPool) Put(e Encoder) {
 	}
 	p.pool.Put(e)
 }
+
+// Encode marshals and writes the given value using an encoder of the pool.
+func (p *encoderPool) Encode(v interface{}, w io.Writer) error {
+	// the encoderPool will handle whether or not a pool is actually in use
+	e := p.Get(w)
+	if err := e.Encode(v); err != nil {
+		return err
+	}
+	p.Put(e)
+
+	return nil
+}
+
+// Specificities of the media ranges matching a content type, see RFC 7231 section 5.3.2.
+const (
+	specNone = iota
+	specAny
+	specType
+	specExact
+)
+
+// mediaRange is a media range of an Accept header value.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses an Accept header value into the list of media ranges it contains. An empty
+// value is equivalent to "*/*". Invalid media ranges are ignored.
+func parseAccept(accept string) []mediaRange {
+	if strings.TrimSpace(accept) == "" {
+		return []mediaRange{{typ: "*", subtype: "*", q: 1}}
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		if mediaType == "*" {
+			// Some clients send "*" instead of "*/*"
+			mediaType = "*/*"
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok || (typ == "*" && subtype != "*") {
+			continue
+		}
+		q := 1.0
+		if qv, ok := params["q"]; ok {
+			f, err := strconv.ParseFloat(qv, 64)
+			if err != nil || f < 0 || f > 1 {
+				continue
+			}
+			q = f
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality value given to the content type by the most specific media
+// range that matches it together with the specificity of the range.
+func acceptQuality(ranges []mediaRange, contentType string) (float64, int) {
+	typ, subtype, _ := strings.Cut(contentType, "/")
+	var q float64
+	spec := specNone
+	for _, r := range ranges {
+		s := specNone
+		switch {
+		case r.typ == "*" && r.subtype == "*":
+			s = specAny
+		case r.typ == typ && r.subtype == "*":
+			s = specType
+		case r.typ == typ && r.subtype == subtype:
+			s = specExact
+		}
+		if s > spec {
+			q, spec = r.q, s
+		}
+	}
+	return q, spec
+}
@@ -0,0 +1,250 @@
+package shogoa
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// namedEncoder is a test encoder that writes its name.
+type namedEncoder struct {
+	name string
+	w    io.Writer
+}
+
+func (e *namedEncoder) Encode(v interface{}) error {
+	_, err := fmt.Fprint(e.w, e.name)
+	return err
+}
+
+func newNamedEncoder(name string) EncoderFunc {
+	return func(w io.Writer) Encoder { return &namedEncoder{name: name, w: w} }
+}
+
+// testResponseWriter is a http.ResponseWriter that does not sniff the content type.
+type testResponseWriter struct {
+	strings.Builder
+	header http.Header
+}
+
+func (w *testResponseWriter) Header() http.Header { return w.header }
+
+func (w *testResponseWriter) WriteHeader(int) {}
+
+func TestHTTPEncoder_Encode(t *testing.T) {
+	encoder := NewHTTPEncoder()
+	encoder.Register(newNamedEncoder("json"), "application/json")
+	encoder.Register(newNamedEncoder("xml"), "application/xml", "text/xml")
+	encoder.Register(newNamedEncoder("msgpack"), "application/msgpack")
+	encoder.Register(newNamedEncoder("default"), "*/*")
+
+	cases := []struct {
+		accept      string
+		encoder     string
+		contentType string
+	}{
+		{"", "default", ""},
+		{"*/*", "default", ""},
+		{"application/json", "json", "application/json"},
+		{"application/json;q=0.9, application/msgpack", "msgpack", "application/msgpack"},
+		{"application/json;q=0.9, application/msgpack;q=0.5", "json", "application/json"},
+		{"text/*", "xml", "text/xml"},
+		{"text/*;q=0.5, application/*;q=0.8", "json", "application/json"},
+		{"application/*, application/json;q=0", "xml", "application/xml"},
+		{"text/html, application/xml;q=0.9, */*;q=0.8", "xml", "application/xml"},
+		{"text/html, */*;q=0.8", "default", ""},
+		{"application/vnd.bottle+json", "json", "application/vnd.bottle+json"},
+		{"application/json; charset=utf-8", "json", "application/json"},
+		{"invalid, application/json", "json", "application/json"},
+	}
+	for _, c := range cases {
+		t.Run(c.accept, func(t *testing.T) {
+			rw := &testResponseWriter{header: make(http.Header)}
+			if err := encoder.Encode(nil, rw, c.accept); err != nil {
+				t.Fatal(err)
+			}
+			if got := rw.String(); got != c.encoder {
+				t.Errorf("unexpected encoder: want %q, got %q", c.encoder, got)
+			}
+			if got := rw.Header().Get("Content-Type"); got != c.contentType {
+				t.Errorf("unexpected Content-Type: want %q, got %q", c.contentType, got)
+			}
+		})
+	}
+
+	t.Run("existing Content-Type", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		rw.Header().Set("Content-Type", "application/vnd.bottle+json")
+		if err := encoder.Encode(nil, rw, "application/json"); err != nil {
+			t.Fatal(err)
+		}
+		if got := rw.Header().Get("Content-Type"); got != "application/vnd.bottle+json" {
+			t.Errorf("unexpected Content-Type: %q", got)
+		}
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		for _, accept := range []string{"image/png", "application/json;q=0, */*;q=0"} {
+			err := encoder.Encode(nil, io.Discard, accept)
+			var serr ServiceError
+			if !errors.As(err, &serr) {
+				t.Fatalf("%s: unexpected error: %v", accept, err)
+			}
+			if serr.ResponseStatus() != http.StatusNotAcceptable {
+				t.Errorf("%s: unexpected status: %d", accept, serr.ResponseStatus())
+			}
+		}
+	})
+
+	t.Run("no default encoder", func(t *testing.T) {
+		encoder := NewHTTPEncoder()
+		encoder.Register(newNamedEncoder("xml"), "application/xml")
+		encoder.Register(newNamedEncoder("json"), "application/json")
+		var buf strings.Builder
+		if err := encoder.Encode(nil, &buf, "*/*"); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != "xml" {
+			t.Errorf("unexpected encoder: %q", got)
+		}
+	})
+}
+
+func TestHTTPEncoder_Encode_SharedDefault(t *testing.T) {
+	encoder := NewHTTPEncoder()
+	encoder.Register(newNamedEncoder("json"), "application/json", "*/*")
+	encoder.Register(newNamedEncoder("xml"), "application/xml")
+
+	cases := []struct {
+		accept      string
+		encoder     string
+		contentType string
+	}{
+		{"", "json", "application/json"},
+		{"*/*", "json", "application/json"},
+		{"text/html, */*;q=0.8", "json", "application/json"},
+		{"application/json;q=0, */*", "xml", "application/xml"},
+		{"text/html, application/json;q=0, */*;q=0.8", "xml", "application/xml"},
+	}
+	for _, c := range cases {
+		t.Run(c.accept, func(t *testing.T) {
+			rw := &testResponseWriter{header: make(http.Header)}
+			if err := encoder.Encode(nil, rw, c.accept); err != nil {
+				t.Fatal(err)
+			}
+			if got := rw.String(); got != c.encoder {
+				t.Errorf("unexpected encoder: want %q, got %q", c.encoder, got)
+			}
+			if got := rw.Header().Get("Content-Type"); got != c.contentType {
+				t.Errorf("unexpected Content-Type: want %q, got %q", c.contentType, got)
+			}
+		})
+	}
+
+	t.Run("not acceptable", func(t *testing.T) {
+		encoder := NewHTTPEncoder()
+		encoder.Register(newNamedEncoder("json"), "application/json", "*/*")
+		if _, err := encoder.ContentType("application/json;q=0, */*"); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestHTTPEncoder_ContentType(t *testing.T) {
+	encoder := NewHTTPEncoder()
+	encoder.Register(newNamedEncoder("json"), "application/json")
+	encoder.Register(newNamedEncoder("default"), "*/*")
+
+	cases := map[string]string{
+		"":                            "*/*",
+		"application/json":            "application/json",
+		"application/vnd.bottle+json": "application/vnd.bottle+json",
+		"text/html, */*;q=0.8":        "*/*",
+	}
+	for accept, want := range cases {
+		got, err := encoder.ContentType(accept)
+		if err != nil {
+			t.Fatalf("%s: %v", accept, err)
+		}
+		if got != want {
+			t.Errorf("%s: unexpected content type: want %q, got %q", accept, want, got)
+		}
+	}
+	if _, err := encoder.ContentType("application/json;q=0, */*;q=0"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestService_Send(t *testing.T) {
+	service := New("test")
+	service.Encoder = NewHTTPEncoder()
+	service.Encoder.Register(newNamedEncoder("json"), "application/json")
+
+	send := func(accept string, code int) (*httptest.ResponseRecorder, error) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", accept)
+		ctx := NewContext(rw, req, nil)
+		return rw, service.Send(ctx, code, nil)
+	}
+
+	t.Run("acceptable", func(t *testing.T) {
+		rw, err := send("application/*", 201)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rw.Code != 201 {
+			t.Errorf("unexpected status: %d", rw.Code)
+		}
+		if got := rw.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("unexpected Content-Type: %q", got)
+		}
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		rw, err := send("image/png", 200)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if rw.Body.Len() != 0 {
+			t.Errorf("unexpected response body: %q", rw.Body.String())
+		}
+	})
+
+	t.Run("error responses disregard the Accept header", func(t *testing.T) {
+		rw, err := send("image/png", 406)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rw.Code != 406 || rw.Body.String() != "json" {
+			t.Errorf("unexpected response: %d %q", rw.Code, rw.Body.String())
+		}
+	})
+}
+
+func TestHTTPDecoder_Decode(t *testing.T) {
+	decoder := NewHTTPDecoder()
+	decoder.Register(NewJSONDecoder, "application/json")
+
+	cases := map[string]string{
+		"json":              "application/json",
+		"parameters":        "application/json; charset=utf-8",
+		"structured suffix": "application/problem+json",
+		"default to JSON":   "",
+	}
+	for name, contentType := range cases {
+		t.Run(name, func(t *testing.T) {
+			var v map[string]string
+			if err := decoder.Decode(&v, strings.NewReader(`{"foo":"bar"}`), contentType); err != nil {
+				t.Fatal(err)
+			}
+			if v["foo"] != "bar" {
+				t.Errorf("unexpected value: %v", v)
+			}
+		})
+	}
+}
@@ -26,12 +26,18 @@ previously called middleware or action handler into HTTP responses. If the error
 error class then the corresponding content including the HTTP status is used otherwise an internal
 error is returned. Errors that bubble up all the way to the top (i.e. not handled by the error
 middleware) also generate an internal error response.
+
+The error handler middleware may also render errors as RFC 9457 problem details
+(application/problem+json), see NewProblemDetails and the ProblemErrors field of Service.
 */
 package shogoa
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/shogo82148/shogoa/internal/randid"
@@ -73,10 +79,25 @@ var (
 	// handler but not the HTTP method.
 	ErrMethodNotAllowed = NewErrorClass("method_not_allowed", 405)
 
+	// ErrNotAcceptable is the error produced when none of the media types accepted by the
+	// client can be produced by the service encoders.
+	ErrNotAcceptable = NewErrorClass("not_acceptable", 406)
+
 	// ErrPreconditionFailed is the error response code indicates that access to the
 	// target resource has been denied.
 	ErrPreconditionFailed = NewErrorClass("precondition_failed", 412)
 
+	// ErrConflict is the error produced when a request conflicts with a request being
+	// processed, e.g. a retry made before the original request completes.
+	ErrConflict = NewErrorClass("conflict", 409)
+
+	// ErrUnprocessableEntity is the error produced when a request is well-formed but cannot be
+	// processed, e.g. an idempotency key reused with a different payload.
+	ErrUnprocessableEntity = NewErrorClass("unprocessable_entity", 422)
+
+	// ErrTooManyRequests is the error produced when a client exceeds the rate limit of an action.
+	ErrTooManyRequests = NewErrorClass("too_many_requests", 429)
+
 	// ErrInternal is the class of error used for uncaught errors.
 	ErrInternal = NewErrorClass("internal", 500)
 )
@@ -125,6 +146,8 @@ type ErrorResponse struct {
 	Detail string `json:"detail" yaml:"detail" xml:"detail" form:"detail"`
 	// Meta contains additional key/value pairs useful to clients.
 	Meta map[string]any `json:"meta,omitempty" yaml:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
+
+	errors []*ErrorResponse // errors merged by MergeErrors
 }
 
 // NewErrorClass creates a new error class.
@@ -277,6 +300,32 @@ func (e *ErrorResponse) ResponseStatus() int { return e.Status }
 // Token is the unique error occurrence identifier.
 func (e *ErrorResponse) Token() string { return e.ID }
 
+// ErrorCode returns the code of the error class that produced err if err is or wraps an
+// *ErrorResponse or a *ProblemDetails, it returns an empty string otherwise.
+func ErrorCode(err error) string {
+	var resp *ErrorResponse
+	if errors.As(err, &resp) {
+		return resp.Code
+	}
+	var p *ProblemDetails
+	if errors.As(err, &p) {
+		code, _ := p.Extensions["code"].(string)
+		return code
+	}
+	return ""
+}
+
+// Errors returns the individual errors that were combined into e by MergeErrors. It returns a
+// copy of e if e is not the result of a merge.
+func (e *ErrorResponse) Errors() []*ErrorResponse {
+	if len(e.errors) > 0 {
+		return slices.Clone(e.errors)
+	}
+	c := *e
+	c.Meta = maps.Clone(e.Meta)
+	return []*ErrorResponse{&c}
+}
+
 // MergeErrors updates an error by merging another into it. It first converts other into a
 // ServiceError if not already one - producing an internal error in that case. The merge algorithm
 // is:
@@ -291,6 +340,8 @@ func (e *ErrorResponse) Token() string { return e.ID }
 // by a semi-colon. The MetaValues field of is updated by merging the map of other MetaValues
 // into e's where values in e with identical keys to values in other get overwritten.
 //
+// The individual errors remain available via the Errors method of the result.
+//
 // Merge returns the updated error. This is useful in case the error was initially nil in
 // which case other is returned.
 func MergeErrors(err, other error) error {
@@ -314,6 +365,7 @@ func MergeErrors(err, other error) error {
 
 	e := asErrorResponse(err)
 	o := asErrorResponse(other)
+	errs := append(e.Errors(), o.Errors()...)
 	switch {
 	case e.Status == http.StatusInternalServerError || o.Status == http.StatusInternalServerError:
 		if e.Status != http.StatusInternalServerError {
@@ -332,6 +384,7 @@ func MergeErrors(err, other error) error {
 	for k, v := range o.Meta {
 		e.Meta[k] = v
 	}
+	e.errors = errs
 	return e
 }
 
@@ -3,6 +3,7 @@ package shogoa
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"testing"
@@ -447,3 +448,23 @@ func TestMergeErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestErrorCode(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		code string
+	}{
+		"error response":  {ErrNotFound("no bottle"), "not_found"},
+		"wrapped error":   {fmt.Errorf("show: %w", ErrBadRequest("invalid")), "bad_request"},
+		"problem details": {NewProblemDetails(ErrNotFound("no bottle")), "not_found"},
+		"other error":     {errors.New("boom"), ""},
+		"nil":             {nil, ""},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := ErrorCode(c.err); got != c.code {
+				t.Errorf("got %q, want %q", got, c.code)
+			}
+		})
+	}
+}
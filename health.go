@@ -0,0 +1,193 @@
+package shogoa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthMediaIdentifier is the media type identifier used for health check responses.
+const HealthMediaIdentifier = "application/vnd.shogoa.health"
+
+// List of the health check statuses.
+const (
+	// HealthPass is the status of successful health checks.
+	HealthPass = "pass"
+	// HealthFail is the status of failed health checks.
+	HealthFail = "fail"
+)
+
+// DefaultHealthCheckTimeout is the timeout of health checks registered with a timeout of 0.
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+type (
+	// HealthChecker checks the health of a dependency of the service such as a database. It returns
+	// a non-nil error if the dependency is unhealthy.
+	HealthChecker func(context.Context) error
+
+	// Health is the set of health checks exposed by the liveness and readiness endpoints mounted
+	// with ServeHealth.
+	Health struct {
+		service *Service
+
+		mu        sync.RWMutex
+		liveness  []*healthCheck
+		readiness []*healthCheck
+	}
+
+	// HealthResponse is the health check response media type. It aggregates the results of the
+	// individual health checks.
+	HealthResponse struct {
+		// Status is HealthPass if all the checks passed, HealthFail otherwise.
+		Status string `json:"status" yaml:"status" xml:"status" form:"status"`
+		// Checks contains the result of each check indexed by check name.
+		Checks map[string]*HealthCheckResult `json:"checks,omitempty" yaml:"checks,omitempty" xml:"-" form:"checks,omitempty"`
+	}
+
+	// HealthCheckResult is the result of a single health check.
+	HealthCheckResult struct {
+		// Status is HealthPass if the check succeeded, HealthFail otherwise.
+		Status string `json:"status" yaml:"status" xml:"status" form:"status"`
+		// Error is the error returned by the check if any.
+		Error string `json:"error,omitempty" yaml:"error,omitempty" xml:"error,omitempty" form:"error,omitempty"`
+		// Duration is the time it took to run the check.
+		Duration string `json:"duration" yaml:"duration" xml:"duration" form:"duration"`
+	}
+
+	// healthCheck is a named health check.
+	healthCheck struct {
+		name    string
+		timeout time.Duration
+		check   HealthChecker
+	}
+)
+
+// ServeHealth creates a "Health" controller and calls ServeHealth on it.
+func (service *Service) ServeHealth(livenessPath, readinessPath string) (*Health, error) {
+	ctrl := service.NewController("Health")
+	return ctrl.ServeHealth(livenessPath, readinessPath)
+}
+
+// ServeHealth mounts the liveness and readiness endpoints on the given paths, for example
+// "/healthz" and "/readyz". The endpoints run the checks registered on the returned Health
+// concurrently and respond with a HealthResponse: status 200 if all checks pass, 503 otherwise.
+// The readiness endpoint fails as soon as the service graceful shutdown begins so that load
+// balancers stop routing requests to the service, set Service.ShutdownDelay to give them time to
+// notice before the service stops accepting connections, see Service.Run.
+func (ctrl *Controller) ServeHealth(livenessPath, readinessPath string) (*Health, error) {
+	if strings.ContainsAny(livenessPath+readinessPath, ":*") {
+		return nil, fmt.Errorf("health check paths may not include wildcards")
+	}
+	h := &Health{service: ctrl.Service}
+	ctx := ctrl.Service.Context
+	LogInfo(ctx, "mount health", "kind", "liveness", "route", fmt.Sprintf("GET %s", livenessPath))
+	ctrl.Service.Mux.Handle("GET", livenessPath, ctrl.MuxHandler("liveness", h.handler(false), nil))
+	LogInfo(ctx, "mount health", "kind", "readiness", "route", fmt.Sprintf("GET %s", readinessPath))
+	ctrl.Service.Mux.Handle("GET", readinessPath, ctrl.MuxHandler("readiness", h.handler(true), nil))
+	return h, nil
+}
+
+// AddLivenessCheck registers a check run by the liveness endpoint. Liveness checks should only
+// fail if the service cannot recover without being restarted. The check is considered failed if it
+// does not complete within timeout, DefaultHealthCheckTimeout is used if timeout is 0.
+func (h *Health) AddLivenessCheck(name string, timeout time.Duration, check HealthChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = append(h.liveness, &healthCheck{name: name, timeout: timeout, check: check})
+}
+
+// AddReadinessCheck registers a check run by the readiness endpoint. Readiness checks fail when
+// the service cannot serve requests, for example because a database is unreachable. The check is
+// considered failed if it does not complete within timeout, DefaultHealthCheckTimeout is used if
+// timeout is 0.
+func (h *Health) AddReadinessCheck(name string, timeout time.Duration, check HealthChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = append(h.readiness, &healthCheck{name: name, timeout: timeout, check: check})
+}
+
+// Check runs the liveness or readiness checks concurrently and returns the aggregated result.
+func (h *Health) Check(ctx context.Context, readiness bool) *HealthResponse {
+	h.mu.RLock()
+	checks := h.liveness
+	if readiness {
+		checks = h.readiness
+	}
+	checks = checks[:len(checks):len(checks)]
+	h.mu.RUnlock()
+
+	res := &HealthResponse{Status: HealthPass, Checks: make(map[string]*HealthCheckResult, len(checks))}
+	results := make([]*HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}()
+	}
+	wg.Wait()
+	for i, c := range checks {
+		res.Checks[c.name] = results[i]
+		if results[i].Status != HealthPass {
+			res.Status = HealthFail
+		}
+	}
+	if readiness && h.service.ShuttingDown() {
+		res.Status = HealthFail
+		res.Checks["shutdown"] = &HealthCheckResult{Status: HealthFail, Error: "service is shutting down", Duration: "0s"}
+	}
+	return res
+}
+
+// handler returns the liveness or readiness endpoint handler.
+func (h *Health) handler(readiness bool) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		res := h.Check(ctx, readiness)
+		status := http.StatusOK
+		if res.Status != HealthPass {
+			status = http.StatusServiceUnavailable
+		}
+		rw.Header().Set("Cache-Control", "no-store")
+		if rw.Header().Get("Content-Type") == "" {
+			rw.Header().Set("Content-Type", HealthMediaIdentifier)
+		}
+		return h.service.Send(ctx, status, res)
+	}
+}
+
+// run runs the check, it gives up waiting for the check once the timeout elapses.
+func (c *healthCheck) run(ctx context.Context) *HealthCheckResult {
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errc <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errc <- c.check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", timeout)
+	}
+	res := &HealthCheckResult{Status: HealthPass, Duration: time.Since(start).String()}
+	if err != nil {
+		res.Status = HealthFail
+		res.Error = err.Error()
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+package shogoa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestService_ServeHealth(t *testing.T) {
+	service := New("test")
+	service.Encoder.Register(NewJSONEncoder, "*/*")
+	health, err := service.ServeHealth("/healthz", "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(t *testing.T, path string) (int, *HealthResponse) {
+		t.Helper()
+		rw := httptest.NewRecorder()
+		service.Mux.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		if got := rw.Header().Get("Content-Type"); got != HealthMediaIdentifier {
+			t.Errorf("unexpected Content-Type: %q", got)
+		}
+		var res HealthResponse
+		if err := json.Unmarshal(rw.Body.Bytes(), &res); err != nil {
+			t.Fatalf("invalid response body %q: %s", rw.Body.String(), err)
+		}
+		return rw.Code, &res
+	}
+
+	t.Run("no checks", func(t *testing.T) {
+		for _, path := range []string{"/healthz", "/readyz"} {
+			code, res := get(t, path)
+			if code != 200 || res.Status != HealthPass {
+				t.Errorf("%s: unexpected response: %d %+v", path, code, res)
+			}
+		}
+	})
+
+	health.AddLivenessCheck("loop", 0, func(context.Context) error { return nil })
+	health.AddReadinessCheck("db", time.Second, func(context.Context) error { return nil })
+
+	t.Run("passing checks", func(t *testing.T) {
+		code, res := get(t, "/readyz")
+		if code != 200 || res.Status != HealthPass {
+			t.Fatalf("unexpected response: %d %+v", code, res)
+		}
+		if c := res.Checks["db"]; c == nil || c.Status != HealthPass {
+			t.Errorf("unexpected db check result: %+v", c)
+		}
+		if _, ok := res.Checks["loop"]; ok {
+			t.Error("liveness check run by readiness endpoint")
+		}
+	})
+
+	health.AddReadinessCheck("cache", time.Second, func(context.Context) error { return errors.New("unreachable") })
+	health.AddReadinessCheck("queue", 10*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	t.Run("failing checks", func(t *testing.T) {
+		start := time.Now()
+		code, res := get(t, "/readyz")
+		if d := time.Since(start); d > 500*time.Millisecond {
+			t.Errorf("checks did not time out: %s", d)
+		}
+		if code != 503 || res.Status != HealthFail {
+			t.Fatalf("unexpected response: %d %+v", code, res)
+		}
+		if c := res.Checks["cache"]; c == nil || c.Error != "unreachable" {
+			t.Errorf("unexpected cache check result: %+v", c)
+		}
+		if c := res.Checks["queue"]; c == nil || c.Status != HealthFail {
+			t.Errorf("unexpected queue check result: %+v", c)
+		}
+		if code, _ := get(t, "/healthz"); code != 200 {
+			t.Errorf("unexpected liveness status: %d", code)
+		}
+	})
+
+	t.Run("shutting down", func(t *testing.T) {
+		service := New("test")
+		service.Encoder.Register(NewJSONEncoder, "*/*")
+		if _, err := service.ServeHealth("/healthz", "/readyz"); err != nil {
+			t.Fatal(err)
+		}
+		service.shuttingDown.Store(true)
+		rw := httptest.NewRecorder()
+		service.Mux.ServeHTTP(rw, httptest.NewRequest("GET", "/readyz", nil))
+		if rw.Code != 503 {
+			t.Errorf("unexpected readiness status: %d", rw.Code)
+		}
+		rw = httptest.NewRecorder()
+		service.Mux.ServeHTTP(rw, httptest.NewRequest("GET", "/healthz", nil))
+		if rw.Code != 200 {
+			t.Errorf("unexpected liveness status: %d", rw.Code)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package shogoa
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// IdempotencyMetadata is the name of the design metadata that opts actions, or all the actions of
+// a resource, in idempotency key support, e.g.:
+//
+//	Metadata("idempotent")
+//
+// The generated code wraps the handlers of the actions with Service.HandleIdempotency.
+const IdempotencyMetadata = "idempotent"
+
+// UseIdempotency mounts the middleware that makes the actions declared idempotent in the design
+// safe to retry, see the idempotency middleware package. Requests are processed normally if no
+// middleware is mounted. The middleware must be mounted before the service handles requests.
+func (service *Service) UseIdempotency(m Middleware) {
+	service.idempotency = m
+}
+
+// HandleIdempotency creates a handler that runs the middleware mounted with UseIdempotency. It is
+// used by the generated code for the actions declared idempotent.
+func (service *Service) HandleIdempotency(h Handler) Handler {
+	// Use closure to enable late computation of the handler to ensure the middleware has been
+	// mounted.
+	var handler Handler
+	var initHandler sync.Once
+
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		initHandler.Do(func() {
+			handler = h
+			if service.idempotency != nil {
+				handler = service.idempotency(h)
+			}
+		})
+		return handler(ctx, rw, req)
+	}
+}
@@ -0,0 +1,57 @@
+package shogoa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleIdempotency(t *testing.T) {
+	var called bool
+	handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		called = true
+		return nil
+	}
+	serve := func(h Handler) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+		rw := httptest.NewRecorder()
+		if err := h(NewContext(rw, req, nil), rw, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("should pass through without idempotency middleware", func(t *testing.T) {
+		called = false
+		serve(New("test").HandleIdempotency(handler))
+		if !called {
+			t.Error("handler not called")
+		}
+	})
+
+	t.Run("should run the idempotency middleware", func(t *testing.T) {
+		called = false
+		var (
+			ran   bool
+			wraps int
+		)
+		service := New("test")
+		h := service.HandleIdempotency(handler)
+		service.UseIdempotency(func(h Handler) Handler {
+			wraps++
+			return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				ran = true
+				return h(ctx, rw, req)
+			}
+		})
+		serve(h)
+		serve(h)
+		if !ran || !called {
+			t.Errorf("unexpected calls: middleware %v, handler %v", ran, called)
+		}
+		if wraps != 1 {
+			t.Errorf("middleware wrapped the handler %d times, want 1", wraps)
+		}
+	})
+}
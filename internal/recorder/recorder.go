@@ -0,0 +1,58 @@
+// Package recorder provides the response writer used by the middlewares that store responses to
+// replay them later.
+package recorder
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Recorder is a response writer that records the response it writes.
+type Recorder struct {
+	http.ResponseWriter
+	// Code is the status code of the response, 0 until the headers are written.
+	Code int
+	// HeaderMap is a copy of the response headers taken when they are written.
+	HeaderMap http.Header
+	// Body is the response body, empty if truncated.
+	Body bytes.Buffer
+	// Truncated is true if the body is larger than the maximum size given to New.
+	Truncated bool
+
+	maxBodySize int
+}
+
+// New returns a recorder that writes to rw and records bodies up to maxBodySize bytes, 0 meaning
+// no limit.
+func New(rw http.ResponseWriter, maxBodySize int) *Recorder {
+	return &Recorder{ResponseWriter: rw, maxBodySize: maxBodySize}
+}
+
+// WriteHeader records the status code and the headers.
+func (r *Recorder) WriteHeader(status int) {
+	if r.Code == 0 && status >= 200 {
+		r.Code = status
+		r.HeaderMap = r.ResponseWriter.Header().Clone()
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body.
+func (r *Recorder) Write(b []byte) (int, error) {
+	if r.Code == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	if r.maxBodySize > 0 && r.Body.Len()+len(b) > r.maxBodySize {
+		r.Truncated = true
+		r.Body.Reset()
+	}
+	if !r.Truncated {
+		r.Body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying response writer.
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,42 @@
+package recorder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("records the response", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		r := New(rw, 0)
+		r.Header().Set("Content-Type", "text/plain")
+		r.Write([]byte("hello"))
+		r.Header().Set("X-Late", "ignored")
+		if r.Code != http.StatusOK || r.HeaderMap.Get("Content-Type") != "text/plain" || r.HeaderMap.Get("X-Late") != "" {
+			t.Errorf("unexpected recorded response %d %v", r.Code, r.HeaderMap)
+		}
+		if r.Body.String() != "hello" || rw.Body.String() != "hello" {
+			t.Errorf("unexpected bodies %q, %q", r.Body.String(), rw.Body.String())
+		}
+	})
+
+	t.Run("ignores informational responses", func(t *testing.T) {
+		r := New(httptest.NewRecorder(), 0)
+		r.WriteHeader(http.StatusEarlyHints)
+		r.WriteHeader(http.StatusCreated)
+		if r.Code != http.StatusCreated {
+			t.Errorf("unexpected status %d", r.Code)
+		}
+	})
+
+	t.Run("truncates large bodies", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		r := New(rw, 4)
+		r.Write([]byte("abc"))
+		r.Write([]byte("def"))
+		if !r.Truncated || r.Body.Len() != 0 || rw.Body.String() != "abcdef" {
+			t.Errorf("unexpected truncation %v %q %q", r.Truncated, r.Body.String(), rw.Body.String())
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package shogoa
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout is the default value of the service ShutdownTimeout field.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ShutdownSignals lists the signals that trigger the graceful shutdown of services started with Run.
+var ShutdownSignals = []os.Signal{
+	os.Interrupt,
+	syscall.SIGTERM,
+}
+
+// Hook is a function run by Service.Run when the service starts or shuts down, see
+// Service.OnStart and Service.OnShutdown.
+type Hook func(context.Context) error
+
+// OnStart registers a hook that Run calls before serving requests, for example to open database
+// pools or start message consumers. Hooks are run in registration order, if one fails Run runs the
+// OnShutdown hooks registered before the failing hook to release what the previous hooks acquired
+// and returns the error without serving any request.
+func (service *Service) OnStart(h Hook) {
+	service.onStart = append(service.onStart, h)
+	service.startedHooks = append(service.startedHooks, len(service.onShutdown))
+}
+
+// OnShutdown registers a hook that Run calls once in-flight requests completed during graceful
+// shutdown, for example to close database pools or stop message consumers. Hooks are run in
+// reverse registration order, all hooks run even if some fail. Register the shutdown hook of a
+// component right after its start hook: if a start hook fails only the shutdown hooks registered
+// before it run, e.g.:
+//
+//	service.OnStart(db.Open)
+//	service.OnShutdown(db.Close)
+//	service.OnStart(consumer.Start) // db.Close runs if consumer.Start fails
+//	service.OnShutdown(consumer.Stop)
+func (service *Service) OnShutdown(h Hook) {
+	service.onShutdown = append(service.onShutdown, h)
+}
+
+// ShuttingDown returns true once the graceful shutdown of the service has begun.
+func (service *Service) ShuttingDown() bool {
+	return service.shuttingDown.Load()
+}
+
+// Run runs the service until ctx is cancelled or the process receives one of ShutdownSignals.
+// Run calls the OnStart hooks then serves requests on all the given listeners - or on a listener
+// created for the service server Addr if there are none. On shutdown Run first keeps serving
+// requests for ShutdownDelay while the readiness endpoint fails, then stops accepting new
+// requests and waits for in-flight requests to complete for at most ShutdownTimeout. Requests still
+// running after that are cancelled (see CancelAll) and their connections are closed. Run then calls
+// the OnShutdown hooks with a context that expires after ShutdownTimeout. Each phase is logged
+// with the service logger.
+//
+// Run returns nil if the service was shut down gracefully, the error that caused the service to
+// stop otherwise.
+func (service *Service) Run(ctx context.Context, listeners ...net.Listener) error {
+	// Unlike the Catch helper used by shogoagen, NotifyContext stops relaying the signals once Run
+	// returns and does not leave a goroutine blocked waiting for a signal that never comes.
+	ctx, stop := signal.NotifyContext(ctx, ShutdownSignals...)
+	defer stop()
+
+	if len(listeners) == 0 {
+		addr := service.Server.Addr
+		if addr == "" {
+			addr = ":http"
+		}
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return err
+		}
+		listeners = []net.Listener{l}
+	}
+
+	service.LogInfo("starting", "hooks", len(service.onStart))
+	for i, h := range service.onStart {
+		if err := h(ctx); err != nil {
+			service.LogError("start hook failed", "hook", i, "error", err)
+			for _, l := range listeners {
+				l.Close()
+			}
+			service.runShutdownHooks(service.startedHooks[i])
+			return err
+		}
+	}
+
+	errc := make(chan error, len(listeners))
+	for _, l := range listeners {
+		service.LogInfo("listen", "transport", "http", "addr", l.Addr().String())
+		go func(l net.Listener) {
+			errc <- service.Server.Serve(l)
+		}(l)
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		service.LogInfo("shutdown requested", "cause", context.Cause(ctx))
+	case err = <-errc:
+		service.LogError("server failed", "error", err)
+	}
+	// Restore the default behavior so that a second signal terminates the process.
+	stop()
+
+	if serr := service.shutdown(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// shutdown drains the in-flight requests and runs the shutdown hooks.
+func (service *Service) shutdown() error {
+	service.shuttingDown.Store(true)
+	if service.ShutdownDelay > 0 {
+		service.LogInfo("delaying shutdown", "delay", service.ShutdownDelay.String())
+		time.Sleep(service.ShutdownDelay)
+	}
+	timeout := service.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	service.LogInfo("draining", "timeout", timeout.String())
+	drainCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	err := service.Server.Shutdown(drainCtx)
+	cancel()
+	if err != nil {
+		service.LogError("drain incomplete, closing connections", "error", err)
+		service.CancelAll()
+		service.Server.Close()
+	}
+
+	err = service.runShutdownHooks(len(service.onShutdown))
+	service.CancelAll()
+	service.LogInfo("stopped")
+	return err
+}
+
+// runShutdownHooks runs the first n OnShutdown hooks in reverse registration order with a context
+// that expires after ShutdownTimeout.
+func (service *Service) runShutdownHooks(n int) error {
+	timeout := service.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	service.LogInfo("running shutdown hooks", "hooks", n)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var errs []error
+	for i := n - 1; i >= 0; i-- {
+		if err := service.onShutdown[i](ctx); err != nil {
+			service.LogError("shutdown hook failed", "hook", i, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
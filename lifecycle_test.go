@@ -0,0 +1,199 @@
+package shogoa
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestService_Run(t *testing.T) {
+	listen := func(t *testing.T) net.Listener {
+		t.Helper()
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+
+	t.Run("graceful shutdown", func(t *testing.T) {
+		service := New("test")
+		var calls []string
+		hook := func(name string) Hook {
+			return func(context.Context) error {
+				calls = append(calls, name)
+				return nil
+			}
+		}
+		service.OnStart(hook("start1"))
+		service.OnStart(hook("start2"))
+		service.OnShutdown(hook("shutdown1"))
+		service.OnShutdown(hook("shutdown2"))
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		service.Mux.Handle("GET", "/", func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+			close(started)
+			<-release
+			if !service.ShuttingDown() {
+				t.Error("ShuttingDown() = false during shutdown")
+			}
+			io.WriteString(rw, "done")
+		})
+
+		l1, l2 := listen(t), listen(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error, 1)
+		go func() { runErr <- service.Run(ctx, l1, l2) }()
+
+		resp, err := http.Get("http://" + l2.Addr().String() + "/unknown")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		body := make(chan string, 1)
+		go func() {
+			resp, err := http.Get("http://" + l1.Addr().String() + "/")
+			if err != nil {
+				body <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			body <- string(b)
+		}()
+		<-started
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+
+		if got := <-body; got != "done" {
+			t.Errorf("in-flight request was not completed: %q", got)
+		}
+		if err := <-runErr; err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"start1", "start2", "shutdown2", "shutdown1"}; !slices.Equal(calls, want) {
+			t.Errorf("unexpected hook calls: want %v, got %v", want, calls)
+		}
+		if service.Context.Err() == nil {
+			t.Error("service context was not cancelled")
+		}
+	})
+
+	t.Run("shutdown delay", func(t *testing.T) {
+		service := New("test")
+		service.ShutdownDelay = 200 * time.Millisecond
+		service.Encoder.Register(NewJSONEncoder, "*/*")
+		if _, err := service.ServeHealth("/healthz", "/readyz"); err != nil {
+			t.Fatal(err)
+		}
+
+		l := listen(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error, 1)
+		go func() { runErr <- service.Run(ctx, l) }()
+		ready := func() int {
+			resp, err := http.Get("http://" + l.Addr().String() + "/readyz")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		if code := ready(); code != http.StatusOK {
+			t.Fatalf("unexpected readiness status %d before shutdown", code)
+		}
+		cancel()
+		for !service.ShuttingDown() {
+			time.Sleep(time.Millisecond)
+		}
+		if code := ready(); code != http.StatusServiceUnavailable {
+			t.Errorf("unexpected readiness status %d during the shutdown delay", code)
+		}
+		if err := <-runErr; err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("drain timeout", func(t *testing.T) {
+		service := New("test")
+		service.ShutdownTimeout = 50 * time.Millisecond
+		started := make(chan struct{})
+		cancelled := make(chan struct{})
+		service.Mux.Handle("GET", "/", func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+			close(started)
+			<-req.Context().Done()
+			close(cancelled)
+		})
+
+		l := listen(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error, 1)
+		go func() { runErr <- service.Run(ctx, l) }()
+		go http.Get("http://" + l.Addr().String() + "/")
+		<-started
+		cancel()
+
+		select {
+		case err := <-runErr:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after the shutdown timeout")
+		}
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("in-flight request was not cancelled")
+		}
+	})
+
+	t.Run("failing hooks", func(t *testing.T) {
+		errStart := errors.New("start")
+		service := New("test")
+		var calls []string
+		hook := func(name string, err error) Hook {
+			return func(context.Context) error {
+				calls = append(calls, name)
+				return err
+			}
+		}
+		service.OnStart(hook("start1", nil))
+		service.OnShutdown(hook("shutdown1", nil))
+		service.OnStart(hook("start2", nil))
+		service.OnShutdown(hook("shutdown2", nil))
+		service.OnStart(hook("start3", errStart))
+		service.OnShutdown(hook("shutdown3", nil))
+		service.OnStart(hook("start4", nil))
+		service.OnShutdown(hook("shutdown4", nil))
+		if err := service.Run(context.Background(), listen(t)); err != errStart {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if want := []string{"start1", "start2", "start3", "shutdown2", "shutdown1"}; !slices.Equal(calls, want) {
+			t.Errorf("unexpected hook calls after the start failure: want %v, got %v", want, calls)
+		}
+
+		errShutdown := errors.New("shutdown")
+		service = New("test")
+		called := false
+		service.OnShutdown(func(context.Context) error { called = true; return nil })
+		service.OnShutdown(func(context.Context) error { return errShutdown })
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := service.Run(ctx, listen(t)); !errors.Is(err, errShutdown) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !called {
+			t.Error("shutdown hooks did not all run")
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+	"github.com/shogo82148/shogoa/internal/recorder"
+)
+
+// maxBodySize is the size of the largest response body that is cached.
+const maxBodySize = 10 << 20
+
+// timeNow is the clock used by the middleware and the memory store, tests override it.
+var timeNow = time.Now
+
+// Option allows to override default parameters.
+type Option func(*options)
+
+// options contains final options
+type options struct {
+	headers []string
+	params  []string
+}
+
+// Credentials declares the security schemes of the design whose credentials are recognized by the
+// middleware in addition to the Authorization and Cookie headers. The schemes are the definitions
+// created by the generated NewXXSecurity functions: the header or query string parameter of the
+// *shogoa.APIKeySecurity and *shogoa.JWTSecurity schemes is read, the other schemes use the
+// Authorization header.
+func Credentials(schemes ...any) Option {
+	return func(o *options) {
+		for _, scheme := range schemes {
+			var in shogoa.Location
+			var name string
+			switch s := scheme.(type) {
+			case *shogoa.APIKeySecurity:
+				in, name = s.In, s.Name
+			case *shogoa.JWTSecurity:
+				in, name = s.In, s.Name
+			case *shogoa.OAuth2Security, *shogoa.BasicAuthSecurity:
+				continue
+			default:
+				panic(fmt.Sprintf("cache: unsupported security scheme %T", scheme))
+			}
+			if in == shogoa.LocQuery {
+				o.params = append(o.params, name)
+			} else {
+				o.headers = append(o.headers, name)
+			}
+		}
+	}
+}
+
+// New returns a middleware that caches the encoded responses of GET requests in the given store.
+//
+// The responses are cached according to their Cache-Control header, which the generated code sets
+// from the policy declared with the CacheControl DSL in the design: only 200 responses with a
+// max-age or s-maxage directive are cached, for the duration given by s-maxage or max-age, and
+// responses with the private, no-cache or no-store directives or with a Set-Cookie header are not
+// cached.
+//
+// The middleware runs before the security middlewares of the actions, so the requests that carry
+// credentials are neither served from nor stored in the cache: requests with an Authorization or
+// Cookie header, or with the header or query string parameter of a scheme given with the
+// Credentials option, always reach the action. Services whose API key or JWT schemes are read
+// from other locations must declare them with Credentials:
+//
+//	service.Use(cache.New(store, cache.Credentials(app.NewAPIKeySecurity())))
+//
+// Cached responses are keyed by path, query string and content type selected by the service
+// encoder for the request Accept header, as well as by the values of the request headers listed in
+// the Vary response header. The cache is bypassed for the requests with the no-cache or no-store
+// Cache-Control directive and for conditional requests. Cached responses are served with the Age
+// header set.
+//
+// The middleware is mounted on the service or on the controllers:
+//
+//	service.Use(cache.New(cache.NewMemoryStore(1000)))
+func New(store Store, o ...Option) shogoa.Middleware {
+	var opts options
+	for _, opt := range o {
+		opt(&opts)
+	}
+
+	return func(h shogoa.Handler) shogoa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if req.Method != http.MethodGet || opts.credentials(req) {
+				return h(ctx, rw, req)
+			}
+			resp := shogoa.ContextResponse(ctx)
+			if resp == nil || resp.Service == nil {
+				return h(ctx, rw, req)
+			}
+			contentType, err := resp.Service.Encoder.ContentType(req.Header.Get("Accept"))
+			if err != nil {
+				// Let the action render the error.
+				return h(ctx, rw, req)
+			}
+			key := req.URL.Path + "?" + req.URL.RawQuery + "|" + contentType
+
+			directives := parseCacheControl(req.Header.Get("Cache-Control"))
+			_, noCache := directives["no-cache"]
+			_, noStore := directives["no-store"]
+			conditional := req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+			if !noCache && !noStore && !conditional {
+				e, err := lookup(ctx, store, key, req)
+				if err != nil {
+					shogoa.LogError(ctx, "failed to read cached response", "err", err)
+				} else if e != nil {
+					return serve(rw, e)
+				}
+			}
+
+			rrw := recorder.New(resp.SwitchWriter(nil), maxBodySize)
+			resp.SwitchWriter(rrw)
+			err = h(ctx, rw, req)
+			resp.SwitchWriter(rrw.ResponseWriter)
+			if err != nil || noStore {
+				return err
+			}
+			if err := save(ctx, store, key, req, rrw); err != nil {
+				shogoa.LogError(ctx, "failed to cache response", "err", err)
+			}
+			return nil
+		}
+	}
+}
+
+// credentials reports whether req carries credentials.
+func (o *options) credentials(req *http.Request) bool {
+	if req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "" {
+		return true
+	}
+	for _, name := range o.headers {
+		if req.Header.Get(name) != "" {
+			return true
+		}
+	}
+	if len(o.params) > 0 {
+		query := req.URL.Query()
+		for _, name := range o.params {
+			if query.Get(name) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookup returns the cached response to req if any.
+func lookup(ctx context.Context, store Store, key string, req *http.Request) (*Entry, error) {
+	e, err := store.Get(ctx, key)
+	if err != nil || e == nil {
+		return nil, err
+	}
+	if len(e.Vary) > 0 {
+		e, err = store.Get(ctx, varyKey(key, e.Vary, req))
+		if err != nil || e == nil {
+			return nil, err
+		}
+	}
+	if !timeNow().Before(e.Expires) {
+		return nil, nil
+	}
+	return e, nil
+}
+
+// save caches the response recorded by rrw if its Cache-Control header allows it.
+func save(ctx context.Context, store Store, key string, req *http.Request, rrw *recorder.Recorder) error {
+	if rrw.Code != http.StatusOK || rrw.Truncated || rrw.HeaderMap.Get("Set-Cookie") != "" {
+		return nil
+	}
+	directives := parseCacheControl(rrw.HeaderMap.Get("Cache-Control"))
+	for _, d := range []string{"private", "no-cache", "no-store"} {
+		if _, ok := directives[d]; ok {
+			return nil
+		}
+	}
+	maxAge, shared := directives["s-maxage"]
+	if !shared {
+		maxAge = directives["max-age"]
+	}
+	ttl, err := strconv.Atoi(maxAge)
+	if err != nil || ttl <= 0 {
+		return nil
+	}
+
+	var vary []string
+	for _, v := range rrw.HeaderMap.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+			switch name {
+			case "*":
+				return nil
+			case "", "Accept":
+				// The key already contains the content type selected for the Accept header.
+			default:
+				vary = append(vary, name)
+			}
+		}
+	}
+	slices.Sort(vary)
+	vary = slices.Compact(vary)
+
+	now := timeNow()
+	expires := now.Add(time.Duration(ttl) * time.Second)
+	e := &Entry{
+		Status:  rrw.Code,
+		Header:  rrw.HeaderMap,
+		Body:    rrw.Body.Bytes(),
+		Created: now,
+		Expires: expires,
+	}
+	if len(vary) == 0 {
+		return store.Set(ctx, key, e)
+	}
+	if err := store.Set(ctx, key, &Entry{Vary: vary, Created: now, Expires: expires}); err != nil {
+		return err
+	}
+	return store.Set(ctx, varyKey(key, vary, req), e)
+}
+
+// serve writes the cached response.
+func serve(rw http.ResponseWriter, e *Entry) error {
+	header := rw.Header()
+	for k, v := range e.Header {
+		header[k] = v
+	}
+	header.Set("Age", strconv.Itoa(int(timeNow().Sub(e.Created).Seconds())))
+	rw.WriteHeader(e.Status)
+	_, err := rw.Write(e.Body)
+	return err
+}
+
+// varyKey computes the key of the variant of a response that varies on the given headers.
+func varyKey(key string, vary []string, req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range vary {
+		b.WriteString("|")
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(strings.Join(req.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// parseCacheControl parses the directives of a Cache-Control header value.
+func parseCacheControl(v string) map[string]string {
+	directives := make(map[string]string)
+	for _, d := range strings.Split(v, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if name == "" {
+			continue
+		}
+		directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+	return directives
+}
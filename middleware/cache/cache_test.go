@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+)
+
+func TestMiddleware(t *testing.T) {
+	now := time.Unix(1000, 0)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	service := shogoa.New("test")
+	service.Encoder.Register(shogoa.NewJSONEncoder, "*/*", "application/json")
+	service.Encoder.Register(shogoa.NewXMLEncoder, "application/xml")
+
+	var (
+		calls        int
+		cacheControl string
+		header       http.Header
+	)
+	handler := New(NewMemoryStore(10))(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		calls++
+		rw.Header().Set("Cache-Control", cacheControl)
+		for k, v := range header {
+			rw.Header()[k] = v
+		}
+		return service.Send(ctx, http.StatusOK, fmt.Sprintf("response %d", calls))
+	})
+	get := func(path string, h http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range h {
+			req.Header[k] = v
+		}
+		rw := httptest.NewRecorder()
+		ctx := shogoa.NewContext(rw, req, nil)
+		shogoa.ContextResponse(ctx).Service = service
+		if err := handler(ctx, shogoa.ContextResponse(ctx), req); err != nil {
+			t.Fatal(err)
+		}
+		return rw
+	}
+	reset := func(cc string) {
+		calls = 0
+		cacheControl = cc
+		header = nil
+	}
+
+	t.Run("caches the responses", func(t *testing.T) {
+		reset("public, max-age=60")
+		first := get("/bottles?page=1", nil)
+		now = now.Add(10 * time.Second)
+		second := get("/bottles?page=1", nil)
+		if second.Body.String() != first.Body.String() || second.Header().Get("Age") != "10" {
+			t.Errorf("expected a cached response, got %q with Age %q", second.Body.String(), second.Header().Get("Age"))
+		}
+		get("/bottles?page=2", nil)
+		if calls != 2 {
+			t.Errorf("handler called %d times, want 2", calls)
+		}
+
+		now = now.Add(time.Minute)
+		if rw := get("/bottles?page=1", nil); rw.Header().Get("Age") != "" {
+			t.Error("expected the cached response to expire")
+		}
+	})
+
+	t.Run("keys by content type", func(t *testing.T) {
+		reset("max-age=60")
+		get("/bottles", http.Header{"Accept": {"application/json"}})
+		get("/bottles", http.Header{"Accept": {"application/xml"}})
+		get("/bottles", http.Header{"Accept": {"application/xml;q=0.9, text/html"}})
+		if calls != 2 {
+			t.Errorf("handler called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("keys by Vary headers", func(t *testing.T) {
+		reset("max-age=60")
+		header = http.Header{"Vary": {"Accept-Language, Accept"}}
+		for _, lang := range []string{"en", "ja", "en"} {
+			get("/wines", http.Header{"Accept-Language": {lang}})
+		}
+		if calls != 2 {
+			t.Errorf("handler called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("does not cache uncacheable responses", func(t *testing.T) {
+		for _, cc := range []string{"", "private, max-age=60", "no-store", "max-age=0"} {
+			reset(cc)
+			get("/spirits", nil)
+			get("/spirits", nil)
+			if calls != 2 {
+				t.Errorf("%q: handler called %d times, want 2", cc, calls)
+			}
+		}
+
+		reset("max-age=60")
+		get("/beers", http.Header{"Authorization": {"Bearer token"}})
+		get("/beers", http.Header{"Authorization": {"Bearer token"}})
+		if calls != 2 {
+			t.Errorf("authorized request: handler called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("honors the request directives", func(t *testing.T) {
+		reset("max-age=60")
+		get("/ciders", nil)
+		get("/ciders", http.Header{"Cache-Control": {"no-cache"}})
+		if rw := get("/ciders", nil); rw.Body.String() != `"response 2"`+"\n" {
+			t.Errorf("expected the response of the no-cache request to be cached, got %q", rw.Body.String())
+		}
+		get("/ciders", http.Header{"If-None-Match": {`"v1"`}})
+		if calls != 3 {
+			t.Errorf("handler called %d times, want 3", calls)
+		}
+	})
+}
+
+func TestCredentials(t *testing.T) {
+	service := shogoa.New("test")
+	service.Encoder.Register(shogoa.NewJSONEncoder, "*/*")
+
+	var calls int
+	secured := func(h shogoa.Handler) shogoa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if req.Header.Get("X-Api-Key") != "secret" && req.URL.Query().Get("key") != "secret" {
+				return shogoa.ErrUnauthorized("missing API key")
+			}
+			return h(ctx, rw, req)
+		}
+	}
+	m := New(NewMemoryStore(10), Credentials(
+		&shogoa.APIKeySecurity{In: shogoa.LocHeader, Name: "X-Api-Key"},
+		&shogoa.APIKeySecurity{In: shogoa.LocQuery, Name: "key"},
+	))
+	handler := m(secured(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		calls++
+		rw.Header().Set("Cache-Control", "max-age=60")
+		return service.Send(ctx, http.StatusOK, "secret bottles")
+	}))
+	get := func(target string, h http.Header) error {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range h {
+			req.Header[k] = v
+		}
+		rw := httptest.NewRecorder()
+		ctx := shogoa.NewContext(rw, req, nil)
+		shogoa.ContextResponse(ctx).Service = service
+		return handler(ctx, shogoa.ContextResponse(ctx), req)
+	}
+
+	if err := get("/bottles", http.Header{"X-Api-Key": {"secret"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := get("/bottles", nil); err == nil {
+		t.Error("expected the request without API key to be rejected")
+	}
+	if err := get("/bottles?key=secret", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := get("/bottles?key=other", nil); err == nil {
+		t.Error("expected the request with an invalid API key to be rejected")
+	}
+	if err := get("/bottles", http.Header{"Cookie": {"session=secret"}}); err == nil {
+		t.Error("expected the request with a cookie to reach the action")
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+	store := NewMemoryStore(2)
+	ctx := context.Background()
+	set := func(key string, ttl time.Duration) {
+		if err := store.Set(ctx, key, &Entry{Body: []byte(key), Expires: now.Add(ttl)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(key string) *Entry {
+		e, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	set("a", time.Minute)
+	set("b", time.Minute)
+	get("a")
+	set("c", time.Minute)
+	if get("b") != nil {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if get("a") == nil || get("c") == nil {
+		t.Error("expected the recently used entries to be kept")
+	}
+
+	set("d", time.Second)
+	now = now.Add(time.Second)
+	if get("d") != nil {
+		t.Error("expected the entry to expire")
+	}
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	// Status is the status code of the response.
+	Status int
+	// Header contains the headers of the response.
+	Header http.Header
+	// Body is the encoded body of the response.
+	Body []byte
+	// Vary lists the request headers the response varies on. Entries with Vary set do not hold
+	// a response but tell how to compute the keys of the variants of the response.
+	Vary []string
+	// Created is the time the response was cached.
+	Created time.Time
+	// Expires is the time the response becomes stale.
+	Expires time.Time
+}
+
+// Store is the interface implemented by the response stores.
+type Store interface {
+	// Get returns the entry of key, or nil if key is unknown or the entry expired.
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Set saves the entry of key, the store may discard the entry at any time.
+	Set(ctx context.Context, key string, e *Entry) error
+}
+
+// MemoryStore is a Store that keeps the entries in memory and evicts the least recently used
+// entries when full.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	lru        *list.List // of *memoryEntry, most recently used first
+	entries    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key   string
+	entry *Entry
+}
+
+// NewMemoryStore returns an in-memory store holding at most maxEntries entries.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry of key.
+func (s *MemoryStore) Get(_ context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	e := el.Value.(*memoryEntry).entry
+	if !timeNow().Before(e.Expires) {
+		s.lru.Remove(el)
+		delete(s.entries, key)
+		return nil, nil
+	}
+	s.lru.MoveToFront(el)
+	return e, nil
+}
+
+// Set saves the entry of key and evicts the least recently used entry if the store is full.
+func (s *MemoryStore) Set(_ context.Context, key string, e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		el.Value.(*memoryEntry).entry = e
+		s.lru.MoveToFront(el)
+		return nil
+	}
+	s.entries[key] = s.lru.PushFront(&memoryEntry{key: key, entry: e})
+	for s.lru.Len() > s.maxEntries {
+		el := s.lru.Back()
+		s.lru.Remove(el)
+		delete(s.entries, el.Value.(*memoryEntry).key)
+	}
+	return nil
+}
@@ -14,6 +14,8 @@ import (
 // them, it turns other Go error types into a 500 internal error response.
 // If verbose is false the details of internal errors is not included in HTTP responses.
 // If you use github.com/pkg/errors then wrapping the error will allow a trace to be printed to the logs
+// Errors are rendered as RFC 9457 problem details instead if the service ProblemErrors field is set
+// or if the request Accept header lists application/problem+json, see shogoa.NewProblemDetails.
 func ErrorHandler(service *shogoa.Service, verbose bool) shogoa.Middleware {
 	return func(h shogoa.Handler) shogoa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -51,6 +53,15 @@ func ErrorHandler(service *shogoa.Service, verbose bool) shogoa.Middleware {
 					}
 				}
 			}
+			if service.ProblemErrors || shogoa.AcceptsProblemDetails(req.Header.Get("Accept")) {
+				var p *shogoa.ProblemDetails
+				if err, ok := respBody.(error); ok {
+					p = shogoa.NewProblemDetails(err)
+				} else {
+					p = shogoa.NewProblemDetails(e)
+				}
+				return service.SendProblem(ctx, status, p)
+			}
 			return service.Send(ctx, status, respBody)
 		}
 	}
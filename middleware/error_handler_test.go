@@ -242,4 +242,94 @@ func TestErrorHandler(t *testing.T) {
 			t.Errorf("unexpected status: %d", decoded.Status)
 		}
 	})
+
+	t.Run("problem details", func(t *testing.T) {
+		// build a service
+		service := shogoa.New("test")
+		service.Encoder.Register(shogoa.NewJSONEncoder, "*/*")
+		service.Decoder.Register(shogoa.NewJSONDecoder, "*/*")
+		service.ProblemErrors = true
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var err error
+			err = shogoa.MergeErrors(err, shogoa.MissingParamError("id"))
+			err = shogoa.MergeErrors(err, shogoa.InvalidParamTypeError("count", "abc", "integer"))
+			return err
+		}
+		errorHandler := ErrorHandler(service, true)(handler)
+
+		// run the handler
+		req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+		rw := httptest.NewRecorder()
+		ctx := shogoa.NewContext(rw, req, nil)
+		if err := errorHandler(ctx, rw, req); err != nil {
+			t.Fatal(err)
+		}
+
+		// check the result
+		result := rw.Result()
+		if result.StatusCode != http.StatusBadRequest {
+			t.Errorf("unexpected status code: %d", result.StatusCode)
+		}
+		if result.Header.Get("Content-Type") != shogoa.ProblemMediaIdentifier {
+			t.Errorf("unexpected content type: %s", result.Header.Get("Content-Type"))
+		}
+		var decoded shogoa.ProblemDetails
+		if err := service.Decoder.Decode(&decoded, result.Body, "application/json"); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Type != "urn:shogoa:error:invalid_request" {
+			t.Errorf("unexpected type: %s", decoded.Type)
+		}
+		if decoded.Status != http.StatusBadRequest {
+			t.Errorf("unexpected status: %d", decoded.Status)
+		}
+		if decoded.Extensions["param"] != "count" || decoded.Token() == "" {
+			t.Errorf("unexpected extensions: %v", decoded.Extensions)
+		}
+		if len(decoded.Errors) != 2 {
+			t.Fatalf("unexpected errors: %v", decoded.Errors)
+		}
+		if decoded.Errors[0].Extensions["name"] != "id" || decoded.Errors[1].Extensions["param"] != "count" {
+			t.Errorf("unexpected errors: %v, %v", decoded.Errors[0], decoded.Errors[1])
+		}
+	})
+
+	t.Run("problem details negotiated", func(t *testing.T) {
+		// build a service
+		service := shogoa.New("test")
+		service.Encoder.Register(shogoa.NewJSONEncoder, "*/*")
+		service.Decoder.Register(shogoa.NewJSONDecoder, "*/*")
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return errors.New("boom")
+		}
+		errorHandler := ErrorHandler(service, false)(handler)
+
+		// run the handler
+		req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+		req.Header.Set("Accept", "application/json, application/problem+json")
+		rw := httptest.NewRecorder()
+		ctx := shogoa.NewContext(rw, req, nil)
+		if err := errorHandler(ctx, rw, req); err != nil {
+			t.Fatal(err)
+		}
+
+		// check the result
+		result := rw.Result()
+		if result.StatusCode != http.StatusInternalServerError {
+			t.Errorf("unexpected status code: %d", result.StatusCode)
+		}
+		if result.Header.Get("Content-Type") != shogoa.ProblemMediaIdentifier {
+			t.Errorf("unexpected content type: %s", result.Header.Get("Content-Type"))
+		}
+		var decoded shogoa.ProblemDetails
+		if err := service.Decoder.Decode(&decoded, result.Body, "application/json"); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Type != "urn:shogoa:error:internal" {
+			t.Errorf("unexpected type: %s", decoded.Type)
+		}
+		if decoded.Detail == "boom" || decoded.Token() == "" {
+			t.Errorf("unexpected problem details: %+v", decoded)
+		}
+	})
 }
@@ -0,0 +1,203 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+	"github.com/shogo82148/shogoa/internal/recorder"
+	"github.com/shogo82148/shogoa/middleware/security/apikey"
+)
+
+const (
+	// HeaderKey is the name of the request header that carries the idempotency key.
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is the name of the response header set on replayed responses.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	// DefaultTTL is the default duration for which the responses are recorded.
+	DefaultTTL = 24 * time.Hour
+	// DefaultLockTimeout is the default duration after which a request that did not complete,
+	// e.g. because the service instance crashed, no longer blocks its retries.
+	DefaultLockTimeout = time.Minute
+
+	// maxKeyLength is the maximum length of the idempotency keys.
+	maxKeyLength = 255
+)
+
+// timeNow is the clock used by the memory store, tests override it.
+var timeNow = time.Now
+
+// PrincipalFunc returns the identifier of the principal that made a request, the idempotency keys
+// of different principals never collide. An empty identifier means that the principal is unknown.
+type PrincipalFunc func(ctx context.Context, req *http.Request) string
+
+// DefaultPrincipal is a PrincipalFunc that identifies the principal by the name resolved by the
+// apikey security middleware if any, or by a hash of the Authorization header otherwise. The
+// principal of anonymous requests is unknown.
+func DefaultPrincipal(ctx context.Context, req *http.Request) string {
+	if p := apikey.ContextPrincipal(ctx); p != nil {
+		return "principal:" + p.Name
+	}
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		return "authorization:" + hex.EncodeToString(sum[:])
+	}
+	return ""
+}
+
+// Option allows to override default parameters.
+type Option func(*options)
+
+// options contains final options
+type options struct {
+	principal   PrincipalFunc
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+// Principal sets the function that identifies the principals, DefaultPrincipal by default.
+func Principal(f PrincipalFunc) Option {
+	return func(o *options) {
+		o.principal = f
+	}
+}
+
+// TTL sets the duration for which the responses are recorded, DefaultTTL by default.
+func TTL(d time.Duration) Option {
+	return func(o *options) {
+		o.ttl = d
+	}
+}
+
+// LockTimeout sets the duration after which a request that did not complete no longer blocks its
+// retries, DefaultLockTimeout by default.
+func LockTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.lockTimeout = d
+	}
+}
+
+// New returns a middleware that makes requests carrying an Idempotency-Key header safe to retry.
+//
+// The middleware records the first response to a key under the key and the principal that made
+// the request, and replays it to the retries with the Idempotent-Replayed header set. Retries
+// made while the first request is processed fail with shogoa.ErrConflict errors, and requests that
+// reuse a key with a different method, URL or payload fail with shogoa.ErrUnprocessableEntity
+// errors. Requests that fail with an error or a 5xx response are not recorded so that they can be
+// retried. Requests without Idempotency-Key header are processed normally.
+//
+// Requests whose principal is unknown, e.g. anonymous requests with DefaultPrincipal, are also
+// processed normally: their keys would be shared by all the anonymous clients so that a client
+// could replay the response recorded for another. Use the Principal option with a function that
+// identifies the anonymous clients, e.g. by session, to make their requests safe to retry.
+//
+// The middleware applies to the actions declared idempotent in the design with the "idempotent"
+// metadata when mounted with the service UseIdempotency method:
+//
+//	service.UseIdempotency(idempotency.New(idempotency.NewMemoryStore()))
+func New(store Store, o ...Option) shogoa.Middleware {
+	opts := options{
+		principal:   DefaultPrincipal,
+		ttl:         DefaultTTL,
+		lockTimeout: DefaultLockTimeout,
+	}
+	for _, opt := range o {
+		opt(&opts)
+	}
+
+	return func(h shogoa.Handler) shogoa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key := req.Header.Get(HeaderKey)
+			if key == "" {
+				return h(ctx, rw, req)
+			}
+			if len(key) > maxKeyLength {
+				return shogoa.ErrBadRequest(fmt.Sprintf("%s header exceeds %d characters", HeaderKey, maxKeyLength))
+			}
+			principal := opts.principal(ctx, req)
+			resp := shogoa.ContextResponse(ctx)
+			if principal == "" || resp == nil {
+				return h(ctx, rw, req)
+			}
+			fp, err := fingerprint(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			key = principal + "|" + key
+			rec, err := store.Start(ctx, key, fp, opts.lockTimeout)
+			if err != nil {
+				return fmt.Errorf("idempotency key check failed: %w", err)
+			}
+			if rec != nil {
+				if rec.Fingerprint != fp {
+					return shogoa.ErrUnprocessableEntity("idempotency key reused with a different request", "key", req.Header.Get(HeaderKey))
+				}
+				if !rec.Done {
+					return shogoa.ErrConflict("a request with the same idempotency key is being processed", "key", req.Header.Get(HeaderKey))
+				}
+				return replay(rw, rec)
+			}
+
+			rec = &Record{Fingerprint: fp}
+			saved := false
+			rrw := recorder.New(resp.SwitchWriter(nil), 0)
+			resp.SwitchWriter(rrw)
+			defer func() {
+				resp.SwitchWriter(rrw.ResponseWriter)
+				if !saved {
+					if err := store.Delete(context.WithoutCancel(ctx), key); err != nil {
+						shogoa.LogError(ctx, "failed to release idempotency key", "err", err)
+					}
+				}
+			}()
+
+			if err := h(ctx, rw, req); err != nil {
+				return err
+			}
+			if rrw.Code == 0 || rrw.Code >= 500 {
+				return nil
+			}
+			rec.Done = true
+			rec.Status = rrw.Code
+			rec.Header = rrw.HeaderMap
+			rec.Body = rrw.Body.Bytes()
+			if err := store.Save(context.WithoutCancel(ctx), key, rec, opts.ttl); err != nil {
+				shogoa.LogError(ctx, "failed to record idempotent response", "err", err)
+				return nil
+			}
+			saved = true
+			return nil
+		}
+	}
+}
+
+// fingerprint computes a hash of the method, URL and decoded payload of the request.
+func fingerprint(ctx context.Context, req *http.Request) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL.RequestURI())
+	if r := shogoa.ContextRequest(ctx); r != nil && r.Payload != nil {
+		if err := json.NewEncoder(h).Encode(r.Payload); err != nil {
+			return "", fmt.Errorf("failed to hash request payload: %w", err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// replay writes the recorded response.
+func replay(rw http.ResponseWriter, rec *Record) error {
+	header := rw.Header()
+	for k, v := range rec.Header {
+		header[k] = v
+	}
+	header.Set(HeaderReplayed, "true")
+	rw.WriteHeader(rec.Status)
+	_, err := rw.Write(rec.Body)
+	return err
+}
@@ -0,0 +1,223 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+)
+
+type payload struct {
+	Name string `json:"name"`
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		calls  int
+		status int
+		fail   error
+	)
+	handler := New(NewMemoryStore())(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		calls++
+		if fail != nil {
+			return fail
+		}
+		rw.Header().Set("Location", "/orders/1")
+		rw.WriteHeader(status)
+		_, err := rw.Write([]byte(`{"id":1}`))
+		return err
+	})
+	serve := func(key, auth string, p *payload) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		if key != "" {
+			req.Header.Set(HeaderKey, key)
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rw := httptest.NewRecorder()
+		ctx := shogoa.NewContext(rw, req, nil)
+		if p != nil {
+			shogoa.ContextRequest(ctx).Payload = p
+		}
+		err := handler(ctx, shogoa.ContextResponse(ctx), req)
+		return rw, err
+	}
+	reset := func() {
+		calls = 0
+		status = http.StatusCreated
+		fail = nil
+	}
+
+	t.Run("replays the recorded response", func(t *testing.T) {
+		reset()
+		for i := range 2 {
+			rw, err := serve("key1", "Bearer alice", &payload{Name: "wine"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rw.Code != http.StatusCreated || rw.Body.String() != `{"id":1}` || rw.Header().Get("Location") != "/orders/1" {
+				t.Errorf("request %d: unexpected response %d %v %q", i, rw.Code, rw.Header(), rw.Body.String())
+			}
+			if replayed := rw.Header().Get(HeaderReplayed) == "true"; replayed != (i == 1) {
+				t.Errorf("request %d: unexpected %s header %q", i, HeaderReplayed, rw.Header().Get(HeaderReplayed))
+			}
+		}
+		if calls != 1 {
+			t.Errorf("handler called %d times, want 1", calls)
+		}
+	})
+
+	t.Run("rejects mismatched payloads", func(t *testing.T) {
+		reset()
+		if _, err := serve("key2", "Bearer alice", &payload{Name: "wine"}); err != nil {
+			t.Fatal(err)
+		}
+		_, err := serve("key2", "Bearer alice", &payload{Name: "beer"})
+		if shogoa.ErrorCode(err) != "unprocessable_entity" {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("separates principals", func(t *testing.T) {
+		reset()
+		for _, auth := range []string{"Bearer alice", "Bearer bob"} {
+			rw, err := serve("key3", auth, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rw.Header().Get(HeaderReplayed) != "" {
+				t.Errorf("%s: unexpected replay", auth)
+			}
+		}
+		if calls != 2 {
+			t.Errorf("handler called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("ignores anonymous requests", func(t *testing.T) {
+		reset()
+		if _, err := serve("key4", "", &payload{Name: "wine"}); err != nil {
+			t.Fatal(err)
+		}
+		rw, err := serve("key4", "", &payload{Name: "beer"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rw.Header().Get(HeaderReplayed) != "" {
+			t.Error("unexpected replay")
+		}
+		if calls != 2 {
+			t.Errorf("handler called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("does not record failures", func(t *testing.T) {
+		reset()
+		fail = errors.New("boom")
+		if _, err := serve("key5", "Bearer alice", nil); err == nil {
+			t.Fatal("expected an error")
+		}
+		fail = nil
+		status = http.StatusServiceUnavailable
+		if _, err := serve("key5", "Bearer alice", nil); err != nil {
+			t.Fatal(err)
+		}
+		status = http.StatusCreated
+		rw, err := serve("key5", "Bearer alice", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rw.Code != http.StatusCreated || rw.Header().Get(HeaderReplayed) != "" {
+			t.Errorf("unexpected response %d %v", rw.Code, rw.Header())
+		}
+		if calls != 3 {
+			t.Errorf("handler called %d times, want 3", calls)
+		}
+	})
+
+	t.Run("ignores requests without key", func(t *testing.T) {
+		reset()
+		for range 2 {
+			if _, err := serve("", "", nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if calls != 2 {
+			t.Errorf("handler called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("rejects long keys", func(t *testing.T) {
+		reset()
+		_, err := serve(strings.Repeat("k", maxKeyLength+1), "", nil)
+		if shogoa.ErrorCode(err) != "bad_request" {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+}
+
+func TestMiddleware_Concurrent(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := New(NewMemoryStore())(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		close(started)
+		<-release
+		rw.WriteHeader(http.StatusCreated)
+		return nil
+	})
+	serve := func() error {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set(HeaderKey, "key")
+		req.Header.Set("Authorization", "Bearer alice")
+		rw := httptest.NewRecorder()
+		ctx := shogoa.NewContext(rw, req, nil)
+		return handler(ctx, shogoa.ContextResponse(ctx), req)
+	}
+
+	done := make(chan error)
+	go func() { done <- serve() }()
+	<-started
+	if err := serve(); shogoa.ErrorCode(err) != "conflict" {
+		t.Errorf("unexpected error %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if rec, err := store.Start(ctx, "key", "fp", time.Minute); err != nil || rec != nil {
+		t.Fatalf("unexpected result %v, %v", rec, err)
+	}
+	rec, err := store.Start(ctx, "key", "other", time.Minute)
+	if err != nil || rec == nil || rec.Fingerprint != "fp" || rec.Done {
+		t.Fatalf("unexpected result %v, %v", rec, err)
+	}
+	if err := store.Save(ctx, "key", &Record{Fingerprint: "fp", Done: true}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(30 * time.Minute)
+	if rec, _ := store.Start(ctx, "key", "fp", time.Minute); rec == nil || !rec.Done {
+		t.Errorf("unexpected record %v", rec)
+	}
+	now = now.Add(time.Hour)
+	if rec, _ := store.Start(ctx, "key", "fp", time.Minute); rec != nil {
+		t.Errorf("expected the record to expire, got %v", rec)
+	}
+	if len(store.records) != 1 {
+		t.Errorf("expected the expired records to be swept, got %d records", len(store.records))
+	}
+}
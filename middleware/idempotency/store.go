@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record is the state of an idempotency key.
+type Record struct {
+	// Fingerprint identifies the request that first used the key, retries must have the same
+	// fingerprint.
+	Fingerprint string
+	// Done is false while the first request is processed and true once its response is recorded.
+	Done bool
+	// Status is the status code of the recorded response.
+	Status int
+	// Header contains the headers of the recorded response.
+	Header http.Header
+	// Body is the body of the recorded response.
+	Body []byte
+}
+
+// Store is the interface implemented by the record stores. Stores shared by several service
+// instances make retries safe across instances.
+type Store interface {
+	// Start atomically creates a record that is not done with the given fingerprint for key if
+	// key is unknown and returns nil. It returns the existing record otherwise. The store may
+	// discard the record after ttl.
+	Start(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error)
+	// Save replaces the record of key with the given record and keeps it for ttl.
+	Save(ctx context.Context, key string, rec *Record, ttl time.Duration) error
+	// Delete removes the record of key so that the request can be retried.
+	Delete(ctx context.Context, key string) error
+}
+
+// sweepInterval is the interval between two removals of the expired records of a MemoryStore.
+const sweepInterval = time.Minute
+
+// MemoryStore is a Store that keeps the records in memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*memoryRecord
+	lastSweep time.Time
+}
+
+type memoryRecord struct {
+	*Record
+	expires time.Time
+}
+
+// NewMemoryStore returns an in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*memoryRecord)}
+}
+
+// Start creates the record of key if it is unknown.
+func (s *MemoryStore) Start(_ context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	now := timeNow()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, r := range s.records {
+			if now.After(r.expires) {
+				delete(s.records, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	if r, ok := s.records[key]; ok && !now.After(r.expires) {
+		return r.Record, nil
+	}
+	s.records[key] = &memoryRecord{
+		Record:  &Record{Fingerprint: fingerprint},
+		expires: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Save records the response of key.
+func (s *MemoryStore) Save(_ context.Context, key string, rec *Record, ttl time.Duration) error {
+	now := timeNow()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = &memoryRecord{Record: rec, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete removes the record of key.
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
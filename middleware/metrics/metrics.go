@@ -0,0 +1,372 @@
+// Package metrics provides a middleware that records request metrics labelled by controller,
+// action, HTTP method and response status, and a handler that serves them in the Prometheus text
+// exposition format. It does not depend on the Prometheus client library:
+//
+//	collector := metrics.New()
+//	service.Use(collector.Middleware())
+//	service.Mux.Handle("GET", "/metrics", func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+//		collector.ServeHTTP(rw, req)
+//	})
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	// DefaultDurationBuckets are the default request duration histogram buckets in seconds.
+	DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+	// DefaultSizeBuckets are the default response size histogram buckets in bytes.
+	DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+// Option allows to override default parameters.
+type Option func(*options) error
+
+// options contains final options
+type options struct {
+	namespace       string
+	durationBuckets []float64
+	sizeBuckets     []float64
+}
+
+// Namespace sets a prefix added to the name of all the metrics, for example the namespace "api"
+// produces the "api_http_requests_total" metric.
+func Namespace(ns string) Option {
+	return func(o *options) error {
+		if !validName(ns) {
+			return fmt.Errorf("invalid metric namespace %#v", ns)
+		}
+		o.namespace = ns + "_"
+		return nil
+	}
+}
+
+// DurationBuckets overrides the upper bounds in seconds of the request duration histogram buckets.
+func DurationBuckets(bounds ...float64) Option {
+	return func(o *options) error {
+		if err := validBuckets(bounds); err != nil {
+			return err
+		}
+		o.durationBuckets = bounds
+		return nil
+	}
+}
+
+// SizeBuckets overrides the upper bounds in bytes of the response size histogram buckets.
+func SizeBuckets(bounds ...float64) Option {
+	return func(o *options) error {
+		if err := validBuckets(bounds); err != nil {
+			return err
+		}
+		o.sizeBuckets = bounds
+		return nil
+	}
+}
+
+type (
+	// Collector records the metrics of the requests handled by its middleware.
+	Collector struct {
+		options
+
+		mu       sync.Mutex
+		requests map[requestKey]*requestSeries
+		inFlight map[actionKey]int64
+	}
+
+	// actionKey identifies the action handling a request.
+	actionKey struct {
+		controller, action, method string
+	}
+
+	// requestKey identifies the request series.
+	requestKey struct {
+		actionKey
+		status string
+	}
+
+	// requestSeries holds the metrics of the requests with the same labels.
+	requestSeries struct {
+		count    uint64
+		duration histogram
+		size     histogram
+	}
+
+	// histogram counts observations in buckets, counts are not cumulative.
+	histogram struct {
+		counts []uint64
+		sum    float64
+	}
+)
+
+// New creates a metrics collector. It panics if an option is invalid.
+func New(o ...Option) *Collector {
+	opts := options{
+		durationBuckets: DefaultDurationBuckets,
+		sizeBuckets:     DefaultSizeBuckets,
+	}
+	for _, opt := range o {
+		if err := opt(&opts); err != nil {
+			panic(err)
+		}
+	}
+	return &Collector{
+		options:  opts,
+		requests: make(map[requestKey]*requestSeries),
+		inFlight: make(map[actionKey]int64),
+	}
+}
+
+// Middleware returns a middleware that records the request count, duration, response size and the
+// number of requests in flight. The response status of requests whose handler returns an error
+// and that have not been written yet is computed from the error, so the middleware may be mounted
+// before or after ErrorHandler.
+func (c *Collector) Middleware() shogoa.Middleware {
+	return func(h shogoa.Handler) shogoa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key := actionKey{
+				controller: shogoa.ContextController(ctx),
+				action:     shogoa.ContextAction(ctx),
+				method:     req.Method,
+			}
+			c.mu.Lock()
+			c.inFlight[key]++
+			c.mu.Unlock()
+			defer func() {
+				c.mu.Lock()
+				c.inFlight[key]--
+				c.mu.Unlock()
+			}()
+
+			startedAt := time.Now()
+			err := h(ctx, rw, req)
+			duration := time.Since(startedAt)
+
+			var status, size int
+			if resp := shogoa.ContextResponse(ctx); resp != nil {
+				status, size = resp.Status, resp.Length
+			}
+			if status == 0 {
+				status = http.StatusOK
+				if err != nil {
+					status = http.StatusInternalServerError
+					var serr shogoa.ServiceError
+					if errors.As(err, &serr) {
+						status = serr.ResponseStatus()
+					}
+				}
+			}
+
+			c.mu.Lock()
+			rk := requestKey{actionKey: key, status: strconv.Itoa(status)}
+			s, ok := c.requests[rk]
+			if !ok {
+				s = &requestSeries{
+					duration: histogram{counts: make([]uint64, len(c.durationBuckets))},
+					size:     histogram{counts: make([]uint64, len(c.sizeBuckets))},
+				}
+				c.requests[rk] = s
+			}
+			s.count++
+			s.duration.observe(c.durationBuckets, duration.Seconds())
+			s.size.observe(c.sizeBuckets, float64(size))
+			c.mu.Unlock()
+
+			return err
+		}
+	}
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (c *Collector) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", ContentType)
+	c.WriteTo(rw)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	c.mu.Lock()
+	keys := make([]requestKey, 0, len(c.requests))
+	for k := range c.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+	series := make([]requestSeries, len(keys))
+	for i, k := range keys {
+		s := c.requests[k]
+		series[i] = requestSeries{
+			count:    s.count,
+			duration: histogram{counts: slices.Clone(s.duration.counts), sum: s.duration.sum},
+			size:     histogram{counts: slices.Clone(s.size.counts), sum: s.size.sum},
+		}
+	}
+	actions := make([]actionKey, 0, len(c.inFlight))
+	for k := range c.inFlight {
+		actions = append(actions, k)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].less(actions[j]) })
+	inFlight := make([]int64, len(actions))
+	for i, k := range actions {
+		inFlight[i] = c.inFlight[k]
+	}
+	c.mu.Unlock()
+
+	name := c.namespace + "http_requests_total"
+	cw.header(name, "counter", "Total number of HTTP requests.")
+	for i, k := range keys {
+		cw.sample(name, k.labels(), strconv.FormatUint(series[i].count, 10))
+	}
+
+	name = c.namespace + "http_request_duration_seconds"
+	cw.header(name, "histogram", "Duration of HTTP requests in seconds.")
+	for i, k := range keys {
+		cw.histogram(name, k.labels(), c.durationBuckets, &series[i].duration, series[i].count)
+	}
+
+	name = c.namespace + "http_response_size_bytes"
+	cw.header(name, "histogram", "Size of HTTP response bodies in bytes.")
+	for i, k := range keys {
+		cw.histogram(name, k.labels(), c.sizeBuckets, &series[i].size, series[i].count)
+	}
+
+	name = c.namespace + "http_requests_in_flight"
+	cw.header(name, "gauge", "Number of HTTP requests being handled.")
+	for i, k := range actions {
+		cw.sample(name, k.labels(), strconv.FormatInt(inFlight[i], 10))
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// observe records the value v.
+func (h *histogram) observe(bounds []float64, v float64) {
+	if i := sort.SearchFloat64s(bounds, v); i < len(bounds) {
+		h.counts[i]++
+	}
+	h.sum += v
+}
+
+// labels returns the formatted labels of the action.
+func (k actionKey) labels() string {
+	return fmt.Sprintf(`action="%s",controller="%s",method="%s"`,
+		escape(k.action), escape(k.controller), escape(k.method))
+}
+
+func (k actionKey) less(o actionKey) bool {
+	if k.controller != o.controller {
+		return k.controller < o.controller
+	}
+	if k.action != o.action {
+		return k.action < o.action
+	}
+	return k.method < o.method
+}
+
+// labels returns the formatted labels of the request series.
+func (k requestKey) labels() string {
+	return fmt.Sprintf(`%s,status="%s"`, k.actionKey.labels(), escape(k.status))
+}
+
+func (k requestKey) less(o requestKey) bool {
+	if k.actionKey != o.actionKey {
+		return k.actionKey.less(o.actionKey)
+	}
+	return k.status < o.status
+}
+
+// countingWriter writes the exposition format and keeps track of the first error.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) printf(format string, a ...any) {
+	if cw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(cw.w, format, a...)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+func (cw *countingWriter) header(name, typ, help string) {
+	cw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (cw *countingWriter) sample(name, labels, value string) {
+	cw.printf("%s{%s} %s\n", name, labels, value)
+}
+
+func (cw *countingWriter) histogram(name, labels string, bounds []float64, h *histogram, count uint64) {
+	var acc uint64
+	for i, b := range bounds {
+		acc += h.counts[i]
+		cw.sample(name+"_bucket", labels+`,le="`+formatFloat(b)+`"`, strconv.FormatUint(acc, 10))
+	}
+	cw.sample(name+"_bucket", labels+`,le="+Inf"`, strconv.FormatUint(count, 10))
+	cw.sample(name+"_sum", labels, formatFloat(h.sum))
+	cw.sample(name+"_count", labels, strconv.FormatUint(count, 10))
+}
+
+// escape escapes a label value as required by the exposition format.
+func escape(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// validName returns true if name is a valid metric name prefix.
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validBuckets checks that the bucket bounds are strictly increasing.
+func validBuckets(bounds []float64) error {
+	if len(bounds) == 0 {
+		return errors.New("histogram must have at least one bucket")
+	}
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] <= bounds[i-1] {
+			return fmt.Errorf("histogram buckets must be in increasing order, got %v", bounds)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shogo82148/shogoa"
+)
+
+func TestCollector(t *testing.T) {
+	collector := New(Namespace("api"), DurationBuckets(1, 10), SizeBuckets(2, 8))
+	service := shogoa.New("test")
+	service.Use(collector.Middleware())
+	ctrl := service.NewController("bottle")
+	show := ctrl.MuxHandler("show", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		io.WriteString(rw, "bottle")
+		return nil
+	}, nil)
+	update := ctrl.MuxHandler("update", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return shogoa.ErrBadRequest("invalid")
+	}, nil)
+
+	for range 2 {
+		show(httptest.NewRecorder(), httptest.NewRequest("GET", "/bottles/1", nil), nil)
+	}
+	update(httptest.NewRecorder(), httptest.NewRequest("PUT", "/bottles/1", nil), nil)
+
+	rw := httptest.NewRecorder()
+	collector.ServeHTTP(rw, httptest.NewRequest("GET", "/metrics", nil))
+	if got := rw.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("unexpected Content-Type: %q", got)
+	}
+	body := rw.Body.String()
+	for _, want := range []string{
+		"# TYPE api_http_requests_total counter\n",
+		`api_http_requests_total{action="show",controller="bottle",method="GET",status="200"} 2` + "\n",
+		`api_http_requests_total{action="update",controller="bottle",method="PUT",status="400"} 1` + "\n",
+		"# TYPE api_http_request_duration_seconds histogram\n",
+		`api_http_request_duration_seconds_bucket{action="show",controller="bottle",method="GET",status="200",le="1"} 2` + "\n",
+		`api_http_request_duration_seconds_bucket{action="show",controller="bottle",method="GET",status="200",le="+Inf"} 2` + "\n",
+		`api_http_request_duration_seconds_count{action="show",controller="bottle",method="GET",status="200"} 2` + "\n",
+		`api_http_response_size_bytes_bucket{action="show",controller="bottle",method="GET",status="200",le="2"} 0` + "\n",
+		`api_http_response_size_bytes_bucket{action="show",controller="bottle",method="GET",status="200",le="8"} 2` + "\n",
+		`api_http_response_size_bytes_sum{action="show",controller="bottle",method="GET",status="200"} 12` + "\n",
+		"# TYPE api_http_requests_in_flight gauge\n",
+		`api_http_requests_in_flight{action="show",controller="bottle",method="GET"} 0` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+	if strings.Index(body, `action="show"`) > strings.Index(body, `action="update"`) {
+		t.Error("series are not sorted")
+	}
+}
+
+func TestCollector_InFlight(t *testing.T) {
+	collector := New()
+	var during string
+	h := collector.Middleware()(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		var b strings.Builder
+		collector.WriteTo(&b)
+		during = b.String()
+		return nil
+	})
+	h(context.Background(), httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if want := `http_requests_in_flight{action="<unknown>",controller="<unknown>",method="GET"} 1`; !strings.Contains(during, want) {
+		t.Errorf("missing %q in:\n%s", want, during)
+	}
+}
+
+func TestCollector_InFlightPanic(t *testing.T) {
+	collector := New()
+	h := collector.Middleware()(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		panic("boom")
+	})
+	func() {
+		defer func() { recover() }()
+		h(context.Background(), httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	var b strings.Builder
+	collector.WriteTo(&b)
+	if want := `http_requests_in_flight{action="<unknown>",controller="<unknown>",method="GET"} 0`; !strings.Contains(b.String(), want) {
+		t.Errorf("missing %q in:\n%s", want, b.String())
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got, want := escape("a\\b\"c\nd"), `a\\b\"c\nd`; got != want {
+		t.Errorf("escape() = %q; want %q", got, want)
+	}
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	for name, o := range map[string]Option{
+		"namespace": Namespace("1api"),
+		"buckets":   DurationBuckets(1, 1),
+		"empty":     SizeBuckets(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("New did not panic")
+				}
+			}()
+			New(o)
+		})
+	}
+}
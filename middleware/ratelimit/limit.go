@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is the number of requests allowed in a period of time.
+type Limit struct {
+	// Requests is the maximum number of requests in Period.
+	Requests int
+	// Period is the duration of the rate limit window.
+	Period time.Duration
+}
+
+// periodUnits lists the units of the limit periods.
+var periodUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+}
+
+// ParseLimit parses a limit of the form "<requests>/<period>" where period is one of "s", "m", "h"
+// and "d" optionally prefixed with a number, e.g. "100/m" or "10/30s".
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <requests>/<period>", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, the number of requests must be a positive integer", s)
+	}
+	if period == "" {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, missing period", s)
+	}
+	unit, ok := periodUnits[period[len(period)-1:]]
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, the period unit must be one of s, m, h or d", s)
+	}
+	count := 1
+	if prefix := period[:len(period)-1]; prefix != "" {
+		count, err = strconv.Atoi(prefix)
+		if err != nil || count <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q, invalid period", s)
+		}
+	}
+	return Limit{Requests: n, Period: time.Duration(count) * unit}, nil
+}
+
+// String returns the limit in the format accepted by ParseLimit.
+func (l Limit) String() string {
+	for _, u := range []string{"d", "h", "m", "s"} {
+		unit := periodUnits[u]
+		if l.Period%unit == 0 {
+			if count := l.Period / unit; count != 1 {
+				return fmt.Sprintf("%d/%d%s", l.Requests, count, u)
+			}
+			return fmt.Sprintf("%d/%s", l.Requests, u)
+		}
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
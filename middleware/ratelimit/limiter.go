@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// timeNow returns the current time, tests may override it.
+var timeNow = time.Now
+
+// Result is the outcome of a rate limit check.
+type Result struct {
+	// Allowed is true if the request may proceed.
+	Allowed bool
+	// Limit is the limit that was checked.
+	Limit Limit
+	// Remaining is the number of requests left before the limit is reached.
+	Remaining int
+	// Reset is the time until the quota of requests is fully available again.
+	Reset time.Duration
+	// RetryAfter is the time to wait before making a request that is allowed if Allowed is false.
+	RetryAfter time.Duration
+}
+
+// Limiter is the interface implemented by the rate limiting algorithms.
+type Limiter interface {
+	// Allow consumes a request from the quota of key and reports whether the request is allowed.
+	Allow(ctx context.Context, key string, limit Limit) (*Result, error)
+}
+
+// tokenBucket implements the token bucket algorithm.
+type tokenBucket struct {
+	store Store
+}
+
+// NewTokenBucket returns a limiter that implements the token bucket algorithm: each key has a
+// bucket holding up to Requests tokens that refills continuously at the rate of Requests tokens
+// per Period and each request consumes a token. The token bucket allows bursts of Requests
+// requests.
+func NewTokenBucket(store Store) Limiter {
+	return &tokenBucket{store: store}
+}
+
+// Allow consumes a token from the bucket of key.
+func (b *tokenBucket) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	res := &Result{Limit: limit}
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Period.Seconds()
+	err := b.store.Update(ctx, key, limit.Period, func(s *State) {
+		now := timeNow()
+		tokens := capacity
+		if !s.Time.IsZero() {
+			tokens = math.Min(capacity, s.Value+now.Sub(s.Time).Seconds()*rate)
+		}
+		if tokens >= 1 {
+			tokens--
+			res.Allowed = true
+		} else {
+			res.RetryAfter = seconds((1 - tokens) / rate)
+		}
+		s.Value = tokens
+		s.Time = now
+		res.Remaining = int(tokens)
+		res.Reset = seconds((capacity - tokens) / rate)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// slidingWindow implements the sliding window counter algorithm.
+type slidingWindow struct {
+	store Store
+}
+
+// NewSlidingWindow returns a limiter that implements the sliding window counter algorithm: the
+// number of requests made in the last Period is estimated from the counts of the current and
+// previous fixed windows weighted by the overlap of the previous window with the sliding window.
+// The sliding window does not allow bursts across window boundaries.
+func NewSlidingWindow(store Store) Limiter {
+	return &slidingWindow{store: store}
+}
+
+// Allow counts the request in the window of key.
+func (w *slidingWindow) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	res := &Result{Limit: limit}
+	quota := float64(limit.Requests)
+	err := w.store.Update(ctx, key, 2*limit.Period, func(s *State) {
+		now := timeNow()
+		start := now.Truncate(limit.Period)
+		if !s.Time.Equal(start) {
+			if s.Time.Equal(start.Add(-limit.Period)) {
+				s.Previous = s.Value
+			} else {
+				s.Previous = 0
+			}
+			s.Value = 0
+			s.Time = start
+		}
+		elapsed := now.Sub(start)
+		weight := 1 - float64(elapsed)/float64(limit.Period)
+		count := s.Previous*weight + s.Value
+		if count+1 <= quota {
+			s.Value++
+			count++
+			res.Allowed = true
+		} else if s.Value+1 <= quota && s.Previous > 0 {
+			// wait until the previous window weighs little enough
+			wait := ceilDuration((1-(quota-s.Value-1)/s.Previous)*float64(limit.Period)) - elapsed
+			res.RetryAfter = max(wait, time.Second)
+		} else {
+			// wait for the next window, in which the current window becomes the previous one,
+			// and until it weighs little enough
+			res.RetryAfter = limit.Period - elapsed
+			if s.Value > quota-1 {
+				res.RetryAfter += ceilDuration((1 - (quota-1)/s.Value) * float64(limit.Period))
+			}
+		}
+		res.Remaining = max(int(quota-count), 0)
+		res.Reset = limit.Period - elapsed
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ceilDuration converts a number of nanoseconds into a duration rounded up so that requests
+// retried after the duration are not early.
+func ceilDuration(ns float64) time.Duration {
+	return time.Duration(math.Ceil(ns))
+}
+
+// seconds converts a number of seconds into a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
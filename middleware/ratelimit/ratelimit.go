@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+	"github.com/shogo82148/shogoa/middleware/security/apikey"
+)
+
+// KeyFunc returns the key that identifies the client of a request, requests with the same key
+// share the same quota.
+type KeyFunc func(ctx context.Context, req *http.Request) string
+
+// ClientIP is a KeyFunc that identifies clients by the IP address of the remote end of the
+// connection. Use a custom KeyFunc to identify clients behind proxies.
+func ClientIP(_ context.Context, req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// APIKeyPrincipal is a KeyFunc that identifies clients by the name of the principal resolved by
+// the apikey security middleware, it falls back to ClientIP for requests without principal. The
+// rate limit middleware must run after the security middleware to use APIKeyPrincipal, which is
+// the case of the rate limits declared in the design.
+func APIKeyPrincipal(ctx context.Context, req *http.Request) string {
+	if p := apikey.ContextPrincipal(ctx); p != nil {
+		return "principal:" + p.Name
+	}
+	return ClientIP(ctx, req)
+}
+
+// Option allows to override default parameters.
+type Option func(*options) error
+
+// options contains final options
+type options struct {
+	key          KeyFunc
+	defaultLimit *Limit
+}
+
+// Key sets the function that identifies the clients, ClientIP by default.
+func Key(f KeyFunc) Option {
+	return func(o *options) error {
+		o.key = f
+		return nil
+	}
+}
+
+// DefaultLimit sets the limit applied to the actions that do not declare a rate limit in the
+// design. Such actions are not limited by default. All the actions without rate limit share the
+// same quota.
+func DefaultLimit(limit string) Option {
+	return func(o *options) error {
+		l, err := ParseLimit(limit)
+		if err != nil {
+			return err
+		}
+		o.defaultLimit = &l
+		return nil
+	}
+}
+
+// New returns a middleware that limits the rate of requests made by each client using the given
+// limiter. It panics if an option is invalid.
+//
+// The middleware enforces the limits declared in the design with the "ratelimit" metadata on
+// actions or resources when mounted with the service UseRateLimit method, each action has its own
+// quota:
+//
+//	service.UseRateLimit(ratelimit.New(ratelimit.NewTokenBucket(ratelimit.NewMemoryStore())))
+//
+// It may also be mounted as a service or controller middleware with the DefaultLimit option to
+// enforce a global limit.
+//
+// The responses carry the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers. Requests that exceed the limit fail with shogoa.ErrTooManyRequests
+// errors and the Retry-After header is set.
+func New(limiter Limiter, o ...Option) shogoa.Middleware {
+	opts := options{key: ClientIP}
+	for _, opt := range o {
+		if err := opt(&opts); err != nil {
+			panic(err)
+		}
+	}
+	var limits sync.Map // parsed design limits indexed by specification
+
+	return func(h shogoa.Handler) shogoa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var (
+				limit Limit
+				scope string
+			)
+			if spec := shogoa.ContextRateLimit(ctx); spec != "" {
+				if l, ok := limits.Load(spec); ok {
+					limit = l.(Limit)
+				} else {
+					l, err := ParseLimit(spec)
+					if err != nil {
+						return err
+					}
+					limits.Store(spec, l)
+					limit = l
+				}
+				scope = shogoa.ContextController(ctx) + "#" + shogoa.ContextAction(ctx)
+			} else if opts.defaultLimit != nil {
+				limit = *opts.defaultLimit
+				scope = "*"
+			} else {
+				return h(ctx, rw, req)
+			}
+
+			res, err := limiter.Allow(ctx, opts.key(ctx, req)+"|"+scope, limit)
+			if err != nil {
+				return fmt.Errorf("rate limit check failed: %w", err)
+			}
+			header := rw.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			header.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Period.Seconds())))
+			if !res.Allowed {
+				header.Set("Retry-After", ceilSeconds(res.RetryAfter))
+				return shogoa.ErrTooManyRequests("rate limit exceeded", "limit", limit.String())
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// ceilSeconds formats d as a number of seconds rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,219 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+	"github.com/shogo82148/shogoa/middleware"
+)
+
+// setTime makes the limiters use the given time.
+func setTime(t *testing.T, now *time.Time) {
+	t.Helper()
+	timeNow = func() time.Time { return *now }
+	t.Cleanup(func() { timeNow = time.Now })
+}
+
+func TestParseLimit(t *testing.T) {
+	valid := map[string]Limit{
+		"100/m":  {100, time.Minute},
+		"10/30s": {10, 30 * time.Second},
+		"1/d":    {1, 24 * time.Hour},
+		"5000/h": {5000, time.Hour},
+	}
+	for s, want := range valid {
+		got, err := ParseLimit(s)
+		if err != nil {
+			t.Errorf("ParseLimit(%q): %v", s, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseLimit(%q) = %v, want %v", s, got, want)
+		}
+		if got.String() != s {
+			t.Errorf("%v.String() = %q, want %q", got, got.String(), s)
+		}
+	}
+	for _, s := range []string{"", "100", "0/m", "-1/m", "100/", "100/w", "100/0s", "a/m"} {
+		if _, err := ParseLimit(s); err == nil {
+			t.Errorf("ParseLimit(%q): expected an error", s)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(1000, 0)
+	setTime(t, &now)
+	limiter := NewTokenBucket(NewMemoryStore())
+	limit := Limit{Requests: 2, Period: 10 * time.Second}
+	ctx := context.Background()
+
+	for i := range 2 {
+		res, err := limiter.Allow(ctx, "key", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != 1-i {
+			t.Errorf("request %d: unexpected result %+v", i, res)
+		}
+	}
+	res, _ := limiter.Allow(ctx, "key", limit)
+	if res.Allowed || res.RetryAfter != 5*time.Second {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if res, _ := limiter.Allow(ctx, "other", limit); !res.Allowed {
+		t.Errorf("keys must have separate buckets: %+v", res)
+	}
+
+	now = now.Add(5 * time.Second)
+	if res, _ := limiter.Allow(ctx, "key", limit); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("expected the bucket to refill: %+v", res)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	now := time.Unix(1000, 0).Truncate(time.Minute)
+	setTime(t, &now)
+	limiter := NewSlidingWindow(NewMemoryStore())
+	limit := Limit{Requests: 4, Period: time.Minute}
+	ctx := context.Background()
+
+	for i := range 4 {
+		if res, _ := limiter.Allow(ctx, "key", limit); !res.Allowed || res.Remaining != 3-i {
+			t.Errorf("request %d: unexpected result %+v", i, res)
+		}
+	}
+	// The 4 requests weigh 3 a quarter into the next window.
+	res, _ := limiter.Allow(ctx, "key", limit)
+	if res.Allowed || res.RetryAfter != 75*time.Second {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	// 4 requests in the previous window weigh 3 a quarter into the next window.
+	now = now.Add(75 * time.Second)
+	if res, _ := limiter.Allow(ctx, "key", limit); !res.Allowed {
+		t.Errorf("unexpected result %+v", res)
+	}
+	res, _ = limiter.Allow(ctx, "key", limit)
+	if res.Allowed || res.RetryAfter != 15*time.Second {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if res, _ := limiter.Allow(ctx, "key", limit); !res.Allowed || res.Remaining != 3 {
+		t.Errorf("expected an empty window: %+v", res)
+	}
+}
+
+func TestSlidingWindowRetryAfter(t *testing.T) {
+	now := time.Unix(1000, 0).Truncate(10 * time.Second)
+	setTime(t, &now)
+	limiter := NewSlidingWindow(NewMemoryStore())
+	limit := Limit{Requests: 3, Period: 10 * time.Second}
+	ctx := context.Background()
+
+	now = now.Add(4 * time.Second)
+	for range 3 {
+		limiter.Allow(ctx, "key", limit)
+	}
+	for i := range 3 {
+		res, _ := limiter.Allow(ctx, "key", limit)
+		if res.Allowed {
+			t.Fatalf("retry %d: unexpected result %+v", i, res)
+		}
+		now = now.Add(res.RetryAfter)
+		if res, _ := limiter.Allow(ctx, "key", limit); !res.Allowed {
+			t.Errorf("retry %d: request made after Retry-After was rejected: %+v", i, res)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	now := time.Unix(1000, 0)
+	setTime(t, &now)
+
+	service := shogoa.New("test")
+	service.Encoder.Register(shogoa.NewJSONEncoder, "*/*")
+	service.Use(middleware.ErrorHandler(service, false))
+	service.UseRateLimit(New(NewTokenBucket(NewMemoryStore()), DefaultLimit("10/m")))
+	ctrl := service.NewController("bottles")
+	var calls int
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		calls++
+		rw.WriteHeader(http.StatusOK)
+		return nil
+	}
+	handler := ctrl.MuxHandler("show", service.HandleRateLimit("1/m", h), nil)
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		rw := httptest.NewRecorder()
+		handler(rw, req, nil)
+		return rw
+	}
+
+	rw := serve("192.0.2.1:1234")
+	if rw.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rw.Code)
+	}
+	want := map[string]string{
+		"RateLimit-Limit":     "1",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+		"RateLimit-Policy":    "1;w=60",
+	}
+	for k, v := range want {
+		if got := rw.Header().Get(k); got != v {
+			t.Errorf("unexpected %s header: %q", k, got)
+		}
+	}
+
+	rw = serve("192.0.2.1:5678")
+	if rw.Code != http.StatusTooManyRequests {
+		t.Errorf("unexpected status %d", rw.Code)
+	}
+	if got := rw.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("unexpected Retry-After header: %q", got)
+	}
+
+	if rw := serve("192.0.2.2:1234"); rw.Code != http.StatusOK {
+		t.Errorf("clients must have separate quotas, got status %d", rw.Code)
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
+
+func TestMiddlewareDefaultLimit(t *testing.T) {
+	m := New(NewSlidingWindow(NewMemoryStore()), DefaultLimit("1/m"))
+	handler := m(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	for i, ok := range []bool{true, false} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rw := httptest.NewRecorder()
+		err := handler(shogoa.NewContext(rw, req, nil), rw, req)
+		if ok && err != nil {
+			t.Errorf("request %d: unexpected error %v", i, err)
+		}
+		if !ok && shogoa.ErrorCode(err) != "too_many_requests" {
+			t.Errorf("request %d: unexpected error %v", i, err)
+		}
+	}
+
+	unlimited := New(NewSlidingWindow(NewMemoryStore()))(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	for range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rw := httptest.NewRecorder()
+		if err := unlimited(shogoa.NewContext(rw, req, nil), rw, req); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// State is the state of a limiter for a key.
+type State struct {
+	// Value is the number of tokens left in a token bucket or the number of requests made in
+	// the current window of a sliding window.
+	Value float64
+	// Previous is the number of requests made in the previous window of a sliding window.
+	Previous float64
+	// Time is the time of the last refill of a token bucket or the start of the current window
+	// of a sliding window.
+	Time time.Time
+}
+
+// Store is the interface implemented by the limiter state stores. Stores shared by several
+// service instances make the limits apply to the service as a whole.
+type Store interface {
+	// Update calls fn with the state of key and saves the state modified by fn, the state is the
+	// zero value if the key is unknown. The calls to Update for the same key must be
+	// serialized. The store may discard the state of a key that is not updated for ttl.
+	Update(ctx context.Context, key string, ttl time.Duration, fn func(*State)) error
+}
+
+// sweepInterval is the interval between two removals of the expired states of a MemoryStore.
+const sweepInterval = time.Minute
+
+// MemoryStore is a Store that keeps the states in memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	states    map[string]*memoryState
+	lastSweep time.Time
+}
+
+type memoryState struct {
+	State
+	expires time.Time
+}
+
+// NewMemoryStore returns an in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]*memoryState)}
+}
+
+// Update updates the state of key.
+func (s *MemoryStore) Update(_ context.Context, key string, ttl time.Duration, fn func(*State)) error {
+	now := timeNow()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, st := range s.states {
+			if now.After(st.expires) {
+				delete(s.states, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	st, ok := s.states[key]
+	if !ok || now.After(st.expires) {
+		st = &memoryState{}
+		s.states[key] = st
+	}
+	fn(&st.State)
+	st.expires = now.Add(ttl)
+	return nil
+}
@@ -0,0 +1,79 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/shogo82148/shogoa"
+)
+
+var (
+	// ErrAPIKeyFailed is the error returned by this middleware when the request does not carry
+	// an API key or when the key is unknown.
+	ErrAPIKeyFailed = shogoa.NewErrorClass("api_key_failed", 401)
+
+	// ErrInsufficientScope is the error returned by this middleware when the API key does not
+	// grant the scopes required by the action.
+	ErrInsufficientScope = shogoa.NewErrorClass("insufficient_scope", 403)
+)
+
+// New returns a middleware to be used with the APIKeySecurity DSL definitions of shogoa.
+//
+// The steps taken by the middleware are:
+//
+//  1. Extract the key from the header or the query string parameter defined by the scheme
+//  2. Resolve the key to a principal with the given store
+//  3. If scopes are defined in the design for the action, validate them against the scopes
+//     granted to the principal
+//
+// The principal is available to the handlers via ContextPrincipal.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//	store, err := apikey.NewFileStore("/etc/myapp/api_keys")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	app.UseAPIKeyMiddleware(service, apikey.New(store, app.NewAPIKeySecurity()))
+func New(store KeyStore, scheme *shogoa.APIKeySecurity) shogoa.Middleware {
+	return func(nextHandler shogoa.Handler) shogoa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var key string
+			switch scheme.In {
+			case shogoa.LocHeader:
+				key = req.Header.Get(scheme.Name)
+				if key == "" {
+					return ErrAPIKeyFailed(fmt.Sprintf("missing header %q", scheme.Name))
+				}
+			case shogoa.LocQuery:
+				key = req.URL.Query().Get(scheme.Name)
+				if key == "" {
+					return ErrAPIKeyFailed(fmt.Sprintf("missing parameter %q", scheme.Name))
+				}
+			default:
+				return fmt.Errorf("whoops, security scheme with location (in) %q not supported", scheme.In)
+			}
+
+			principal, err := store.Lookup(ctx, key)
+			if err != nil {
+				return err
+			}
+			if principal == nil {
+				return ErrAPIKeyFailed("invalid API key")
+			}
+
+			requiredScopes := shogoa.ContextRequiredScopes(ctx)
+			for _, scope := range requiredScopes {
+				if !slices.Contains(principal.Scopes, scope) {
+					return ErrInsufficientScope("the API key does not grant the required scopes",
+						"required", requiredScopes, "scopes", principal.Scopes)
+				}
+			}
+
+			return nextHandler(WithPrincipal(ctx, principal), rw, req)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shogo82148/shogoa"
+)
+
+func TestMiddleware(t *testing.T) {
+	store := NewMemoryStore(map[string]*Principal{
+		"secret": {Name: "alice", Scopes: []string{"read", "write"}},
+	})
+	schemes := map[string]*shogoa.APIKeySecurity{
+		"header": {In: shogoa.LocHeader, Name: "X-API-Key"},
+		"query":  {In: shogoa.LocQuery, Name: "api_key"},
+	}
+
+	for name, scheme := range schemes {
+		serve := func(key string, scopes ...string) (*Principal, error) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if key != "" {
+				if scheme.In == shogoa.LocHeader {
+					req.Header.Set(scheme.Name, key)
+				} else {
+					req.URL.RawQuery = scheme.Name + "=" + key
+				}
+			}
+			rw := httptest.NewRecorder()
+			ctx := shogoa.WithRequiredScopes(shogoa.NewContext(rw, req, nil), scopes)
+			var got *Principal
+			handler := New(store, scheme)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				got = ContextPrincipal(ctx)
+				return nil
+			})
+			return got, handler(ctx, rw, req)
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("valid key", func(t *testing.T) {
+				p, err := serve("secret", "read")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p == nil || p.Name != "alice" {
+					t.Errorf("unexpected principal: %+v", p)
+				}
+			})
+
+			t.Run("missing key", func(t *testing.T) {
+				if _, err := serve(""); shogoa.ErrorCode(err) != "api_key_failed" {
+					t.Errorf("unexpected error: %v", err)
+				}
+			})
+
+			t.Run("unknown key", func(t *testing.T) {
+				if _, err := serve("other"); shogoa.ErrorCode(err) != "api_key_failed" {
+					t.Errorf("unexpected error: %v", err)
+				}
+			})
+
+			t.Run("insufficient scope", func(t *testing.T) {
+				_, err := serve("secret", "admin")
+				var serr shogoa.ServiceError
+				if !errors.As(err, &serr) || serr.ResponseStatus() != 403 {
+					t.Errorf("unexpected error: %v", err)
+				}
+			})
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "keys")
+	content := "# test keys\n\n" + HashKey("secret") + " ci-bot read write\n" + HashKey("other") + " guest\n"
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := store.Lookup(context.Background(), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || p.Name != "ci-bot" || len(p.Scopes) != 2 {
+		t.Errorf("unexpected principal: %+v", p)
+	}
+	if p, _ := store.Lookup(context.Background(), "other"); p == nil || p.Name != "guest" || len(p.Scopes) != 0 {
+		t.Errorf("unexpected principal: %+v", p)
+	}
+	if p, _ := store.Lookup(context.Background(), "unknown"); p != nil {
+		t.Errorf("unexpected principal: %+v", p)
+	}
+
+	t.Run("invalid file", func(t *testing.T) {
+		if err := os.WriteFile(filename, []byte("not-a-hash name\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewFileStore(filename); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package apikey
+
+import "context"
+
+type contextKey struct{}
+
+var principalKey = contextKey{}
+
+// WithPrincipal creates a child context containing the given principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// ContextPrincipal retrieves the principal the API key of the request resolved to from a
+// `context` that went through our security middleware.
+func ContextPrincipal(ctx context.Context) *Principal {
+	p, ok := ctx.Value(principalKey).(*Principal)
+	if !ok {
+		return nil
+	}
+	return p
+}
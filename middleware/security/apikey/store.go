@@ -0,0 +1,108 @@
+package apikey
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Principal is the identity an API key resolves to.
+type Principal struct {
+	// Name identifies the owner of the key.
+	Name string
+	// Scopes lists the scopes granted to the key.
+	Scopes []string
+}
+
+// KeyStore is the interface implemented by the API key stores. Lookup returns a nil principal
+// if the key is unknown and an error only if the key could not be checked.
+type KeyStore interface {
+	Lookup(ctx context.Context, key string) (*Principal, error)
+}
+
+// HashKey returns the hex encoded SHA-256 hash of key as stored in the files loaded with
+// NewFileStore.
+func HashKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// hashedKey is a key store entry.
+type hashedKey struct {
+	hash      [sha256.Size]byte
+	principal *Principal
+}
+
+// MemoryStore is a KeyStore that keeps the SHA-256 hashes of the keys in memory. Lookups compare
+// the hash of the given key with all the hashes in constant time so that the duration of a lookup
+// does not reveal information about the stored keys.
+type MemoryStore struct {
+	keys []hashedKey
+}
+
+// NewMemoryStore returns a key store that resolves the keys of the given map to the principals
+// they index.
+func NewMemoryStore(keys map[string]*Principal) *MemoryStore {
+	s := &MemoryStore{keys: make([]hashedKey, 0, len(keys))}
+	for key, p := range keys {
+		s.keys = append(s.keys, hashedKey{hash: sha256.Sum256([]byte(key)), principal: p})
+	}
+	return s
+}
+
+// NewFileStore loads a key store from the file with the given name. Each line of the file
+// describes a key with its hex encoded SHA-256 hash as computed by HashKey, the name of the
+// principal and the scopes granted to the key separated with white spaces, e.g.:
+//
+//	# hash name scopes...
+//	9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08 ci-bot read write
+//
+// Empty lines and lines starting with "#" are ignored.
+func NewFileStore(filename string) (*MemoryStore, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := &MemoryStore{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: missing principal name", filename, n)
+		}
+		b, err := hex.DecodeString(fields[0])
+		if err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("%s:%d: invalid key hash %q", filename, n, fields[0])
+		}
+		entry := hashedKey{principal: &Principal{Name: fields[1], Scopes: fields[2:]}}
+		copy(entry.hash[:], b)
+		s.keys = append(s.keys, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Lookup returns the principal of the given key or nil if the key is unknown.
+func (s *MemoryStore) Lookup(_ context.Context, key string) (*Principal, error) {
+	hash := sha256.Sum256([]byte(key))
+	var found *Principal
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare(hash[:], k.hash[:]) == 1 {
+			found = k.principal
+		}
+	}
+	return found, nil
+}
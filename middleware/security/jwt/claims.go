@@ -0,0 +1,82 @@
+package jwt
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// Option allows to override default parameters.
+type Option func(*options)
+
+// options contains the claim policies checked by the middleware.
+type options struct {
+	issuers        []string
+	audience       string
+	clockSkew      time.Duration
+	requiredClaims []string
+}
+
+// Issuer requires the "iss" claim of the tokens to be one of the given issuers.
+func Issuer(issuers ...string) Option {
+	return func(o *options) {
+		o.issuers = issuers
+	}
+}
+
+// Audience requires the "aud" claim of the tokens to contain the given audience.
+func Audience(aud string) Option {
+	return func(o *options) {
+		o.audience = aud
+	}
+}
+
+// ClockSkew sets the tolerance applied when checking the "exp", "nbf" and "iat" claims to account
+// for clock differences between the token issuer and the service.
+func ClockSkew(d time.Duration) Option {
+	return func(o *options) {
+		o.clockSkew = d
+	}
+}
+
+// RequiredClaims requires the tokens to define the given claims.
+func RequiredClaims(names ...string) Option {
+	return func(o *options) {
+		o.requiredClaims = names
+	}
+}
+
+// validateClaims checks the time based claims of the token and the claim policies.
+func (o *options) validateClaims(token *jwt.Token) error {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return fmt.Errorf("unsupported claims shape")
+	}
+	now := time.Now()
+	if !claims.VerifyExpiresAt(now.Add(-o.clockSkew).Unix(), false) {
+		return fmt.Errorf("token is expired")
+	}
+	if !claims.VerifyNotBefore(now.Add(o.clockSkew).Unix(), false) {
+		return fmt.Errorf("token is not valid yet")
+	}
+	if !claims.VerifyIssuedAt(now.Add(o.clockSkew).Unix(), false) {
+		return fmt.Errorf("token used before issued")
+	}
+	if len(o.issuers) > 0 {
+		iss, _ := claims["iss"].(string)
+		if !slices.Contains(o.issuers, iss) {
+			return fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+	if o.audience != "" && !claims.VerifyAudience(o.audience, true) {
+		return fmt.Errorf("token audience does not contain %q", o.audience)
+	}
+	for _, name := range o.requiredClaims {
+		if v, ok := claims[name]; !ok || v == nil {
+			return fmt.Errorf("missing required claim %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// KeyProvider is the interface implemented by the values that resolve the key used to verify the
+// signature of a token, typically from the "kid" header of the token. A KeyProvider may be given
+// to New in place of static keys.
+type KeyProvider interface {
+	Key(ctx context.Context, token *jwt.Token) (any, error)
+}
+
+// DefaultJWKSRefreshInterval is the default minimum interval between two fetches of a JSON Web
+// Key Set.
+const DefaultJWKSRefreshInterval = time.Minute
+
+// DefaultJWKSFetchTimeout is the default timeout of the fetches of a JSON Web Key Set.
+const DefaultJWKSFetchTimeout = 10 * time.Second
+
+// JWKS is a KeyProvider that fetches the keys from a JSON Web Key Set (RFC 7517) endpoint, e.g.
+// the "jwks_uri" of an OpenID Connect provider. The keys are cached by key ID, the key set is
+// fetched again when a token refers to an unknown key ID but not more often than
+// MinRefreshInterval after a successful fetch so that tokens with random key IDs cannot be used to
+// flood the endpoint. Failed fetches do not delay the next fetch. Tokens with known key IDs are
+// validated without waiting for an ongoing fetch and concurrent requests for unknown key IDs share
+// a single fetch, which is not canceled with the request that started it.
+//
+// RSA, ECDSA (P-256, P-384 and P-521) and Ed25519 keys are supported, encryption keys, invalid keys
+// and keys of other types are ignored.
+type JWKS struct {
+	// URL is the URL of the key set.
+	URL string
+	// Client is the HTTP client used to fetch the key set, http.DefaultClient if nil.
+	Client *http.Client
+	// MinRefreshInterval is the minimum interval between two fetches of the key set,
+	// DefaultJWKSRefreshInterval if zero.
+	MinRefreshInterval time.Duration
+	// FetchTimeout is the timeout of the fetches of the key set, DefaultJWKSFetchTimeout if zero.
+	FetchTimeout time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]*jsonWebKey
+	fetchedAt time.Time
+	// fetch is the ongoing fetch of the key set, nil if none.
+	fetch *jwksFetch
+}
+
+// jwksFetch is a fetch of the key set shared by the requests that wait for it.
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// jsonWebKey is a JSON Web Key as defined by RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+
+	// key is the decoded public key.
+	key any
+}
+
+// NewJWKS returns a key provider that fetches the keys from the JSON Web Key Set at url.
+func NewJWKS(url string) *JWKS {
+	return &JWKS{URL: url}
+}
+
+// Key returns the key identified by the "kid" header of the token. Tokens without key ID are
+// accepted if the key set contains a single key.
+func (s *JWKS) Key(ctx context.Context, token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, err := s.key(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+	if k.Alg != "" && k.Alg != token.Method.Alg() {
+		return nil, fmt.Errorf("key %q cannot be used with algorithm %s", kid, token.Method.Alg())
+	}
+	return k.key, nil
+}
+
+// key returns the key with the given ID, it fetches the key set if the key is unknown or waits for
+// the ongoing fetch.
+func (s *JWKS) key(ctx context.Context, kid string) (*jsonWebKey, error) {
+	s.mu.Lock()
+	if k, ok := s.lookup(kid); ok {
+		s.mu.Unlock()
+		return k, nil
+	}
+	f := s.fetch
+	if f == nil {
+		interval := s.MinRefreshInterval
+		if interval == 0 {
+			interval = DefaultJWKSRefreshInterval
+		}
+		if time.Since(s.fetchedAt) < interval {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("unknown key ID %q", kid)
+		}
+		f = &jwksFetch{done: make(chan struct{})}
+		s.fetch = f
+		go s.refresh(context.WithoutCancel(ctx), f)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	k, ok := s.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	return k, nil
+}
+
+// refresh runs the fetch f of the key set and stores the fetched keys.
+func (s *JWKS) refresh(ctx context.Context, f *jwksFetch) {
+	timeout := s.FetchTimeout
+	if timeout == 0 {
+		timeout = DefaultJWKSFetchTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	keys, err := s.fetchKeys(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.keys = keys
+		s.fetchedAt = time.Now()
+	}
+	f.err = err
+	s.fetch = nil
+	close(f.done)
+}
+
+// lookup returns the cached key with the given ID.
+func (s *JWKS) lookup(kid string) (*jsonWebKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true
+		}
+	}
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// fetchKeys fetches the key set and returns the signature keys by key ID. The invalid keys are
+// skipped so that a single malformed key does not prevent the other keys from being used.
+func (s *JWKS) fetchKeys(ctx context.Context) (map[string]*jsonWebKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %s", resp.Status)
+	}
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+	keys := make(map[string]*jsonWebKey, len(set.Keys))
+	for _, raw := range set.Keys {
+		k := new(jsonWebKey)
+		if err := json.Unmarshal(raw, k); err != nil {
+			continue
+		}
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil || key == nil {
+			continue
+		}
+		k.key = key
+		keys[k.Kid] = k
+	}
+	return keys, nil
+}
+
+// publicKey decodes the public key, it returns nil if the key type is not supported.
+func (k *jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("invalid EC point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+// decodeBigInt decodes a base64url encoded big-endian unsigned integer.
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("missing key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,308 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jwtpkg "github.com/golang-jwt/jwt/v4"
+	"github.com/shogo82148/shogoa"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func signToken(t *testing.T, method jwtpkg.SigningMethod, key any, kid string, claims jwtpkg.MapClaims) string {
+	t.Helper()
+	token := jwtpkg.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func runMiddleware(middleware shogoa.Middleware, token string) error {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rw := httptest.NewRecorder()
+	ctx := shogoa.NewContext(rw, req, nil)
+	handler := middleware(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if ContextJWT(ctx) == nil {
+			panic("token is nil")
+		}
+		return nil
+	})
+	return handler(ctx, rw, req)
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "alg": "RS256", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes())},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edPub)},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": b64(rsaKey.N.Bytes()), "e": "AQAB"},
+	}
+	var fetches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer ts.Close()
+
+	jwks := NewJWKS(ts.URL)
+	middleware := New(jwks, nil, &shogoa.JWTSecurity{In: shogoa.LocHeader, Name: "Authorization"})
+	claims := jwtpkg.MapClaims{"scopes": "scope1"}
+
+	t.Run("valid tokens", func(t *testing.T) {
+		tokens := map[string]string{
+			"rsa":   signToken(t, jwtpkg.SigningMethodRS256, rsaKey, "rsa", claims),
+			"ecdsa": signToken(t, jwtpkg.SigningMethodES256, ecKey, "ec", claims),
+			"eddsa": signToken(t, jwtpkg.SigningMethodEdDSA, edKey, "ed", claims),
+		}
+		for name, token := range tokens {
+			if err := runMiddleware(middleware, token); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+		}
+		if n := fetches.Load(); n != 1 {
+			t.Errorf("the key set was fetched %d times, expected the keys to be cached", n)
+		}
+	})
+
+	t.Run("invalid tokens", func(t *testing.T) {
+		tokens := map[string]string{
+			"wrong key":       signToken(t, jwtpkg.SigningMethodRS256, rsaKey, "ec", claims),
+			"wrong algorithm": signToken(t, jwtpkg.SigningMethodRS384, rsaKey, "rsa", claims),
+			"encryption key":  signToken(t, jwtpkg.SigningMethodRS256, rsaKey, "enc", claims),
+		}
+		for name, token := range tokens {
+			if err := runMiddleware(middleware, token); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+
+	t.Run("unknown key ID", func(t *testing.T) {
+		fetches.Store(0)
+		jwks.MinRefreshInterval = time.Hour
+		jwks.fetchedAt = time.Time{}
+		token := signToken(t, jwtpkg.SigningMethodRS256, rsaKey, "unknown", claims)
+		for range 3 {
+			if err := runMiddleware(middleware, token); err == nil {
+				t.Error("expected an error")
+			}
+		}
+		if n := fetches.Load(); n != 1 {
+			t.Errorf("the key set was fetched %d times, expected refreshes to be rate-limited", n)
+		}
+	})
+}
+
+func TestJWKS_Refresh(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []any{
+		map[string]string{"kty": "RSA", "kid": "invalid", "n": "", "e": "AQAB"},
+		map[string]any{"kty": "EC", "kid": "malformed", "crv": "P-256", "x": 1, "y": 2},
+		map[string]string{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": "AQAB"},
+	}
+	var fetches atomic.Int32
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-block
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer ts.Close()
+
+	jwks := NewJWKS(ts.URL)
+	middleware := New(jwks, nil, &shogoa.JWTSecurity{In: shogoa.LocHeader, Name: "Authorization"})
+	claims := jwtpkg.MapClaims{"scopes": "scope1"}
+	known := signToken(t, jwtpkg.SigningMethodRS256, rsaKey, "rsa", claims)
+	unknown := signToken(t, jwtpkg.SigningMethodRS256, rsaKey, "unknown", claims)
+
+	t.Run("invalid keys are skipped", func(t *testing.T) {
+		if err := runMiddleware(middleware, known); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("fetches do not block known keys", func(t *testing.T) {
+		jwks.MinRefreshInterval = time.Hour
+		jwks.fetchedAt = time.Time{}
+		errs := make(chan error, 3)
+		for range cap(errs) {
+			go func() { errs <- runMiddleware(middleware, unknown) }()
+		}
+		for fetches.Load() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		if err := runMiddleware(middleware, known); err != nil {
+			t.Error(err)
+		}
+		close(block)
+		for range cap(errs) {
+			if err := <-errs; err == nil {
+				t.Error("expected an error")
+			}
+		}
+		if n := fetches.Load(); n != 2 {
+			t.Errorf("the key set was fetched %d times, expected a single refresh", n)
+		}
+	})
+}
+
+func TestJWKS_FetchFailure(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": "AQAB"},
+	}
+	var fetches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer ts.Close()
+
+	jwks := NewJWKS(ts.URL)
+	jwks.MinRefreshInterval = time.Hour
+	middleware := New(jwks, nil, &shogoa.JWTSecurity{In: shogoa.LocHeader, Name: "Authorization"})
+	token := signToken(t, jwtpkg.SigningMethodRS256, rsaKey, "rsa", jwtpkg.MapClaims{"scopes": "scope1"})
+
+	t.Run("first fetch fails", func(t *testing.T) {
+		if err := runMiddleware(middleware, token); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("next request fetches the key set again", func(t *testing.T) {
+		if err := runMiddleware(middleware, token); err != nil {
+			t.Error(err)
+		}
+		if n := fetches.Load(); n != 2 {
+			t.Errorf("the key set was fetched %d times, want 2", n)
+		}
+	})
+
+	t.Run("canceled requests do not cancel the fetch", func(t *testing.T) {
+		jwks.fetchedAt = time.Time{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := jwks.key(ctx, "other"); err == nil {
+			t.Error("expected an error")
+		}
+		jwks.mu.Lock()
+		f := jwks.fetch
+		jwks.mu.Unlock()
+		if f != nil {
+			<-f.done
+		}
+		if f != nil && f.err != nil {
+			t.Errorf("unexpected fetch error: %v", f.err)
+		}
+		if n := fetches.Load(); n != 3 {
+			t.Errorf("the key set was fetched %d times, want 3", n)
+		}
+	})
+}
+
+func TestMiddleware_EdDSA(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheme := &shogoa.JWTSecurity{In: shogoa.LocHeader, Name: "Authorization"}
+	token := signToken(t, jwtpkg.SigningMethodEdDSA, key, "", jwtpkg.MapClaims{"scopes": "scope1"})
+
+	if err := runMiddleware(New([]ed25519.PublicKey{otherPub, pub}, nil, scheme), token); err != nil {
+		t.Error(err)
+	}
+	if err := runMiddleware(New(otherPub, nil, scheme), token); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestMiddleware_Claims(t *testing.T) {
+	scheme := &shogoa.JWTSecurity{In: shogoa.LocHeader, Name: "Authorization"}
+	now := time.Now()
+	sign := func(claims jwtpkg.MapClaims) string {
+		return signToken(t, jwtpkg.SigningMethodHS256, []byte("keys"), "", claims)
+	}
+	opts := []Option{Issuer("https://issuer.example.com/"), Audience("api"), RequiredClaims("sub")}
+	valid := jwtpkg.MapClaims{"iss": "https://issuer.example.com/", "aud": []string{"other", "api"}, "sub": "alice"}
+	withClaim := func(k string, v any) jwtpkg.MapClaims {
+		c := jwtpkg.MapClaims{}
+		for k, v := range valid {
+			c[k] = v
+		}
+		c[k] = v
+		return c
+	}
+
+	cases := map[string]struct {
+		claims jwtpkg.MapClaims
+		opts   []Option
+		ok     bool
+	}{
+		"valid":                      {valid, opts, true},
+		"unexpected issuer":          {withClaim("iss", "https://evil.example.com/"), opts, false},
+		"unexpected audience":        {withClaim("aud", "other"), opts, false},
+		"missing required claim":     {withClaim("sub", nil), opts, false},
+		"expired":                    {withClaim("exp", now.Add(-10*time.Second).Unix()), opts, false},
+		"expired within clock skew":  {withClaim("exp", now.Add(-10*time.Second).Unix()), append(opts, ClockSkew(time.Minute)), true},
+		"not before":                 {withClaim("nbf", now.Add(10*time.Second).Unix()), opts, false},
+		"not before within skew":     {withClaim("nbf", now.Add(10*time.Second).Unix()), append(opts, ClockSkew(time.Minute)), true},
+		"no policy":                  {jwtpkg.MapClaims{}, nil, true},
+		"expired without any policy": {jwtpkg.MapClaims{"exp": now.Add(-time.Second).Unix()}, nil, false},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := runMiddleware(New("keys", nil, scheme, c.opts...), sign(c.claims))
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
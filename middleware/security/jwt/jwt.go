@@ -3,6 +3,7 @@ package jwt
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"fmt"
 	"net/http"
@@ -25,7 +26,9 @@ import (
 //     against the scopes presented by the JWT in the claim "scope", or if
 //     that's not defined, "scopes".
 //
-// The `exp` (expiration) and `nbf` (not before) date checks are validated by the JWT library.
+// The `exp` (expiration), `nbf` (not before) and `iat` (issued at) date checks are always
+// validated. The options may relax them with ClockSkew and add more claim checks, see Issuer,
+// Audience and RequiredClaims.
 //
 // validationKeys can be one of these:
 //
@@ -33,11 +36,17 @@ import (
 //   - a []byte (for HMAC)
 //   - an rsa.PublicKey
 //   - an ecdsa.PublicKey
+//   - an ed25519.PublicKey
 //   - a slice of any of the above
+//   - a KeyProvider, e.g. a JWKS
 //
 // The type of the keys determine the algorithm that will be used to do the check.  The goal of
 // having lists of keys is to allow for key rotation, still check the previous keys until rotation
-// has been completed.
+// has been completed. A KeyProvider resolves the key of each token instead, JWKS fetches the keys
+// from the JSON Web Key Set of the token issuer:
+//
+//	jwks := jwt.NewJWKS("https://auth.example.com/.well-known/jwks.json")
+//	app.UseJWT(jwt.New(jwks, nil, app.NewJWTSecurity(), jwt.Issuer("https://auth.example.com/"), jwt.Audience("my-api")))
 //
 // You can define an optional function to do additional validations on the token once the signature
 // and the claims requirements are proven to be valid.  Example:
@@ -53,11 +62,17 @@ import (
 // defined in the design, e.g.:
 //
 //	app.UseJWT(jwt.New("secret", validationHandler, app.NewJWTSecurity()))
-func New(validationKeys any, validationFunc shogoa.Middleware, scheme *shogoa.JWTSecurity) shogoa.Middleware {
+func New(validationKeys any, validationFunc shogoa.Middleware, scheme *shogoa.JWTSecurity, opts ...Option) shogoa.Middleware {
 	var rsaKeys []*rsa.PublicKey
 	var hmacKeys [][]byte
 
-	rsaKeys, ecdsaKeys, hmacKeys := partitionKeys(validationKeys)
+	provider, _ := validationKeys.(KeyProvider)
+	rsaKeys, ecdsaKeys, ed25519Keys, hmacKeys := partitionKeys(validationKeys)
+
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
 
 	return func(nextHandler shogoa.Handler) shogoa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -83,7 +98,12 @@ func New(validationKeys any, validationFunc shogoa.Middleware, scheme *shogoa.JW
 				validated = false
 			)
 
-			if len(rsaKeys) > 0 {
+			if provider != nil {
+				token, err = validateProvider(ctx, provider, incomingToken)
+				validated = err == nil
+			}
+
+			if !validated && len(rsaKeys) > 0 {
 				token, err = validateRSAKeys(rsaKeys, "RS", incomingToken)
 				validated = err == nil
 			}
@@ -93,11 +113,19 @@ func New(validationKeys any, validationFunc shogoa.Middleware, scheme *shogoa.JW
 				validated = err == nil
 			}
 
+			if !validated && len(ed25519Keys) > 0 {
+				token, err = validateEd25519Keys(ed25519Keys, incomingToken)
+				validated = err == nil
+			}
+
 			if !validated && len(hmacKeys) > 0 {
 				token, err = validateHMACKeys(hmacKeys, "HS", incomingToken)
 				//validated = err == nil
 			}
 
+			if err == nil {
+				err = o.validateClaims(token)
+			}
 			if err != nil {
 				return ErrJWTError(fmt.Sprintf("JWT validation failed: %s", err))
 			}
@@ -197,12 +225,17 @@ func parseClaimScopes(token *jwt.Token) (map[string]bool, []string, error) {
 // fails during processing.
 var ErrJWTError = shogoa.NewErrorClass("jwt_security_error", 401)
 
+// parser parses tokens and verifies their signature, the claims are checked by the middleware
+// according to its options.
+var parser = jwt.NewParser(jwt.WithoutClaimsValidation())
+
 // partitionKeys sorts keys by their type.
-func partitionKeys(k any) ([]*rsa.PublicKey, []*ecdsa.PublicKey, [][]byte) {
+func partitionKeys(k any) ([]*rsa.PublicKey, []*ecdsa.PublicKey, []ed25519.PublicKey, [][]byte) {
 	var (
-		rsaKeys   []*rsa.PublicKey
-		ecdsaKeys []*ecdsa.PublicKey
-		hmacKeys  [][]byte
+		rsaKeys     []*rsa.PublicKey
+		ecdsaKeys   []*ecdsa.PublicKey
+		ed25519Keys []ed25519.PublicKey
+		hmacKeys    [][]byte
 	)
 
 	switch typed := k.(type) {
@@ -224,14 +257,24 @@ func partitionKeys(k any) ([]*rsa.PublicKey, []*ecdsa.PublicKey, [][]byte) {
 		ecdsaKeys = append(ecdsaKeys, typed)
 	case []*ecdsa.PublicKey:
 		ecdsaKeys = typed
+	case ed25519.PublicKey:
+		ed25519Keys = append(ed25519Keys, typed)
+	case []ed25519.PublicKey:
+		ed25519Keys = typed
 	}
 
-	return rsaKeys, ecdsaKeys, hmacKeys
+	return rsaKeys, ecdsaKeys, ed25519Keys, hmacKeys
+}
+
+func validateProvider(ctx context.Context, provider KeyProvider, incomingToken string) (*jwt.Token, error) {
+	return parser.Parse(incomingToken, func(token *jwt.Token) (any, error) {
+		return provider.Key(ctx, token)
+	})
 }
 
 func validateRSAKeys(rsaKeys []*rsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, pubkey := range rsaKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (any, error) {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (any, error) {
 			if !strings.HasPrefix(token.Method.Alg(), algo) {
 				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
 			}
@@ -246,7 +289,7 @@ func validateRSAKeys(rsaKeys []*rsa.PublicKey, algo, incomingToken string) (toke
 
 func validateECDSAKeys(ecdsaKeys []*ecdsa.PublicKey, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, pubkey := range ecdsaKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (any, error) {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (any, error) {
 			if !strings.HasPrefix(token.Method.Alg(), algo) {
 				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
 			}
@@ -259,9 +302,24 @@ func validateECDSAKeys(ecdsaKeys []*ecdsa.PublicKey, algo, incomingToken string)
 	return
 }
 
+func validateEd25519Keys(ed25519Keys []ed25519.PublicKey, incomingToken string) (token *jwt.Token, err error) {
+	for _, pubkey := range ed25519Keys {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (any, error) {
+			if token.Method.Alg() != jwt.SigningMethodEdDSA.Alg() {
+				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
+			}
+			return pubkey, nil
+		})
+		if err == nil {
+			return
+		}
+	}
+	return
+}
+
 func validateHMACKeys(hmacKeys [][]byte, algo, incomingToken string) (token *jwt.Token, err error) {
 	for _, key := range hmacKeys {
-		token, err = jwt.Parse(incomingToken, func(token *jwt.Token) (any, error) {
+		token, err = parser.Parse(incomingToken, func(token *jwt.Token) (any, error) {
 			if !strings.HasPrefix(token.Method.Alg(), algo) {
 				return nil, ErrJWTError(fmt.Sprintf("Unexpected signing method: %v", token.Header["alg"]))
 			}
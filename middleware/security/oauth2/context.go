@@ -0,0 +1,22 @@
+package oauth2
+
+import "context"
+
+type contextKey struct{}
+
+var introspectionKey = contextKey{}
+
+// WithIntrospection creates a child context containing the given token introspection.
+func WithIntrospection(ctx context.Context, i *Introspection) context.Context {
+	return context.WithValue(ctx, introspectionKey, i)
+}
+
+// ContextIntrospection retrieves the introspection of the access token from a `context` that went
+// through our security middleware.
+func ContextIntrospection(ctx context.Context) *Introspection {
+	i, ok := ctx.Value(introspectionKey).(*Introspection)
+	if !ok {
+		return nil
+	}
+	return i
+}
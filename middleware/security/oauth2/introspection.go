@@ -0,0 +1,169 @@
+package oauth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Introspection is the response of an OAuth2 token introspection as defined by RFC 7662.
+type Introspection struct {
+	// Active indicates whether the token is currently active.
+	Active bool `json:"active"`
+	// Scope is the space-separated list of the scopes associated with the token.
+	Scope string `json:"scope,omitempty"`
+	// ClientID is the identifier of the client that requested the token.
+	ClientID string `json:"client_id,omitempty"`
+	// Username is the human-readable identifier of the resource owner.
+	Username string `json:"username,omitempty"`
+	// TokenType is the type of the token, e.g. "Bearer".
+	TokenType string `json:"token_type,omitempty"`
+	// ExpiresAt is the expiration time of the token in seconds since the epoch.
+	ExpiresAt int64 `json:"exp,omitempty"`
+	// IssuedAt is the time the token was issued in seconds since the epoch.
+	IssuedAt int64 `json:"iat,omitempty"`
+	// NotBefore is the time before which the token must not be used in seconds since the epoch.
+	NotBefore int64 `json:"nbf,omitempty"`
+	// Subject is the subject of the token, usually the resource owner identifier.
+	Subject string `json:"sub,omitempty"`
+	// Issuer is the issuer of the token.
+	Issuer string `json:"iss,omitempty"`
+	// JWTID is the identifier of the token.
+	JWTID string `json:"jti,omitempty"`
+}
+
+// Scopes returns the scopes associated with the token.
+func (i *Introspection) Scopes() []string {
+	return strings.Fields(i.Scope)
+}
+
+// valid returns true if the token is active at the given time.
+func (i *Introspection) valid(now time.Time) bool {
+	if !i.Active {
+		return false
+	}
+	if i.ExpiresAt != 0 && now.Unix() >= i.ExpiresAt {
+		return false
+	}
+	if i.NotBefore != 0 && now.Unix() < i.NotBefore {
+		return false
+	}
+	return true
+}
+
+// Introspector is the interface implemented by the services that validate access tokens.
+// Introspect returns an inactive introspection if the token is invalid and an error only if the
+// token could not be checked.
+type Introspector interface {
+	Introspect(ctx context.Context, token string) (*Introspection, error)
+}
+
+// HTTPIntrospector is an Introspector that calls a RFC 7662 token introspection endpoint.
+type HTTPIntrospector struct {
+	// Endpoint is the URL of the introspection endpoint.
+	Endpoint string
+	// ClientID and ClientSecret are the credentials used to authenticate with the endpoint
+	// using HTTP basic authentication. No authentication is done if ClientID is empty.
+	ClientID     string
+	ClientSecret string
+	// Client is the HTTP client used to make requests, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// NewHTTPIntrospector returns an introspector that calls the given introspection endpoint
+// authenticating with the given client credentials.
+func NewHTTPIntrospector(endpoint, clientID, clientSecret string) *HTTPIntrospector {
+	return &HTTPIntrospector{
+		Endpoint:     endpoint,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}
+}
+
+// Introspect sends the token to the introspection endpoint and decodes its response.
+func (i *HTTPIntrospector) Introspect(ctx context.Context, token string) (*Introspection, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.ClientID), url.QueryEscape(i.ClientSecret))
+	}
+	client := i.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token introspection failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token introspection failed: unexpected status %s", resp.Status)
+	}
+	var res Introspection
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("token introspection failed: %w", err)
+	}
+	return &res, nil
+}
+
+// maxCacheEntries is the number of introspections the cache holds before it evicts entries.
+const maxCacheEntries = 10000
+
+// cache keeps the introspections of active tokens until they expire. Tokens are indexed by their
+// SHA-256 hash so that the cache does not hold credentials.
+type cache struct {
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*Introspection
+}
+
+func newCache() *cache {
+	return &cache{entries: make(map[[sha256.Size]byte]*Introspection)}
+}
+
+// get returns the cached introspection of token if it is still valid.
+func (c *cache) get(token string, now time.Time) (*Introspection, bool) {
+	key := sha256.Sum256([]byte(token))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !i.valid(now) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return i, true
+}
+
+// add caches the introspection of token if it is active and has an expiration time.
+func (c *cache) add(token string, i *Introspection, now time.Time) {
+	if i.ExpiresAt == 0 || !i.valid(now) {
+		return
+	}
+	key := sha256.Sum256([]byte(token))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		for k, e := range c.entries {
+			if !e.valid(now) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = i
+}
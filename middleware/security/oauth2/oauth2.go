@@ -0,0 +1,127 @@
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+)
+
+var (
+	// ErrInvalidToken is the error returned by this middleware when the request does not carry
+	// a bearer token or when the token is not active.
+	ErrInvalidToken = shogoa.NewErrorClass("invalid_token", 401)
+
+	// ErrInsufficientScope is the error returned by this middleware when the token does not grant
+	// the scopes required by the action.
+	ErrInsufficientScope = shogoa.NewErrorClass("insufficient_scope", 403)
+
+	// ErrIntrospectionFailed is the error returned by this middleware when the token cannot be
+	// introspected, e.g. because the authorization server is unavailable.
+	ErrIntrospectionFailed = shogoa.NewErrorClass("introspection_failed", 503)
+)
+
+// New returns a middleware to be used with the OAuth2Security DSL definitions of shogoa. The
+// middleware acts as an OAuth2 resource server: it validates the bearer access token of the
+// requests with the given introspector and ensures the token grants the scopes required by the
+// action.
+//
+// The steps taken by the middleware are:
+//
+//  1. Extract the "Bearer" token from the Authorization header
+//  2. Introspect the token unless a previous introspection of the same token is cached, active
+//     tokens are cached until they expire
+//  3. If scopes are defined in the design for the action, validate them against the scopes of
+//     the token
+//
+// Failures produce 401 or 403 errors and set the WWW-Authenticate header as described in RFC
+// 6750, using the realm of the scheme. Introspection failures produce 503 errors. The
+// introspection of the token is available to the handlers via ContextIntrospection.
+// validationFunc is an optional middleware that performs additional validations once the token is
+// proven to be valid.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//	introspector := oauth2.NewHTTPIntrospector("https://auth.example.com/introspect", "id", "secret")
+//	app.UseOAuth2Middleware(service, oauth2.New(introspector, nil, app.NewOAuth2Security()))
+func New(introspector Introspector, validationFunc shogoa.Middleware, scheme *shogoa.OAuth2Security) shogoa.Middleware {
+	c := newCache()
+
+	return func(nextHandler shogoa.Handler) shogoa.Handler {
+		if validationFunc != nil {
+			nextHandler = validationFunc(nextHandler)
+		}
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			token, ok := extractToken(req)
+			if !ok {
+				rw.Header().Set("WWW-Authenticate", challenge(scheme.Realm, "", ""))
+				return ErrInvalidToken("missing bearer token")
+			}
+
+			t := time.Now()
+			introspection, ok := c.get(token, t)
+			if !ok {
+				var err error
+				introspection, err = introspector.Introspect(ctx, token)
+				if err != nil {
+					rw.Header().Set("WWW-Authenticate", challenge(scheme.Realm, "", ""))
+					return ErrIntrospectionFailed(err)
+				}
+				if !introspection.valid(t) {
+					rw.Header().Set("WWW-Authenticate", challenge(scheme.Realm, "invalid_token", "the access token is not active"))
+					return ErrInvalidToken("the access token is not active")
+				}
+				c.add(token, introspection, t)
+			}
+
+			requiredScopes := shogoa.ContextRequiredScopes(ctx)
+			scopes := introspection.Scopes()
+			for _, scope := range requiredScopes {
+				if !slices.Contains(scopes, scope) {
+					rw.Header().Set("WWW-Authenticate", challenge(scheme.Realm, "insufficient_scope", "", "scope", strings.Join(requiredScopes, " ")))
+					return ErrInsufficientScope("the access token does not grant the required scopes",
+						"required", requiredScopes, "scopes", scopes)
+				}
+			}
+
+			return nextHandler(WithIntrospection(ctx, introspection), rw, req)
+		}
+	}
+}
+
+// extractToken returns the bearer token of the Authorization header.
+func extractToken(req *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// challenge builds a Bearer WWW-Authenticate header value with the given realm, error code,
+// description and additional key/value pairs. Empty values are omitted.
+func challenge(realm, code, description string, keyvals ...string) string {
+	var params []string
+	if realm != "" {
+		params = append(params, fmt.Sprintf("realm=%q", realm))
+	}
+	if code != "" {
+		params = append(params, fmt.Sprintf("error=%q", code))
+	}
+	if description != "" {
+		params = append(params, fmt.Sprintf("error_description=%q", description))
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		params = append(params, fmt.Sprintf("%s=%q", keyvals[i], keyvals[i+1]))
+	}
+	if len(params) == 0 {
+		return "Bearer"
+	}
+	return "Bearer " + strings.Join(params, ", ")
+}
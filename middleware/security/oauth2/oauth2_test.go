@@ -0,0 +1,190 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shogo82148/shogoa"
+)
+
+func newIntrospectionServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || r.PostFormValue("token_type_hint") != "access_token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var res Introspection
+		switch r.PostFormValue("token") {
+		case "valid":
+			res = Introspection{Active: true, Scope: "read write", Subject: "alice", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+		case "expired":
+			res = Introspection{Active: true, Scope: "read", ExpiresAt: time.Now().Add(-time.Minute).Unix()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestHTTPIntrospector(t *testing.T) {
+	var calls atomic.Int32
+	ts := newIntrospectionServer(t, &calls)
+
+	t.Run("active token", func(t *testing.T) {
+		i, err := NewHTTPIntrospector(ts.URL, "client", "secret").Introspect(context.Background(), "valid")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !i.Active || i.Subject != "alice" || len(i.Scopes()) != 2 {
+			t.Errorf("unexpected introspection: %+v", i)
+		}
+	})
+
+	t.Run("inactive token", func(t *testing.T) {
+		i, err := NewHTTPIntrospector(ts.URL, "client", "secret").Introspect(context.Background(), "unknown")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.Active {
+			t.Errorf("unexpected introspection: %+v", i)
+		}
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		_, err := NewHTTPIntrospector(ts.URL, "client", "wrong").Introspect(context.Background(), "valid")
+		if err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	var calls atomic.Int32
+	ts := newIntrospectionServer(t, &calls)
+	var validations atomic.Int32
+	validation := func(h shogoa.Handler) shogoa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			validations.Add(1)
+			return h(ctx, rw, req)
+		}
+	}
+	scheme := &shogoa.OAuth2Security{Realm: "api"}
+	middleware := New(NewHTTPIntrospector(ts.URL, "client", "secret"), validation, scheme)
+
+	serve := func(authorization string, scopes ...string) (*httptest.ResponseRecorder, *Introspection, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rw := httptest.NewRecorder()
+		ctx := shogoa.WithRequiredScopes(shogoa.NewContext(rw, req, nil), scopes)
+		var got *Introspection
+		handler := middleware(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			got = ContextIntrospection(ctx)
+			return nil
+		})
+		err := handler(ctx, rw, req)
+		return rw, got, err
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		calls.Store(0)
+		validations.Store(0)
+		for range 2 {
+			_, i, err := serve("Bearer valid", "read")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i == nil || i.Subject != "alice" {
+				t.Errorf("unexpected introspection: %+v", i)
+			}
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("token was introspected %d times, expected the introspection to be cached", n)
+		}
+		if n := validations.Load(); n != 2 {
+			t.Errorf("validation ran %d times for 2 requests", n)
+		}
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		rw, _, err := serve("")
+		var serr shogoa.ServiceError
+		if !errors.As(err, &serr) || serr.ResponseStatus() != 401 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if h := rw.Header().Get("WWW-Authenticate"); h != `Bearer realm="api"` {
+			t.Errorf("unexpected WWW-Authenticate header: %q", h)
+		}
+	})
+
+	for _, token := range []string{"unknown", "expired"} {
+		t.Run(token+" token", func(t *testing.T) {
+			rw, _, err := serve("Bearer " + token)
+			if shogoa.ErrorCode(err) != "invalid_token" {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if h := rw.Header().Get("WWW-Authenticate"); h != `Bearer realm="api", error="invalid_token", error_description="the access token is not active"` {
+				t.Errorf("unexpected WWW-Authenticate header: %q", h)
+			}
+		})
+	}
+
+	t.Run("insufficient scope", func(t *testing.T) {
+		rw, _, err := serve("Bearer valid", "read", "admin")
+		var serr shogoa.ServiceError
+		if !errors.As(err, &serr) || serr.ResponseStatus() != 403 || shogoa.ErrorCode(err) != "insufficient_scope" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if h := rw.Header().Get("WWW-Authenticate"); h != `Bearer realm="api", error="insufficient_scope", scope="read admin"` {
+			t.Errorf("unexpected WWW-Authenticate header: %q", h)
+		}
+	})
+
+	t.Run("introspection failure", func(t *testing.T) {
+		m := New(NewHTTPIntrospector(ts.URL, "client", "wrong"), nil, scheme)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer other")
+		rw := httptest.NewRecorder()
+		handler := m(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			panic("should not be called")
+		})
+		err := handler(shogoa.NewContext(rw, req, nil), rw, req)
+		var serr shogoa.ServiceError
+		if !errors.As(err, &serr) || serr.ResponseStatus() != 503 || shogoa.ErrorCode(err) != "introspection_failed" {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if h := rw.Header().Get("WWW-Authenticate"); h != `Bearer realm="api"` {
+			t.Errorf("unexpected WWW-Authenticate header: %q", h)
+		}
+	})
+}
+
+func TestCache(t *testing.T) {
+	c := newCache()
+	t0 := time.Unix(1000, 0)
+	c.add("token", &Introspection{Active: true, ExpiresAt: 1060}, t0)
+	c.add("noexp", &Introspection{Active: true}, t0)
+	if _, ok := c.get("token", t0.Add(30*time.Second)); !ok {
+		t.Error("expected a cache hit before expiry")
+	}
+	if _, ok := c.get("token", t0.Add(time.Minute)); ok {
+		t.Error("expected a cache miss after expiry")
+	}
+	if _, ok := c.get("noexp", t0); ok {
+		t.Error("tokens without expiration should not be cached")
+	}
+}
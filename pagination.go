@@ -0,0 +1,22 @@
+package shogoa
+
+import (
+	"context"
+	"net/url"
+)
+
+// SetNextPageLink adds a Link header with the "next" relation to the response, see RFC 8288. The
+// link target is the request URL with the query string parameter named param set to cursor, the
+// other parameters such as the page size are preserved. No header is added if cursor is empty,
+// i.e. on the last page.
+func SetNextPageLink(ctx context.Context, param, cursor string) {
+	req, resp := ContextRequest(ctx), ContextResponse(ctx)
+	if cursor == "" || req == nil || req.Request == nil || resp == nil {
+		return
+	}
+	u := url.URL{Path: req.URL.Path, RawPath: req.URL.RawPath}
+	query := req.URL.Query()
+	query.Set(param, cursor)
+	u.RawQuery = query.Encode()
+	resp.Header().Add("Link", "<"+u.String()+`>; rel="next"`)
+}
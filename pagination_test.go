@@ -0,0 +1,33 @@
+package shogoa_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shogo82148/shogoa"
+)
+
+func TestSetNextPageLink(t *testing.T) {
+	cases := []struct {
+		name   string
+		url    string
+		cursor string
+		want   []string
+	}{
+		{"first page", "/bottles?limit=10", "abc", []string{`</bottles?cursor=abc&limit=10>; rel="next"`}},
+		{"next page", "/bottles?cursor=abc&limit=10", "d/f", []string{`</bottles?cursor=d%2Ff&limit=10>; rel="next"`}},
+		{"last page", "/bottles?cursor=abc", "", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", c.url, nil)
+			ctx := shogoa.NewContext(rw, req, nil)
+			shogoa.SetNextPageLink(ctx, "cursor", c.cursor)
+			got := rw.Header().Values("Link")
+			if len(got) != len(c.want) || (len(got) > 0 && got[0] != c.want[0]) {
+				t.Errorf("unexpected Link header: want %q, got %q", c.want, got)
+			}
+		})
+	}
+}
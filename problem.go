@@ -0,0 +1,212 @@
+package shogoa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ProblemMediaIdentifier is the media type identifier used for problem details responses as
+// defined by RFC 9457 (formerly RFC 7807).
+const ProblemMediaIdentifier = "application/problem+json"
+
+// ProblemTypeURI returns the problem type URI of errors with the given error class code. Override
+// it to point clients to human-readable documentation of the error classes.
+var ProblemTypeURI = func(code string) string {
+	return "urn:shogoa:error:" + code
+}
+
+// ProblemDetails is the RFC 9457 representation of an error. It implements ServiceError so that
+// clients may return decoded problem details as errors.
+type ProblemDetails struct {
+	// Type is a URI reference that identifies the problem type.
+	Type string
+	// Title is a short, human-readable summary of the problem type.
+	Title string
+	// Status is the HTTP status code of the response.
+	Status int
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string
+	// Instance is a URI reference that identifies the specific occurrence of the problem.
+	Instance string
+	// Errors lists the individual problems when several errors were merged together.
+	Errors []*ProblemDetails
+	// Extensions contains the extension members of the problem details, e.g. the error ID and
+	// the metadata of the error.
+	Extensions map[string]any
+}
+
+// problemMembers lists the members defined by RFC 9457 and shogoa, extensions may not override them.
+var problemMembers = map[string]bool{
+	"type": true, "title": true, "status": true, "detail": true, "instance": true, "errors": true,
+}
+
+// NewProblemDetails converts err into problem details. The type of the problem is derived from
+// the error class code. The code and the ID of the error are set in the "code" and "id" extension
+// members, the metadata of the error becomes extension members and errors combined with
+// MergeErrors are listed in the "errors" member. Errors that do not implement ServiceError produce
+// internal errors.
+func NewProblemDetails(err error) *ProblemDetails {
+	var p *ProblemDetails
+	if errors.As(err, &p) {
+		return p
+	}
+	var resp *ErrorResponse
+	if !errors.As(err, &resp) {
+		status := http.StatusInternalServerError
+		var serr ServiceError
+		if errors.As(err, &serr) {
+			status = serr.ResponseStatus()
+			resp = &ErrorResponse{ID: serr.Token(), Code: "error", Status: status, Detail: err.Error()}
+		} else {
+			resp = &ErrorResponse{Code: "internal", Status: status, Detail: err.Error()}
+		}
+	}
+	p = newProblem(resp)
+	if errs := resp.Errors(); len(errs) > 1 {
+		p.Errors = make([]*ProblemDetails, len(errs))
+		for i, e := range errs {
+			p.Errors[i] = newProblem(e)
+		}
+	}
+	return p
+}
+
+// newProblem converts a single error response into problem details.
+func newProblem(e *ErrorResponse) *ProblemDetails {
+	p := &ProblemDetails{
+		Type:       ProblemTypeURI(e.Code),
+		Title:      http.StatusText(e.Status),
+		Status:     e.Status,
+		Detail:     e.Detail,
+		Extensions: make(map[string]any, len(e.Meta)+2),
+	}
+	for k, v := range e.Meta {
+		if !problemMembers[k] {
+			p.Extensions[k] = v
+		}
+	}
+	if e.ID != "" {
+		p.Extensions["id"] = e.ID
+	}
+	if e.Code != "" {
+		p.Extensions["code"] = e.Code
+	}
+	return p
+}
+
+// Error returns the problem details.
+func (p *ProblemDetails) Error() string {
+	msg := fmt.Sprintf("%d %s: %s", p.Status, p.Type, p.Detail)
+	if id := p.Token(); id != "" {
+		msg = fmt.Sprintf("[%s] %s", id, msg)
+	}
+	return msg
+}
+
+// ResponseStatus is the status used to build responses.
+func (p *ProblemDetails) ResponseStatus() int { return p.Status }
+
+// Token is the unique error occurrence identifier stored in the "id" extension member if any.
+func (p *ProblemDetails) Token() string {
+	id, _ := p.Extensions["id"].(string)
+	return id
+}
+
+// MarshalJSON encodes the problem details as a JSON object, extension members are encoded
+// alongside the members defined by RFC 9457.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+6)
+	for k, v := range p.Extensions {
+		if !problemMembers[k] {
+			m[k] = v
+		}
+	}
+	typ := p.Type
+	if typ == "" {
+		typ = "about:blank"
+	}
+	m["type"] = typ
+	if p.Title != "" {
+		m["title"] = p.Title
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	if len(p.Errors) > 0 {
+		m["errors"] = p.Errors
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes a JSON problem details object, unknown members are stored in Extensions.
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = ProblemDetails{}
+	fields := map[string]any{
+		"type":     &p.Type,
+		"title":    &p.Title,
+		"status":   &p.Status,
+		"detail":   &p.Detail,
+		"instance": &p.Instance,
+		"errors":   &p.Errors,
+	}
+	for k, v := range raw {
+		if f, ok := fields[k]; ok {
+			if err := json.Unmarshal(v, f); err != nil {
+				return fmt.Errorf("invalid problem details member %#v: %w", k, err)
+			}
+			continue
+		}
+		var ext any
+		if err := json.Unmarshal(v, &ext); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		p.Extensions[k] = ext
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	return nil
+}
+
+// SendProblem sends a problem details response with the given status code. The problem details
+// are always encoded with the JSON encoder of the service regardless of the request Accept header.
+func (service *Service) SendProblem(ctx context.Context, code int, p *ProblemDetails) error {
+	r := ContextResponse(ctx)
+	if r == nil {
+		return fmt.Errorf("no response data in context")
+	}
+	r.Header().Set("Content-Type", ProblemMediaIdentifier)
+	r.WriteHeader(code)
+	if enc := jsonEncoder(service); enc != nil {
+		return enc.Encode(p, r)
+	}
+	return json.NewEncoder(r).Encode(p)
+}
+
+// AcceptsProblemDetails returns true if the given Accept header value explicitly lists the
+// problem details media type with a quality value at least as high as the shogoa error media type.
+func AcceptsProblemDetails(accept string) bool {
+	ranges := parseAccept(accept)
+	q, spec := acceptQuality(ranges, ProblemMediaIdentifier)
+	if spec != specExact || q <= 0 {
+		return false
+	}
+	eq, _ := acceptQuality(ranges, ErrorMediaIdentifier)
+	return q >= eq
+}
@@ -0,0 +1,138 @@
+package shogoa
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewProblemDetails(t *testing.T) {
+	t.Run("error response", func(t *testing.T) {
+		err := ErrBadRequest("invalid bottle", "bottle", 42, "status", "ignored")
+		p := NewProblemDetails(err)
+		if p.Type != "urn:shogoa:error:bad_request" {
+			t.Errorf("unexpected type: %s", p.Type)
+		}
+		if p.Title != "Bad Request" || p.Status != 400 || p.Detail != "invalid bottle" {
+			t.Errorf("unexpected problem details: %+v", p)
+		}
+		if p.Extensions["bottle"] != 42 || p.Token() != err.(*ErrorResponse).ID {
+			t.Errorf("unexpected extensions: %v", p.Extensions)
+		}
+		if _, ok := p.Extensions["status"]; ok {
+			t.Errorf("meta overrode a standard member: %v", p.Extensions)
+		}
+		if len(p.Errors) != 0 {
+			t.Errorf("unexpected errors: %v", p.Errors)
+		}
+	})
+
+	t.Run("merged errors", func(t *testing.T) {
+		err := MergeErrors(MissingParamError("id"), MissingHeaderError("X-Account"))
+		err = MergeErrors(err, ErrNotFound("no bottle"))
+		if errs := err.(*ErrorResponse).Errors(); len(errs) != 3 {
+			t.Fatalf("unexpected number of merged errors: %d", len(errs))
+		}
+		p := NewProblemDetails(err)
+		if p.Type != "urn:shogoa:error:bad_request" || p.Status != 400 {
+			t.Errorf("unexpected problem details: %+v", p)
+		}
+		if len(p.Errors) != 3 {
+			t.Fatalf("unexpected number of errors: %d", len(p.Errors))
+		}
+		if p.Errors[0].Type != "urn:shogoa:error:invalid_request" || p.Errors[0].Extensions["name"] != "id" {
+			t.Errorf("unexpected first error: %+v", p.Errors[0])
+		}
+		if p.Errors[2].Type != "urn:shogoa:error:not_found" || p.Errors[2].Status != 404 {
+			t.Errorf("unexpected last error: %+v", p.Errors[2])
+		}
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		p := NewProblemDetails(errors.New("boom"))
+		if p.Type != "urn:shogoa:error:internal" || p.Status != 500 || p.Detail != "boom" {
+			t.Errorf("unexpected problem details: %+v", p)
+		}
+	})
+}
+
+func TestProblemDetailsJSON(t *testing.T) {
+	p := NewProblemDetails(MergeErrors(MissingParamError("id"), MissingParamError("name")))
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, member := range []string{"type", "title", "status", "detail", "errors", "id", "name"} {
+		if _, ok := raw[member]; !ok {
+			t.Errorf("missing member %q in %s", member, b)
+		}
+	}
+
+	var decoded ProblemDetails
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Type != p.Type || decoded.Status != p.Status || decoded.Detail != p.Detail || decoded.Token() != p.Token() {
+		t.Errorf("unexpected decoded problem details: want %+v, got %+v", p, decoded)
+	}
+	if len(decoded.Errors) != 2 || decoded.Errors[1].Extensions["name"] != "name" {
+		t.Errorf("unexpected decoded errors: %v", decoded.Errors)
+	}
+
+	if err := json.Unmarshal([]byte(`{"title":"Not Found"}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Type != "about:blank" || decoded.Title != "Not Found" || decoded.Extensions != nil {
+		t.Errorf("unexpected decoded problem details: %+v", decoded)
+	}
+}
+
+func TestAcceptsProblemDetails(t *testing.T) {
+	cases := map[string]bool{
+		"":                         false,
+		"*/*":                      false,
+		"application/json":         false,
+		"application/problem+json": true,
+		"application/json, application/problem+json":                         true,
+		"application/problem+json;q=0":                                       false,
+		"application/problem+json;q=0.5, application/vnd.shogoa.error":       false,
+		"application/problem+json, application/vnd.shogoa.error;q=0.5, */*":  true,
+		"application/vnd.shogoa.error;q=0.8, application/problem+json;q=0.9": true,
+	}
+	for accept, want := range cases {
+		if got := AcceptsProblemDetails(accept); got != want {
+			t.Errorf("AcceptsProblemDetails(%q) = %v; want %v", accept, got, want)
+		}
+	}
+}
+
+func TestSendProblem(t *testing.T) {
+	service := New("test")
+	service.Encoder.Register(NewXMLEncoder, "*/*")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	rw := httptest.NewRecorder()
+	ctx := NewContext(rw, req, nil)
+	if err := service.SendProblem(ctx, http.StatusNotFound, NewProblemDetails(ErrNotFound("no bottle"))); err != nil {
+		t.Fatal(err)
+	}
+	if rw.Code != http.StatusNotFound {
+		t.Errorf("unexpected status code: %d", rw.Code)
+	}
+	if ct := rw.Header().Get("Content-Type"); ct != ProblemMediaIdentifier {
+		t.Errorf("unexpected content type: %s", ct)
+	}
+	var decoded ProblemDetails
+	if err := json.Unmarshal(rw.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON %s: %s", rw.Body, err)
+	}
+	if decoded.Detail != "no bottle" {
+		t.Errorf("unexpected detail: %s", decoded.Detail)
+	}
+}
@@ -0,0 +1,62 @@
+package shogoa
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// RateLimitMetadata is the name of the design metadata that declares the rate limit of an action
+// or of all the actions of a resource, e.g.:
+//
+//	Metadata("ratelimit", "100/m")
+//
+// The generated code wraps the handlers of the actions with Service.HandleRateLimit.
+const RateLimitMetadata = "ratelimit"
+
+// ContextRateLimit extracts the rate limit declared in the design for the action from the given
+// context. It returns an empty string if the action does not declare a rate limit. It is exported
+// so that the rate limiting middlewares mounted with UseRateLimit, which live in other packages
+// such as middleware/ratelimit, can read the limit of the action.
+func ContextRateLimit(ctx context.Context) string {
+	if l := ctx.Value(rateLimitKey); l != nil {
+		return l.(string)
+	}
+	return ""
+}
+
+// WithRateLimit builds a context containing the given rate limit. HandleRateLimit sets the limit
+// declared in the design, WithRateLimit allows hand-written handlers and tests to run the rate
+// limiting middlewares with a limit of their own.
+func WithRateLimit(ctx context.Context, limit string) context.Context {
+	return context.WithValue(ctx, rateLimitKey, limit)
+}
+
+// UseRateLimit mounts the middleware that enforces the rate limits declared in the design, see
+// the ratelimit middleware package. Actions that declare a rate limit are not limited if no
+// middleware is mounted. The middleware must be mounted before the service handles requests.
+func (service *Service) UseRateLimit(m Middleware) {
+	service.rateLimiter = m
+}
+
+// HandleRateLimit creates a handler that runs the middleware mounted with UseRateLimit with the
+// given rate limit. It is used by the generated code for the actions that declare a rate limit.
+func (service *Service) HandleRateLimit(limit string, h Handler) Handler {
+	// Use closure to enable late computation of the handler to ensure the middleware has been
+	// mounted.
+	var handler Handler
+	var initHandler sync.Once
+
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		initHandler.Do(func() {
+			handler = h
+			if service.rateLimiter != nil {
+				limited := service.rateLimiter(h)
+				handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					return limited(WithRateLimit(ctx, limit), rw, req)
+				}
+			}
+		})
+		return handler(ctx, rw, req)
+	}
+}
@@ -0,0 +1,56 @@
+package shogoa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleRateLimit(t *testing.T) {
+	var limit string
+	handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		limit = ContextRateLimit(ctx)
+		return nil
+	}
+	serve := func(h Handler) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		rw := httptest.NewRecorder()
+		if err := h(NewContext(rw, req, nil), rw, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("should pass through without rate limit middleware", func(t *testing.T) {
+		limit = "unset"
+		serve(New("test").HandleRateLimit("100/m", handler))
+		if limit != "" {
+			t.Errorf("unexpected rate limit: %q", limit)
+		}
+	})
+
+	t.Run("should run the rate limit middleware with the limit", func(t *testing.T) {
+		var (
+			called string
+			wraps  int
+		)
+		service := New("test")
+		h := service.HandleRateLimit("100/m", handler)
+		service.UseRateLimit(func(h Handler) Handler {
+			wraps++
+			return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				called = ContextRateLimit(ctx)
+				return h(ctx, rw, req)
+			}
+		})
+		serve(h)
+		serve(h)
+		if called != "100/m" || limit != "100/m" {
+			t.Errorf("unexpected rate limits: middleware %q, handler %q", called, limit)
+		}
+		if wraps != 1 {
+			t.Errorf("middleware wrapped the handler %d times, want 1", wraps)
+		}
+	})
+}
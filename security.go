@@ -41,6 +41,8 @@ type OAuth2Security struct {
 	AuthorizationURL string
 	// Scopes defines a list of scopes for the security scheme, along with their description.
 	Scopes map[string]string
+	// Realm is the realm of the WWW-Authenticate challenges, see RFC 6750.
+	Realm string
 }
 
 // BasicAuthSecurity represents the `Basic` security scheme, which consists of a simple login/pass,
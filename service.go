@@ -14,6 +14,8 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -39,9 +41,26 @@ type Service struct {
 	Decoder *HTTPDecoder
 	// Response body encoder
 	Encoder *HTTPEncoder
-
-	middleware []Middleware       // Middleware chain
-	cancel     context.CancelFunc // Service context cancel signal trigger
+	// ShutdownTimeout is the maximum duration Run waits for in-flight requests to complete and
+	// then for the shutdown hooks to return during graceful shutdown.
+	ShutdownTimeout time.Duration
+	// ShutdownDelay is the duration Run keeps serving requests once graceful shutdown begins
+	// before it stops accepting new connections. The readiness endpoint fails during the delay so
+	// that load balancers have time to stop routing requests to the service.
+	ShutdownDelay time.Duration
+	// ProblemErrors causes the ErrorHandler middleware to render errors as RFC 9457 problem
+	// details (application/problem+json). Clients may also request problem details by listing
+	// application/problem+json in the request Accept header.
+	ProblemErrors bool
+
+	middleware   []Middleware       // Middleware chain
+	cancel       context.CancelFunc // Service context cancel signal trigger
+	onStart      []Hook             // Hooks run before serving requests
+	startedHooks []int              // Number of shutdown hooks registered before each start hook
+	onShutdown   []Hook             // Hooks run after in-flight requests completed
+	shuttingDown atomic.Bool        // Whether graceful shutdown has begun
+	rateLimiter  Middleware         // Middleware enforcing the design rate limits
+	idempotency  Middleware         // Middleware handling the design idempotent actions
 }
 
 // Controller defines the common fields and behavior of generated controllers.
@@ -104,8 +123,9 @@ func New(name string) *Service {
 		Server: &http.Server{
 			Handler: mux,
 		},
-		Decoder: NewHTTPDecoder(),
-		Encoder: NewHTTPEncoder(),
+		Decoder:         NewHTTPDecoder(),
+		Encoder:         NewHTTPEncoder(),
+		ShutdownTimeout: DefaultShutdownTimeout,
 
 		cancel: cancel,
 	}
@@ -264,14 +284,33 @@ func (service *Service) NewController(name string) *Controller {
 }
 
 // Send serializes the given body matching the request Accept header against the service
-// encoders. It uses the default service encoder if no match is found.
+// encoders, see HTTPEncoder.Encode for a description of the negotiation algorithm. Send returns an
+// error of class ErrNotAcceptable without writing the response if no encoder is acceptable, unless
+// code is an error status code (400 or above) in which case the Accept header is disregarded and
+// the default encoder is used so that errors always get rendered.
 func (service *Service) Send(ctx context.Context, code int, body any) error {
 	r := ContextResponse(ctx)
 	if r == nil {
 		return fmt.Errorf("no response data in context")
 	}
+	var accept string
+	if req := ContextRequest(ctx); req != nil {
+		accept = req.Header.Get("Accept")
+	}
+	p, contentType, err := service.Encoder.negotiate(accept)
+	if err != nil {
+		if code < 400 {
+			return err
+		}
+		if p, contentType = service.Encoder.fallback(); p == nil {
+			return err
+		}
+	}
+	if contentType != "" && r.Header().Get("Content-Type") == "" {
+		r.Header().Set("Content-Type", contentType)
+	}
 	r.WriteHeader(code)
-	return service.EncodeResponse(ctx, body)
+	return p.Encode(body, r)
 }
 
 // ServeFiles create a "FileServer" controller and calls ServerFiles on it.
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 
 	"github.com/shogo82148/shogoa/design"
@@ -95,6 +96,9 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err := g.generateSecurity(); err != nil {
 		return nil, err
 	}
+	if err := g.generateErrors(); err != nil {
+		return nil, err
+	}
 	if err := g.generateHrefs(); err != nil {
 		return nil, err
 	}
@@ -145,6 +149,7 @@ func (g *Generator) generateContexts() (err error) {
 	title := fmt.Sprintf("%s: Application Contexts", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("iter"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("strings"),
@@ -207,6 +212,9 @@ func (g *Generator) generateContexts() (err error) {
 				API:          g.API,
 				DefaultPkg:   g.Target,
 				Security:     a.Security,
+				Cacheable:    a.Cacheable,
+				CacheControl: a.CachePolicy(),
+				Pagination:   a.Pagination,
 			}
 			return ctxWr.Execute(&ctxData)
 		})
@@ -318,6 +326,8 @@ func (g *Generator) generateControllers() (err error) {
 				"PayloadOptional":  a.PayloadOptional,
 				"PayloadMultipart": a.PayloadMultipart,
 				"Security":         a.Security,
+				"RateLimit":        a.RateLimit(),
+				"Idempotent":       a.Idempotent(),
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
@@ -374,6 +384,43 @@ func (g *Generator) generateSecurity() (err error) {
 	return
 }
 
+// generateErrors generates the error classes of the errors declared by the API actions.
+func (g *Generator) generateErrors() (err error) {
+	errs := slices.Collect(g.API.AllErrors())
+	if len(errs) == 0 {
+		return nil
+	}
+
+	var (
+		errFile string
+		errWr   *ErrorsWriter
+	)
+	{
+		errFile = filepath.Join(g.OutDir, "errors.go")
+		errWr, err = NewErrorsWriter(errFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		errWr.Close()
+		if err == nil {
+			err = errWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Errors", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.NewImport("shogoa", "github.com/shogo82148/shogoa"),
+	}
+	if err = errWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, errFile)
+	err = errWr.Execute(errs)
+
+	return
+}
+
 // generateHrefs iterates through the API resources and generates the href factory methods.
 func (g *Generator) generateHrefs() (err error) {
 	var (
@@ -45,6 +45,11 @@ type (
 		SecurityTmpl *template.Template
 	}
 
+	// ErrorsWriter generate code for the error classes of the errors declared by the API actions.
+	ErrorsWriter struct {
+		*codegen.SourceFile
+	}
+
 	// ResourcesWriter generate code for a shogoa application resources.
 	// Resources are data structures initialized by the application handlers and passed to controller
 	// actions.
@@ -84,6 +89,9 @@ type (
 		API          *design.APIDefinition
 		DefaultPkg   string
 		Security     *design.SecurityDefinition
+		Cacheable    bool
+		CacheControl string
+		Pagination   *design.PaginationDefinition
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
@@ -220,6 +228,11 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 	if err := w.ExecuteTemplate("new", ctxNewT, fn, data); err != nil {
 		return err
 	}
+	if data.Pagination != nil && data.Pagination.NextCursor == "" {
+		if err := w.ExecuteTemplate("pagination", ctxPaginationT, nil, data); err != nil {
+			return err
+		}
+	}
 	if data.Payload != nil {
 		found := false
 		for _, t := range design.Design.Types {
@@ -243,12 +256,18 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			"Context":  data,
 			"Response": resp,
 		}
+		if resp.Stream != "" {
+			respData["Stream"] = streamFramings[resp.Stream]
+		}
 		var mt *design.MediaTypeDefinition
 		if resp.Type != nil {
 			var ok bool
 			if mt, ok = resp.Type.(*design.MediaTypeDefinition); !ok {
 				respData["Type"] = resp.Type
 				respData["ContentType"] = resp.MediaType
+				if resp.Stream != "" {
+					return w.ExecuteTemplate("response", ctxTStreamRespT, nil, respData)
+				}
 				return w.ExecuteTemplate("response", ctxTRespT, nil, respData)
 			}
 		} else {
@@ -282,7 +301,11 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 					base := fmt.Sprintf("%s%s", resp.Name, strings.Title(view))
 					respData["RespName"] = codegen.Goify(base, true)
 				}
-				if err := w.ExecuteTemplate("response", ctxMTRespT, fn, respData); err != nil {
+				tmpl := ctxMTRespT
+				if resp.Stream != "" {
+					tmpl = ctxMTStreamRespT
+				}
+				if err := w.ExecuteTemplate("response", tmpl, fn, respData); err != nil {
 					return err
 				}
 			}
@@ -292,6 +315,13 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 	})
 }
 
+// streamFramings describes the framings of the streamed responses indexed by content type:
+// ContentType is the shogoa package constant holding the content type.
+var streamFramings = map[string]struct{ ContentType, Description string }{
+	design.StreamNDJSON: {"shogoa.NDJSONContentType", "newline delimited JSON"},
+	design.StreamSSE:    {"shogoa.EventStreamContentType", "server-sent events"},
+}
+
 // NewControllersWriter returns a handlers code writer.
 // Handlers provide the glue between the underlying request data and the user controller.
 func NewControllersWriter(filename string) (*ControllersWriter, error) {
@@ -363,6 +393,20 @@ func (w *SecurityWriter) Execute(schemes []*design.SecuritySchemeDefinition) err
 	return w.ExecuteTemplate("security_schemes", securitySchemesT, nil, schemes)
 }
 
+// NewErrorsWriter returns an error classes code writer.
+func NewErrorsWriter(filename string) (*ErrorsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &ErrorsWriter{SourceFile: file}, nil
+}
+
+// Execute writes the error class of each error.
+func (w *ErrorsWriter) Execute(errors []*design.ErrorDefinition) error {
+	return w.ExecuteTemplate("errors", errorsT, nil, errors)
+}
+
 // NewResourcesWriter returns a contexts code writer.
 // Resources provide the glue between the underlying request data and the user controller.
 func NewResourcesWriter(filename string) (*ResourcesWriter, error) {
@@ -665,19 +709,60 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *shogoa.Servic
 */}}{{ if $validation }}{{ $validation }}{{ end }}{{ end }}	}
 {{ end }}{{ end }}{{/* if .Params */}}	return &rctx, err
 }
+`
+
+	// errorsT generates the error classes of the errors declared by the API actions.
+	// template input: []*design.ErrorDefinition
+	errorsT = `var (
+{{ range . }}	// Err{{ goify .Name true }} creates {{ printf "%q" .Name }} errors rendered with status {{ .Status }}.
+{{ if .Description }}	{{ comment .Description }}
+{{ end }}	Err{{ goify .Name true }} = shogoa.NewErrorClass({{ printf "%q" .Name }}, {{ .Status }})
+{{ end }})
+`
+
+	// ctxPaginationT generates the next page helper of actions paginated with the Link header.
+	// template input: *ContextTemplateData
+	ctxPaginationT = `// SetNextPage adds the Link header of the next page that starts at cursor to the response.
+// It does nothing if cursor is empty, i.e. on the last page.
+func (ctx *{{ .Name }}) SetNextPage(cursor string) {
+	shogoa.SetNextPageLink(ctx.Context, {{ printf "%q" .Pagination.CursorParam }}, cursor)
+}
 `
 
 	// ctxMTRespT generates the response helpers for responses with media types.
 	// template input: map[string]interface{}
 	ctxMTRespT = `// {{ goify .RespName true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
-	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+{{ if .Projected.IsProblem }}	return ctx.ResponseData.Service.SendProblem(ctx.Context, {{ .Response.Status }}, shogoa.NewProblemDetails(r))
+}
+{{ else }}{{ if and .Context.CacheControl (lt .Response.Status 300) }}	if ctx.ResponseData.Header().Get("Cache-Control") == "" {
+		ctx.ResponseData.Header().Set("Cache-Control", {{ printf "%q" .Context.CacheControl }})
+	}
+{{ end }}	if ctx.ResponseData.Header().Get("Content-Type") == "" {
 		ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
 	}
 {{ if .Projected.Type.IsArray }}	if r == nil {
 		r = {{ gotyperef .Projected .Projected.AllRequired 0 false }}{}
 	}
-{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+{{ end }}	return ctx.ResponseData.Service.{{ if and .Context.Cacheable (eq .Response.Status 200) }}SendCacheable{{ else }}Send{{ end }}(ctx.Context, {{ .Response.Status }}, r)
+}
+{{ end }}`
+
+	// ctxMTStreamRespT generates the response helpers for streamed responses with media types.
+	// template input: map[string]interface{}
+	ctxMTStreamRespT = `// {{ goify .RespName true }} sends a HTTP response with status code {{ .Response.Status }} streaming the values of seq
+// as {{ .Stream.Description }}.
+func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(seq iter.Seq[{{ gotyperef .Projected .Projected.AllRequired 0 false }}]) error {
+	return shogoa.SendStream(ctx.Context, {{ .Response.Status }}, {{ .Stream.ContentType }}, seq)
+}
+`
+
+	// ctxTStreamRespT generates the response helpers for streamed responses with overridden types.
+	// template input: map[string]interface{}
+	ctxTStreamRespT = `// {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }} streaming the values of seq
+// as {{ .Stream.Description }}.
+func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(seq iter.Seq[{{ gotyperef .Type nil 0 false }}]) error {
+	return shogoa.SendStream(ctx.Context, {{ .Response.Status }}, {{ .Stream.ContentType }}, seq)
 }
 `
 
@@ -685,10 +770,13 @@ func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Proje
 	// template input: map[string]interface{}
 	ctxTRespT = `// {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .Type nil 0 false }}) error {
-	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+{{ if and .Context.CacheControl (lt .Response.Status 300) }}	if ctx.ResponseData.Header().Get("Cache-Control") == "" {
+		ctx.ResponseData.Header().Set("Cache-Control", {{ printf "%q" .Context.CacheControl }})
+	}
+{{ end }}	if ctx.ResponseData.Header().Get("Content-Type") == "" {
 		ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
 	}
-	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+	return ctx.ResponseData.Service.{{ if and .Context.Cacheable (eq .Response.Status 200) }}SendCacheable{{ else }}Send{{ end }}(ctx.Context, {{ .Response.Status }}, r)
 }
 `
 
@@ -697,7 +785,10 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .
 	ctxNoMTRespT = `
 // {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.MediaType }}resp []byte{{ end }}) error {
-{{ if .Response.MediaType }}	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+{{ if and .Context.CacheControl (lt .Response.Status 300) }}	if ctx.ResponseData.Header().Get("Cache-Control") == "" {
+		ctx.ResponseData.Header().Set("Cache-Control", {{ printf "%q" .Context.CacheControl }})
+	}
+{{ end }}{{ if .Response.MediaType }}	if ctx.ResponseData.Header().Get("Content-Type") == "" {
 		ctx.ResponseData.Header().Set("Content-Type", "{{ .Response.MediaType }}")
 	}
 {{ end }}	ctx.ResponseData.WriteHeader({{ .Response.Status }}){{ if .Response.MediaType }}
@@ -755,19 +846,12 @@ type {{ .Resource }}Controller interface {
 	serviceT = `
 // initService sets up the service encoders, decoders and mux.
 func initService(service *shogoa.Service) {
-	// Setup encoders and decoders
+	// Setup encoders and decoders, the default ones are also registered for "*/*"
 {{ range .Encoders }}{{/*
-*/}}	service.Encoder.Register({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}")
+*/}}	service.Encoder.Register({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}"{{ if .Default }}, "*/*"{{ end }})
 {{ end }}{{ range .Decoders }}{{/*
-*/}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}")
-{{ end }}
-
-	// Setup default encoder and decoder
-{{ range .Encoders }}{{ if .Default }}{{/*
-*/}}	service.Encoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
-{{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
-*/}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
-{{ end }}{{ end }}}
+*/}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}"{{ if .Default }}, "*/*"{{ end }})
+{{ end }}}
 `
 
 	// mountT generates the code for a resource "Mount" function.
@@ -798,7 +882,9 @@ func Mount{{ .Resource }}Controller(service *shogoa.Service, ctrl {{ .Resource }
 {{ end }}		}
 {{ end }}		return ctrl.{{ .Name }}(rctx)
 	}
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ if .Idempotent }}	h = service.HandleIdempotency(h)
+{{ end }}{{ if .RateLimit }}	h = service.HandleRateLimit({{ printf "%q" .RateLimit }}, h)
+{{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
 {{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
@@ -972,8 +1058,9 @@ func {{ $funcName }}() *shogoa.{{ .Context }} {
 		Scopes: map[string]string{
 {{ range $k, $v := . }}			{{ printf "%q" $k }}: {{ printf "%q" $v }},
 {{ end }}{{/*
-*/}}		},{{ end }}{{/*
-*/}}{{ else if eq .Context "BasicAuthSecurity" }}{{/*
+*/}}		},{{ end }}
+		Realm: {{ printf "%q" .SchemeName }},
+{{ else if eq .Context "BasicAuthSecurity" }}{{/*
 */}}{{ else if eq .Context "JWTSecurity" }}{{/*
 */}}		In:   {{ if eq .In "header" }}shogoa.LocHeader{{ else }}shogoa.LocQuery{{ end }},
 		Name:             {{ printf "%q" .Name }},
@@ -58,10 +58,16 @@ var _ = Describe("ContextsWriter", func() {
 			var payload *design.UserTypeDefinition
 			var responses map[string]*design.ResponseDefinition
 			var routes []*design.RouteDefinition
+			var cacheable bool
+			var cacheControl string
+			var pagination *design.PaginationDefinition
 
 			var data *genapp.ContextTemplateData
 
 			BeforeEach(func() {
+				cacheable = false
+				cacheControl = ""
+				pagination = nil
 				params = nil
 				headers = nil
 				payload = nil
@@ -82,6 +88,9 @@ var _ = Describe("ContextsWriter", func() {
 					Routes:       routes,
 					API:          design.Design,
 					DefaultPkg:   "",
+					Cacheable:    cacheable,
+					CacheControl: cacheControl,
+					Pagination:   pagination,
 				}
 			})
 
@@ -98,6 +107,38 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("of a paginated action", func() {
+				BeforeEach(func() {
+					pagination = &design.PaginationDefinition{CursorParam: "cursor", PageSizeParam: "limit"}
+				})
+
+				It("writes the next page helper", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := os.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`func (ctx *ListBottleContext) SetNextPage(cursor string) {
+	shogoa.SetNextPageLink(ctx.Context, "cursor", cursor)
+}`))
+				})
+
+				Context("with a next cursor attribute", func() {
+					BeforeEach(func() {
+						pagination.NextCursor = "next"
+						pagination.Items = "items"
+					})
+
+					It("does not write the next page helper", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := os.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(string(b)).ShouldNot(ContainSubstring("SetNextPage"))
+					})
+				})
+			})
+
 			Context("with a media type setting a ContentType", func() {
 				var contentType = "application/json"
 
@@ -137,6 +178,125 @@ var _ = Describe("ContextsWriter", func() {
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
 					Ω(written).Should(ContainSubstring(`ctx.ResponseData.Header().Set("Content-Type", "` + contentType + `")`))
+					Ω(written).Should(ContainSubstring(`return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`))
+				})
+
+				Context("of a cacheable action", func() {
+					BeforeEach(func() {
+						cacheable = true
+					})
+
+					It("the generated code handles conditional requests", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := os.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(`return ctx.ResponseData.Service.SendCacheable(ctx.Context, 200, r)`))
+					})
+				})
+
+				Context("with a cache policy", func() {
+					BeforeEach(func() {
+						cacheControl = "public, max-age=60"
+					})
+
+					It("the generated code sets the Cache-Control header", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := os.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(`	if ctx.ResponseData.Header().Get("Cache-Control") == "" {
+		ctx.ResponseData.Header().Set("Cache-Control", "public, max-age=60")
+	}
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {`))
+					})
+				})
+			})
+
+			Context("with a streamed media type", func() {
+				var stream string
+
+				BeforeEach(func() {
+					stream = design.StreamNDJSON
+				})
+
+				JustBeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"foo": {Type: design.String}},
+							},
+							TypeName: "Test",
+						},
+						Identifier: "application/vnd.shogoa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					data.Responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:      "OK",
+						Status:    200,
+						MediaType: mediaType.Identifier,
+						Stream:    stream,
+					}}
+				})
+
+				It("the generated code streams the values as newline delimited JSON", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := os.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(streamResponse))
+				})
+
+				Context("using server-sent events", func() {
+					BeforeEach(func() {
+						stream = design.StreamSSE
+					})
+
+					It("the generated code streams the values as server-sent events", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := os.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(sseResponse))
+					})
+				})
+			})
+
+			Context("with the problem details media type", func() {
+				JustBeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(design.ProblemMediaIdentifier): design.ProblemMedia,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					data.Responses = map[string]*design.ResponseDefinition{"BadRequest": {
+						Name:      "BadRequest",
+						Status:    400,
+						MediaType: design.ProblemMediaIdentifier,
+					}}
+				})
+
+				It("the generated code sends problem details", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := os.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(problemResponse))
 				})
 			})
 
@@ -1006,6 +1166,8 @@ var _ = Describe("ControllersWriter", func() {
 
 		Context("with data", func() {
 			var multipart bool
+			var rateLimit string
+			var idempotent bool
 			var actions, verbs, paths, contexts, unmarshals []string
 			var payloads []*design.UserTypeDefinition
 			var encoders, decoders []*genapp.EncoderTemplateData
@@ -1015,6 +1177,8 @@ var _ = Describe("ControllersWriter", func() {
 
 			BeforeEach(func() {
 				multipart = false
+				rateLimit = ""
+				idempotent = false
 				actions = nil
 				verbs = nil
 				paths = nil
@@ -1055,6 +1219,8 @@ var _ = Describe("ControllersWriter", func() {
 						"Unmarshal":        unmarshal,
 						"Payload":          payload,
 						"PayloadMultipart": multipart,
+						"RateLimit":        rateLimit,
+						"Idempotent":       idempotent,
 					}
 				}
 				if len(as) > 0 {
@@ -1099,6 +1265,52 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with a rate limited action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					rateLimit = "100/m"
+				})
+
+				It("wraps the handler with the rate limit handler", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := os.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`		return ctrl.List(rctx)
+	}
+	h = service.HandleRateLimit("100/m", h)
+	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))`))
+				})
+			})
+
+			Context("with an idempotent action", func() {
+				BeforeEach(func() {
+					actions = []string{"create"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"CreateBottleContext"}
+					rateLimit = "100/m"
+					idempotent = true
+				})
+
+				It("wraps the handler with the idempotency handler", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := os.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`		return ctrl.Create(rctx)
+	}
+	h = service.HandleIdempotency(h)
+	h = service.HandleRateLimit("100/m", h)
+	service.Mux.Handle("POST", "/accounts/:accountID/bottles", ctrl.MuxHandler("create", h, nil))`))
+				})
+			})
+
 			Context("with actions that take a payload", func() {
 				BeforeEach(func() {
 					actions = []string{"list"}
@@ -1651,7 +1863,68 @@ var _ = Describe("UserTypesWriter", func() {
 	})
 })
 
+var _ = Describe("ErrorsWriter", func() {
+	var writer *genapp.ErrorsWriter
+	var workspace *codegen.Workspace
+	var filename string
+
+	oldGO111MODULE := os.Getenv("GO111MODULE")
+
+	BeforeEach(func() {
+		os.Setenv("GO111MODULE", "off")
+
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("controllers")
+		Ω(err).ShouldNot(HaveOccurred())
+		src, err := pkg.CreateSourceFile("test.go")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer src.Close()
+		filename = src.Abs()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		writer, err = genapp.NewErrorsWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+		os.Setenv("GO111MODULE", oldGO111MODULE)
+	})
+
+	Context("with errors", func() {
+		var data []*design.ErrorDefinition
+
+		BeforeEach(func() {
+			data = []*design.ErrorDefinition{
+				{Name: "bottle_not_found", Status: 404, Description: "The bottle does not exist"},
+				{Name: "sold_out", Status: 409},
+			}
+		})
+
+		It("writes the error classes", func() {
+			err := writer.Execute(data)
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := os.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(errorClasses))
+		})
+	})
+})
+
 const (
+	errorClasses = `var (
+	// ErrBottleNotFound creates "bottle_not_found" errors rendered with status 404.
+	// The bottle does not exist
+	ErrBottleNotFound = shogoa.NewErrorClass("bottle_not_found", 404)
+	// ErrSoldOut creates "sold_out" errors rendered with status 409.
+	ErrSoldOut = shogoa.NewErrorClass("sold_out", 409)
+)
+`
+
 	emptyContext = `
 type ListBottleContext struct {
 	context.Context
@@ -1670,6 +1943,26 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *shogoa.
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	return &rctx, err
 }
+`
+
+	streamResponse = `// OK sends a HTTP response with status code 200 streaming the values of seq
+// as newline delimited JSON.
+func (ctx *ListBottleContext) OK(seq iter.Seq[*Test]) error {
+	return shogoa.SendStream(ctx.Context, 200, shogoa.NDJSONContentType, seq)
+}
+`
+
+	sseResponse = `// OK sends a HTTP response with status code 200 streaming the values of seq
+// as server-sent events.
+func (ctx *ListBottleContext) OK(seq iter.Seq[*Test]) error {
+	return shogoa.SendStream(ctx.Context, 200, shogoa.EventStreamContentType, seq)
+}
+`
+
+	problemResponse = `// BadRequest sends a HTTP response with status code 400.
+func (ctx *ListBottleContext) BadRequest(r error) error {
+	return ctx.ResponseData.Service.SendProblem(ctx.Context, 400, shogoa.NewProblemDetails(r))
+}
 `
 
 	intContext = `
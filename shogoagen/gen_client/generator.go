@@ -3,9 +3,11 @@ package genclient
 import (
 	"flag"
 	"fmt"
+	"mime"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"text/template"
@@ -302,6 +304,9 @@ func (g *Generator) generateClientResources(pkgDir, clientPkg string, funcs temp
 	if err := g.generateUserTypes(pkgDir); err != nil {
 		return err
 	}
+	if err := g.generateErrors(pkgDir, funcs); err != nil {
+		return err
+	}
 
 	return g.generateMediaTypes(pkgDir, funcs)
 }
@@ -333,6 +338,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("io"),
+		codegen.SimpleImport("iter"),
 		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("net/url"),
@@ -344,6 +350,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.NewImport("goaclient", "github.com/shogo82148/shogoa/client"),
 		codegen.NewImport("uuid", "github.com/shogo82148/shogoa/uuid"),
 	}
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
@@ -489,6 +496,12 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 	if action.Security != nil {
 		signer = codegen.Goify(action.Security.Scheme.SchemeName, true)
 	}
+	result, decoded := g.actionResult(action)
+	stream := g.actionStream(action)
+	var pagination *paginationData
+	if action.Pagination != nil && decoded && result != nil {
+		pagination = newPaginationData(action.Pagination, result, params, names)
+	}
 	data := struct {
 		Name               string
 		ResourceName       string
@@ -505,6 +518,11 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Signer             string
 		QueryParams        []*paramData
 		Headers            []*paramData
+		Decoded            bool
+		Result             *resultData
+		Pagination         *paginationData
+		Stream             *resultData
+		HasErrors          bool
 	}{
 		Name:               action.Name,
 		ResourceName:       action.Parent.Name,
@@ -521,6 +539,11 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Signer:             signer,
 		QueryParams:        queryParams,
 		Headers:            headers,
+		Decoded:            decoded,
+		Result:             result,
+		Pagination:         pagination,
+		Stream:             stream,
+		HasErrors:          len(slices.Collect(g.API.AllErrors())) > 0,
 	}
 	if action.WebSocket() {
 		return clientsWSTmpl.Execute(file, data)
@@ -531,6 +554,205 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 	return requestsTmpl.Execute(file, data)
 }
 
+// errorData describes an error declared by the API actions.
+type errorData struct {
+	*design.ErrorDefinition
+	// Result describes the custom media type of the error response, nil if the error is
+	// rendered with ErrorMedia or ProblemMedia.
+	Result *resultData
+	// ContentType is the media type of the custom error responses without parameters.
+	ContentType string
+}
+
+// errorsData returns the data used to generate the typed errors of the API.
+func (g *Generator) errorsData() []*errorData {
+	var data []*errorData
+	for e := range g.API.AllErrors() {
+		d := &errorData{ErrorDefinition: e}
+		data = append(data, d)
+		mt := g.API.MediaTypeWithIdentifier(e.MediaType)
+		if mt == nil || mt.IsError() {
+			continue
+		}
+		p, _, err := mt.Project(design.DefaultView)
+		if err != nil {
+			continue
+		}
+		d.Result = &resultData{
+			TypeRef:    decodeGoTypeRef(p, p.AllRequired(), 0, false),
+			DecodeFunc: "Decode" + typeName(p),
+			Validate:   p.IsObject() || p.IsArray(),
+			Statuses:   []int{e.Status},
+			MediaType:  p,
+		}
+		d.ContentType, _, _ = mime.ParseMediaType(mt.Identifier)
+	}
+	return data
+}
+
+// resultData describes the media type decoded from the success responses of an action.
+type resultData struct {
+	// TypeRef is the Go type of the decoded value.
+	TypeRef string
+	// DecodeFunc is the name of the client method that decodes the value.
+	DecodeFunc string
+	// Validate is true if the decoded value has a Validate method.
+	Validate bool
+	// Statuses lists the status codes of the responses with a body.
+	Statuses []int
+	// MediaType is the projected media type of the decoded value.
+	MediaType *design.MediaTypeDefinition
+}
+
+// paginationData describes the client method that iterates over the items of all the pages of a
+// paginated action.
+type paginationData struct {
+	// Params lists the method parameters, that is the action parameters but the cursor.
+	Params string
+	// ParamNames lists the arguments of the action method call made for each page.
+	ParamNames string
+	// CursorParam is the name of the cursor query string parameter.
+	CursorParam string
+	// ItemType is the Go type of the page items.
+	ItemType string
+	// Items is the name of the page field that holds the items, the page is a collection if empty.
+	Items string
+	// NextCursor is the name of the page field that holds the next page cursor, the cursor is
+	// read from the Link header if empty.
+	NextCursor string
+	// NextCursorPointer is true if the NextCursor field is a pointer.
+	NextCursorPointer bool
+}
+
+// newPaginationData computes the pagination data of an action given the media type decoded from
+// its OK response and the parameters and parameter names of the action client method.
+func newPaginationData(p *design.PaginationDefinition, result *resultData, params, names []string) *paginationData {
+	cursorVar := codegen.GoifyAtt(p.Parent.QueryParams.Type.ToObject()[p.CursorParam], p.CursorParam, false)
+	var pageParams, pageNames []string
+	found := false
+	for i, name := range names {
+		if name == cursorVar && !found {
+			found = true
+			pageNames = append(pageNames, "cursorp")
+			continue
+		}
+		pageParams = append(pageParams, params[i])
+		pageNames = append(pageNames, name)
+	}
+	data := &paginationData{
+		Params:      strings.Join(pageParams, ", "),
+		ParamNames:  strings.Join(pageNames, ", "),
+		CursorParam: p.CursorParam,
+	}
+	mt := result.MediaType
+	items := mt.AttributeDefinition
+	if p.Items != "" {
+		items = mt.Type.ToObject()[p.Items]
+		data.Items = codegen.GoifyAtt(items, p.Items, true)
+		data.NextCursor = codegen.GoifyAtt(mt.Type.ToObject()[p.NextCursor], p.NextCursor, true)
+		data.NextCursorPointer = mt.IsPrimitivePointer(p.NextCursor)
+	}
+	elem := items.Type.ToArray().ElemType
+	data.ItemType = codegen.GoTypeRef(elem.Type, elem.AllRequired(), 1, false)
+	return data
+}
+
+// actionResult returns the media type decoded from the success responses of action, nil if they
+// have no body. The boolean is false if the responses cannot be decoded into a single type, that is
+// if they use different media types or views or if they are streamed.
+func (g *Generator) actionResult(action *design.ActionDefinition) (*resultData, bool) {
+	var (
+		result *resultData
+		ok     = true
+	)
+	action.IterateResponses(func(resp *design.ResponseDefinition) error {
+		if resp.Status < 200 || resp.Status > 299 || resp.MediaType == "" {
+			return nil
+		}
+		mt := g.API.MediaTypeWithIdentifier(resp.MediaType)
+		if mt == nil || resp.Stream != "" {
+			ok = false
+			return nil
+		}
+		view := resp.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			ok = false
+			return nil
+		}
+		typeRef := decodeGoTypeRef(p, p.AllRequired(), 0, false)
+		if result == nil {
+			result = &resultData{
+				TypeRef:    typeRef,
+				DecodeFunc: "Decode" + typeName(p),
+				Validate:   (p.IsObject() || p.IsArray()) && !p.IsError(),
+				MediaType:  p,
+			}
+		} else if result.TypeRef != typeRef {
+			ok = false
+		}
+		result.Statuses = append(result.Statuses, resp.Status)
+		return nil
+	})
+	if !ok {
+		return nil, false
+	}
+	if result != nil {
+		slices.Sort(result.Statuses)
+	}
+	return result, true
+}
+
+// actionStream returns the description of the values streamed in the success responses of the
+// action. It returns nil if the action does not stream its responses or if the responses stream
+// values of different types.
+func (g *Generator) actionStream(action *design.ActionDefinition) *resultData {
+	var (
+		result *resultData
+		ok     = true
+	)
+	action.IterateResponses(func(resp *design.ResponseDefinition) error {
+		if resp.Status < 200 || resp.Status > 299 || resp.Stream == "" {
+			return nil
+		}
+		mt := g.API.MediaTypeWithIdentifier(resp.MediaType)
+		if mt == nil {
+			ok = false
+			return nil
+		}
+		view := resp.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			ok = false
+			return nil
+		}
+		typeRef := decodeGoTypeRef(p, p.AllRequired(), 0, false)
+		if result == nil {
+			result = &resultData{
+				TypeRef:    typeRef,
+				DecodeFunc: "Decode" + typeName(p) + "Stream",
+				Validate:   (p.IsObject() || p.IsArray()) && !p.IsError(),
+				MediaType:  p,
+			}
+		} else if result.TypeRef != typeRef {
+			ok = false
+		}
+		result.Statuses = append(result.Statuses, resp.Status)
+		return nil
+	})
+	if !ok || result == nil {
+		return nil
+	}
+	slices.Sort(result.Statuses)
+	return result
+}
+
 // fileServerMethod returns the name of the client method for downloading assets served by the given
 // file server.
 // Note: the implementation opts for generating good names rather than names that are guaranteed to
@@ -567,6 +789,7 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 	funcs["decodegotyperef"] = decodeGoTypeRef
 	funcs["decodegotypename"] = decodeGoTypeName
 	typeDecodeTmpl := template.Must(template.New("typeDecode").Funcs(funcs).Parse(typeDecodeTmpl))
+	typeDecodeStreamTmpl := template.Must(template.New("typeDecodeStream").Funcs(funcs).Parse(typeDecodeStreamTmpl))
 	var (
 		mtFile string
 		mtWr   *genapp.MediaTypesWriter
@@ -587,7 +810,9 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.NewImport("shogoa", "github.com/shogo82148/shogoa"),
+		codegen.NewImport("goaclient", "github.com/shogo82148/shogoa/client"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("iter"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
@@ -600,6 +825,7 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 		return err
 	}
 	g.genfiles = append(g.genfiles, mtFile)
+	streamed := streamedViews(g.API)
 	err = g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
 		if (mt.Type.IsObject() || mt.Type.IsArray()) && !mt.IsError() {
 			if err := mtWr.Execute(mt); err != nil {
@@ -611,13 +837,91 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 			if err != nil {
 				return err
 			}
-			return typeDecodeTmpl.Execute(mtWr.SourceFile, p)
+			if err := typeDecodeTmpl.Execute(mtWr.SourceFile, p); err != nil {
+				return err
+			}
+			if streamed[design.CanonicalIdentifier(mt.Identifier)+"#"+view.Name] {
+				return typeDecodeStreamTmpl.Execute(mtWr.SourceFile, p)
+			}
+			return nil
 		})
 		return err
 	})
 	return
 }
 
+// streamedViews returns the media type views used by streamed responses indexed by canonical
+// media type identifier and view name separated with "#".
+func streamedViews(api *design.APIDefinition) map[string]bool {
+	streamed := make(map[string]bool)
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(action *design.ActionDefinition) error {
+			return action.IterateResponses(func(resp *design.ResponseDefinition) error {
+				if resp.Stream == "" {
+					return nil
+				}
+				mt := api.MediaTypeWithIdentifier(resp.MediaType)
+				if mt == nil {
+					return nil
+				}
+				id := design.CanonicalIdentifier(mt.Identifier)
+				if resp.ViewName != "" {
+					streamed[id+"#"+resp.ViewName] = true
+					return nil
+				}
+				for name := range mt.Views {
+					streamed[id+"#"+name] = true
+				}
+				return nil
+			})
+		})
+	})
+	return streamed
+}
+
+// generateErrors generates the typed errors of the errors declared by the API actions and the
+// client method that decodes error responses into them.
+func (g *Generator) generateErrors(pkgDir string, funcs template.FuncMap) (err error) {
+	errs := g.errorsData()
+	if len(errs) == 0 {
+		return nil
+	}
+	funcs["hasCustomErrors"] = func(errs []*errorData) bool {
+		return slices.ContainsFunc(errs, func(e *errorData) bool { return e.Result != nil })
+	}
+	errorsTmpl := template.Must(template.New("errors").Funcs(funcs).Parse(errorsTmpl))
+
+	var file *codegen.SourceFile
+	{
+		errFile := filepath.Join(pkgDir, "errors.go")
+		file, err = codegen.SourceFileFor(errFile)
+		if err != nil {
+			return
+		}
+		g.genfiles = append(g.genfiles, errFile)
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Errors", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("errors"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("mime"),
+		codegen.SimpleImport("net/http"),
+		codegen.NewImport("shogoa", "github.com/shogo82148/shogoa"),
+		codegen.NewImport("goaclient", "github.com/shogo82148/shogoa/client"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	err = errorsTmpl.Execute(file, errs)
+	return
+}
+
 // generateUserTypes iterates through the user types and generates the data structures and
 // marshaling code.
 func (g *Generator) generateUserTypes(pkgDir string) (err error) {
@@ -726,6 +1030,9 @@ func goTypeRefExt(t design.DataType, tabs int, pkg string) string {
 // decodeGoTypeRef handles the case where the type being decoded is a error response media type.
 func decodeGoTypeRef(t design.DataType, required []string, tabs int, private bool) string {
 	mt, ok := t.(*design.MediaTypeDefinition)
+	if ok && mt.IsProblem() {
+		return "*shogoa.ProblemDetails"
+	}
 	if ok && mt.IsError() {
 		return "*shogoa.ErrorResponse"
 	}
@@ -735,6 +1042,9 @@ func decodeGoTypeRef(t design.DataType, required []string, tabs int, private boo
 // decodeGoTypeName handles the case where the type being decoded is a error response media type.
 func decodeGoTypeName(t design.DataType, required []string, tabs int, private bool) string {
 	mt, ok := t.(*design.MediaTypeDefinition)
+	if ok && mt.IsProblem() {
+		return "shogoa.ProblemDetails"
+	}
 	if ok && mt.IsError() {
 		return "shogoa.ErrorResponse"
 	}
@@ -864,6 +1174,9 @@ func pathParams(r *design.RouteDefinition) string {
 
 // typeName returns Go type name of given MediaType definition.
 func typeName(mt *design.MediaTypeDefinition) string {
+	if mt.IsProblem() {
+		return "ProblemDetails"
+	}
 	if mt.IsError() {
 		return "ErrorResponse"
 	}
@@ -961,6 +1274,13 @@ func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef . .All
 	err := c.Decoder.Decode(&decoded, resp.Body, resp.Header.Get("Content-Type"))
 	return {{ if .IsObject }}&{{ end }}decoded, err
 }
+`
+
+	typeDecodeStreamTmpl = `{{ $typeName := typeName . }}{{ $funcName := printf "Decode%sStream" $typeName }}// {{ $funcName }} returns an iterator over the {{ $typeName }} instances streamed in resp body.
+// The iterator closes resp body when done.
+func (c *Client) {{ $funcName }}(resp *http.Response) iter.Seq2[{{ decodegotyperef . .AllRequired 0 false }}, error] {
+	return goaclient.DecodeStream[{{ decodegotyperef . .AllRequired 0 false }}](c.Decoder, resp)
+}
 `
 
 	pathTmpl = `{{ $funcName := printf "%sPath%s" (goify (printf "%s%s" .Route.Parent.Name (title .Route.Parent.Parent.Name)) true) ((or (and .Index (add .Index 1)) "") | printf "%v") }}{{/*
@@ -983,7 +1303,100 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 	return c.Client.Do(ctx, req)
 }
-`
+{{ if .Decoded }}
+// {{ $funcName }}Decoded makes a request to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource{{ if .Result }}
+// and returns the validated {{ .Result.TypeRef }} decoded from the response body{{ end }}. The error
+// responses are returned as errors decoded with {{ if .HasErrors }}DecodeError{{ else }}goaclient.DecodeError{{ end }}.
+func (c *Client) {{ $funcName }}Decoded(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) ({{ if .Result }}result {{ .Result.TypeRef }}, {{ end }}err error) {
+	resp, err := c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = {{ if .HasErrors }}c.DecodeError(resp){{ else }}goaclient.DecodeError(c.Decoder, resp){{ end }}
+		return
+	}
+{{ if .Result }}	switch resp.StatusCode {
+	case {{ range $i, $status := .Result.Statuses }}{{ if $i }}, {{ end }}{{ $status }}{{ end }}:
+		decoded, err := c.{{ .Result.DecodeFunc }}(resp)
+		if err != nil {
+			return result, err
+		}
+{{ if .Result.Validate }}		if err := decoded.Validate(); err != nil {
+			return result, err
+		}
+{{ end }}		return decoded, nil
+	}
+{{ end }}	return
+}
+{{ end }}{{ if .Stream }}
+// {{ $funcName }}Stream makes a request to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource
+// and returns an iterator over the {{ .Stream.TypeRef }} values streamed in the response body{{ if .Stream.Validate }},
+// the values are validated as they are decoded{{ end }}. The error responses are yielded as errors
+// decoded with {{ if .HasErrors }}DecodeError{{ else }}goaclient.DecodeError{{ end }}. The iteration stops after yielding the first error.
+func (c *Client) {{ $funcName }}Stream(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) iter.Seq2[{{ .Stream.TypeRef }}, error] {
+	return func(yield func({{ .Stream.TypeRef }}, error) bool) {
+		var zero {{ .Stream.TypeRef }}
+		resp, err := c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		switch resp.StatusCode {
+		case {{ range $i, $status := .Stream.Statuses }}{{ if $i }}, {{ end }}{{ $status }}{{ end }}:
+		default:
+			defer resp.Body.Close()
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				yield(zero, {{ if .HasErrors }}c.DecodeError(resp){{ else }}goaclient.DecodeError(c.Decoder, resp){{ end }})
+			}
+			return
+		}
+		for v, err := range c.{{ .Stream.DecodeFunc }}(resp) {
+{{ if .Stream.Validate }}			if err == nil {
+				err = v.Validate()
+			}
+{{ end }}			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+{{ end }}{{ if .Pagination }}
+// {{ $funcName }}All returns an iterator over the items of all the pages of the {{ .Name }} action of
+// the {{ .ResourceName }} resource. The pages are fetched as the iteration progresses, the
+// iteration stops after yielding the first error.
+func (c *Client) {{ $funcName }}All(ctx context.Context, path string{{ if .Pagination.Params }}, {{ .Pagination.Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) iter.Seq2[{{ .Pagination.ItemType }}, error] {
+	return goaclient.Paginate(ctx, func(ctx context.Context, cursor string) ([]{{ .Pagination.ItemType }}, string, error) {
+		var cursorp *string
+		if cursor != "" {
+			cursorp = &cursor
+		}
+		resp, err := c.{{ $funcName }}(ctx, path, {{ .Pagination.ParamNames }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, "", {{ if .HasErrors }}c.DecodeError(resp){{ else }}goaclient.DecodeError(c.Decoder, resp){{ end }}
+		}
+		page, err := c.{{ .Result.DecodeFunc }}(resp)
+		if err != nil {
+			return nil, "", err
+		}
+{{ if .Result.Validate }}		if err := page.Validate(); err != nil {
+			return nil, "", err
+		}
+{{ end }}{{ if .Pagination.Items }}{{ if .Pagination.NextCursorPointer }}		var next string
+		if page.{{ .Pagination.NextCursor }} != nil {
+			next = *page.{{ .Pagination.NextCursor }}
+		}
+		return page.{{ .Pagination.Items }}, next, nil
+{{ else }}		return page.{{ .Pagination.Items }}, page.{{ .Pagination.NextCursor }}, nil
+{{ end }}{{ else }}		return page, goaclient.NextPageCursor(resp, {{ printf "%q" .Pagination.CursorParam }}), nil
+{{ end }}	})
+}
+{{ end }}`
 
 	clientsWSTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{ $desc := .Description }}{{/*
 */}}{{ if $desc }}{{ multiComment $desc }}{{ else }}// {{ $funcName }} establishes a websocket connection to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource{{ end }}
@@ -1167,6 +1580,56 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 {{ end }}	return req, nil
 }
+`
+
+	errorsTmpl = `{{ range . }}{{ $typeName := printf "%sError" (goify .Name true) }}// {{ $typeName }} is the error returned by DecodeError for {{ printf "%q" .Name }} errors.
+{{ if .Description }}{{ multiComment .Description }}
+{{ end }}{{ if .Result }}type {{ $typeName }} struct {
+	// Status is the status code of the error response.
+	Status int
+	// Body is the decoded error response body.
+	Body {{ .Result.TypeRef }}
+}
+
+// Error returns the error code and the response status.
+func (e *{{ $typeName }}) Error() string {
+	return fmt.Sprintf("%s: %d %s", {{ printf "%q" .Name }}, e.Status, http.StatusText(e.Status))
+}
+{{ else }}type {{ $typeName }} struct {
+	shogoa.ServiceError
+}
+
+// Unwrap returns the decoded error response.
+func (e *{{ $typeName }}) Unwrap() error { return e.ServiceError }
+{{ end }}
+{{ end }}// DecodeError decodes the error encoded in resp body. The returned error is one of the typed errors
+// above if the error code matches one of the errors declared in the design, or if the response
+// status and media type match an error declared with a custom media type. It is the decoded
+// *shogoa.ErrorResponse or *shogoa.ProblemDetails otherwise.
+func (c *Client) DecodeError(resp *http.Response) error {
+{{ if hasCustomErrors . }}	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+{{ range . }}{{ if .Result }}	case resp.StatusCode == {{ .Status }} && mediaType == {{ printf "%q" .ContentType }}:
+		body, err := c.{{ .Result.DecodeFunc }}(resp)
+		if err != nil {
+			return err
+		}
+{{ if .Result.Validate }}		if err := body.Validate(); err != nil {
+			return err
+		}
+{{ end }}		return &{{ goify .Name true }}Error{Status: resp.StatusCode, Body: body}
+{{ end }}{{ end }}	}
+{{ end }}	err := goaclient.DecodeError(c.Decoder, resp)
+	var serr shogoa.ServiceError
+	if !errors.As(err, &serr) {
+		return err
+	}
+	switch shogoa.ErrorCode(serr) {
+{{ range . }}{{ if not .Result }}	case {{ printf "%q" .Name }}:
+		return &{{ goify .Name true }}Error{ServiceError: serr}
+{{ end }}{{ end }}	}
+	return err
+}
 `
 
 	clientTmpl = `// Client is the {{ .API.Name }} service client.
@@ -1185,19 +1648,12 @@ func New(c goaclient.Doer) *Client {
 		Decoder: shogoa.NewHTTPDecoder(),
 	}
 
-{{ if .Encoders }}	// Setup encoders and decoders
+{{ if .Encoders }}	// Setup encoders and decoders, the default ones are also registered for "*/*"
 {{ range .Encoders }}{{/*
-*/}}	client.Encoder.Register({{ .PackageName }}.{{ .Function }}, "{{ joinStrings .MIMETypes "\", \"" }}")
+*/}}	client.Encoder.Register({{ .PackageName }}.{{ .Function }}, "{{ joinStrings .MIMETypes "\", \"" }}"{{ if .Default }}, "*/*"{{ end }})
 {{ end }}{{ range .Decoders }}{{/*
-*/}}	client.Decoder.Register({{ .PackageName }}.{{ .Function }}, "{{ joinStrings .MIMETypes "\", \"" }}")
+*/}}	client.Decoder.Register({{ .PackageName }}.{{ .Function }}, "{{ joinStrings .MIMETypes "\", \"" }}"{{ if .Default }}, "*/*"{{ end }})
 {{ end }}
-
-	// Setup default encoder and decoder
-{{ range .Encoders }}{{ if .Default }}{{/*
-*/}}	client.Encoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
-{{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
-*/}}	client.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
-{{ end }}{{ end }}
 {{ end }}	return client
 }
 
@@ -1207,6 +1663,49 @@ func New(c goaclient.Doer) *Client {
 func (c *Client) Set{{ $name }}(signer goaclient.Signer) {
 	c.{{ $name }} = signer
 }
-{{ end }}{{ end }}
+{{ if and (eq $signer "goaclient.OAuth2Signer") $security.TokenURL }}{{/*
+*/}}{{ if eq $security.Flow "application" }}
+// New{{ $name }} returns a signer for the {{ $security.SchemeName }} security scheme that requests
+// access tokens with the OAuth2 client credentials flow and refreshes them before they expire.
+func New{{ $name }}(clientID, clientSecret string, scopes ...string) goaclient.Signer {
+	return &goaclient.OAuth2Signer{
+		TokenSource: goaclient.ReuseTokenSource(&goaclient.ClientCredentialsTokenSource{
+			TokenURL:     {{ printf "%q" $security.TokenURL }},
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Scopes:       scopes,
+		}),
+	}
+}
+{{ else if eq $security.Flow "password" }}
+// New{{ $name }} returns a signer for the {{ $security.SchemeName }} security scheme that requests
+// access tokens with the OAuth2 password flow and refreshes them before they expire.
+func New{{ $name }}(clientID, clientSecret, username, password string, scopes ...string) goaclient.Signer {
+	return &goaclient.OAuth2Signer{
+		TokenSource: goaclient.ReuseTokenSource(&goaclient.PasswordTokenSource{
+			TokenURL:     {{ printf "%q" $security.TokenURL }},
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Username:     username,
+			Password:     password,
+			Scopes:       scopes,
+		}),
+	}
+}
+{{ else if eq $security.Flow "accessCode" }}
+// New{{ $name }} returns a signer for the {{ $security.SchemeName }} security scheme that requests
+// access tokens with the refresh token obtained with the OAuth2 access code flow and refreshes them
+// before they expire.
+func New{{ $name }}(clientID, clientSecret, refreshToken string) goaclient.Signer {
+	return &goaclient.OAuth2Signer{
+		TokenSource: goaclient.ReuseTokenSource(&goaclient.RefreshTokenSource{
+			TokenURL:     {{ printf "%q" $security.TokenURL }},
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RefreshToken: refreshToken,
+		}),
+	}
+}
+{{ end }}{{ end }}{{ end }}{{ end }}
 `
 )
@@ -515,6 +515,52 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with OAuth2 security schemes", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				SecuritySchemes: []*design.SecuritySchemeDefinition{
+					{
+						SchemeName: "app",
+						Kind:       design.OAuth2SecurityKind,
+						Flow:       "application",
+						TokenURL:   "https://auth.example.com/token",
+					},
+					{
+						SchemeName:       "user",
+						Kind:             design.OAuth2SecurityKind,
+						Flow:             "accessCode",
+						AuthorizationURL: "https://auth.example.com/authorize",
+						TokenURL:         "https://auth.example.com/token",
+					},
+					{
+						SchemeName:       "browser",
+						Kind:             design.OAuth2SecurityKind,
+						Flow:             "implicit",
+						AuthorizationURL: "https://auth.example.com/authorize",
+					},
+				},
+			}
+		})
+
+		It("generates the signer constructors of the flows", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := os.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func NewAppSigner(clientID, clientSecret string, scopes ...string) goaclient.Signer {"))
+			Ω(content).Should(ContainSubstring(`goaclient.ReuseTokenSource(&goaclient.ClientCredentialsTokenSource{
+			TokenURL:     "https://auth.example.com/token",`))
+			Ω(content).Should(ContainSubstring("func NewUserSigner(clientID, clientSecret, refreshToken string) goaclient.Signer {"))
+			Ω(content).Should(ContainSubstring("goaclient.ReuseTokenSource(&goaclient.RefreshTokenSource{"))
+			Ω(content).Should(ContainSubstring("func (c *Client) SetBrowserSigner(signer goaclient.Signer) {"))
+			Ω(content).ShouldNot(ContainSubstring("func NewBrowserSigner("))
+		})
+	})
+
 	Context("with an action with a user type payload", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -569,6 +615,384 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with a streamed response", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			mediaType := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"name": {Type: design.String}},
+					},
+					TypeName: "Bottle",
+				},
+				Identifier: "application/vnd.bottle+json",
+			}
+			mediaType.Views = map[string]*design.ViewDefinition{"default": {
+				AttributeDefinition: mediaType.AttributeDefinition,
+				Name:                "default",
+				Parent:              mediaType,
+			}}
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name: "list",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {
+										Name:      "OK",
+										Status:    200,
+										MediaType: mediaType.Identifier,
+										Stream:    design.StreamNDJSON,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("generates the stream decoder", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := os.ReadFile(filepath.Join(outDir, "client", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`func (c *Client) DecodeBottleStream(resp *http.Response) iter.Seq2[*Bottle, error] {
+	return goaclient.DecodeStream[*Bottle](c.Decoder, resp)
+}`))
+		})
+
+		It("does not generate the decoded action method", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := os.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).ShouldNot(ContainSubstring("ListFooDecoded"))
+		})
+
+		It("generates the stream action method", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := os.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`func (c *Client) ListFooStream(ctx context.Context, path string) iter.Seq2[*Bottle, error] {`))
+			Ω(string(content)).Should(ContainSubstring(`		case 200:
+		default:`))
+			Ω(string(content)).Should(ContainSubstring(`		for v, err := range c.DecodeBottleStream(resp) {
+			if err == nil {
+				err = v.Validate()
+			}
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}`))
+		})
+	})
+
+	Context("with an action with a success media type", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			mediaType := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"name": {Type: design.String}},
+					},
+					TypeName: "Bottle",
+				},
+				Identifier: "application/vnd.bottle+json",
+			}
+			mediaType.Views = map[string]*design.ViewDefinition{"default": {
+				AttributeDefinition: mediaType.AttributeDefinition,
+				Name:                "default",
+				Parent:              mediaType,
+			}}
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {
+										Name:      "OK",
+										Status:    200,
+										MediaType: mediaType.Identifier,
+									},
+									"NoContent": {
+										Name:   "NoContent",
+										Status: 204,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates the decoded action method", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := os.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`func (c *Client) ShowFooDecoded(ctx context.Context, path string) (result *Bottle, err error) {
+	resp, err := c.ShowFoo(ctx, path)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = goaclient.DecodeError(c.Decoder, resp)
+		return
+	}
+	switch resp.StatusCode {
+	case 200:
+		decoded, err := c.DecodeBottle(resp)
+		if err != nil {
+			return result, err
+		}
+		if err := decoded.Validate(); err != nil {
+			return result, err
+		}
+		return decoded, nil
+	}
+	return
+}`))
+		})
+	})
+
+	Context("with a paginated action", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			mediaType := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"names": {Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+							"next":  {Type: design.String},
+						},
+					},
+					TypeName: "NamePage",
+				},
+				Identifier: "application/vnd.name-page+json",
+			}
+			mediaType.Views = map[string]*design.ViewDefinition{"default": {
+				AttributeDefinition: mediaType.AttributeDefinition,
+				Name:                "default",
+				Parent:              mediaType,
+			}}
+			queryParams := &design.AttributeDefinition{
+				Type: design.Object{
+					"cursor": {Type: design.String},
+					"limit":  {Type: design.Integer},
+				},
+			}
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:        "list",
+								Params:      queryParams,
+								QueryParams: queryParams,
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {
+										Name:      "OK",
+										Status:    200,
+										MediaType: mediaType.Identifier,
+									},
+								},
+								Pagination: &design.PaginationDefinition{
+									CursorParam:   "cursor",
+									PageSizeParam: "limit",
+									NextCursor:    "next",
+									Items:         "names",
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+			listAct.Pagination.Parent = listAct
+		})
+
+		It("generates the page iterator", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := os.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`func (c *Client) ListFooAll(ctx context.Context, path string, limit *int) iter.Seq2[string, error] {
+	return goaclient.Paginate(ctx, func(ctx context.Context, cursor string) ([]string, string, error) {
+		var cursorp *string
+		if cursor != "" {
+			cursorp = &cursor
+		}
+		resp, err := c.ListFoo(ctx, path, cursorp, limit)`))
+			Ω(string(content)).Should(ContainSubstring(`		page, err := c.DecodeNamePage(resp)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := page.Validate(); err != nil {
+			return nil, "", err
+		}
+		var next string
+		if page.Next != nil {
+			next = *page.Next
+		}
+		return page.Names, next, nil
+	})
+}`))
+		})
+	})
+
+	Context("with action errors", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			stockError := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"available": {Type: design.Integer}},
+					},
+					TypeName: "StockError",
+				},
+				Identifier: "application/vnd.stock-error+json",
+			}
+			stockError.Views = map[string]*design.ViewDefinition{"default": {
+				AttributeDefinition: stockError.AttributeDefinition,
+				Name:                "default",
+				Parent:              stockError,
+			}}
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					design.CanonicalIdentifier(stockError.Identifier): stockError,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Errors: []*design.ErrorDefinition{
+									{
+										Name:        "not_found",
+										Response:    design.NotFound,
+										Status:      404,
+										Description: "Bottle not found",
+										MediaType:   design.ErrorMediaIdentifier,
+									},
+									{
+										Name:      "out_of_stock",
+										Response:  design.UnprocessableEntity,
+										Status:    422,
+										MediaType: stockError.Identifier,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+			showAct.Errors[0].Parent = showAct
+			showAct.Errors[1].Parent = showAct
+		})
+
+		It("generates the typed errors", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := os.ReadFile(filepath.Join(outDir, "client", "errors.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`// Bottle not found
+type NotFoundError struct {
+	shogoa.ServiceError
+}`))
+			Ω(string(content)).Should(ContainSubstring(`	case "not_found":
+		return &NotFoundError{ServiceError: serr}`))
+		})
+
+		It("decodes the errors with a custom media type", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := os.ReadFile(filepath.Join(outDir, "client", "errors.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`type OutOfStockError struct {
+	// Status is the status code of the error response.
+	Status int
+	// Body is the decoded error response body.
+	Body *StockError
+}`))
+			Ω(string(content)).Should(ContainSubstring(`	case resp.StatusCode == 422 && mediaType == "application/vnd.stock-error+json":
+		body, err := c.DecodeStockError(resp)`))
+			Ω(string(content)).Should(ContainSubstring(`		return &OutOfStockError{Status: resp.StatusCode, Body: body}`))
+			Ω(string(content)).ShouldNot(ContainSubstring(`case "out_of_stock":`))
+		})
+
+		It("decodes the error responses of the decoded action method with DecodeError", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := os.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`func (c *Client) ShowFooDecoded(ctx context.Context, path string) (err error) {`))
+			Ω(string(content)).Should(ContainSubstring(`		err = c.DecodeError(resp)`))
+		})
+	})
+
 	Context("with a multipartform action with a user type payload", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
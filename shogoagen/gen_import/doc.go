@@ -0,0 +1,15 @@
+/*
+Package genimport provides a generator that produces a design package from a Swagger 2.0 or
+OpenAPI 3 document. The document may be written in JSON or YAML, OpenAPI 3 documents are first
+converted to Swagger 2.0.
+
+The generated package defines the API, its security schemes, one resource per operation tag (or
+per first path segment for untagged operations), one user type per object definition and one
+media type per user type used to render responses. User types whose Go name would shadow a
+predeclared identifier, e.g. "Error", are suffixed with "Body" and multipart form payloads are
+declared as user types so that the generated packages compile. Constructs that cannot be
+expressed with the DSL such as responses with status codes that have no default response are
+skipped, the generated design is a starting point that should be reviewed before generating code
+from it.
+*/
+package genimport
@@ -0,0 +1,162 @@
+package genimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/swag"
+	"github.com/shogo82148/shogoa/shogoagen/codegen"
+	"github.com/shogo82148/shogoa/shogoagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a design package generator.
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{Package: "design"}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the design package generator.
+type Generator struct {
+	Spec     string   // Path or URL to the Swagger or OpenAPI document
+	OutDir   string   // Destination directory
+	Package  string   // Name of the generated design package
+	genfiles []string // Generated files
+}
+
+// Generate is the generator entry point. Contrary to the other generators it does not require a
+// design package and may thus be invoked directly.
+func Generate() (files []string, err error) {
+	var outDir, specPath, pkg, ver string
+
+	set := flag.NewFlagSet("import", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&specPath, "spec", "", "")
+	set.StringVar(&pkg, "pkg", "design", "")
+	set.StringVar(&ver, "version", "", "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{Spec: specPath, OutDir: outDir, Package: pkg}
+
+	return g.Generate()
+}
+
+// Generate produces the design package.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.Spec == "" {
+		return nil, fmt.Errorf("missing specification, set it with --spec")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Package == "" {
+		g.Package = "design"
+	}
+
+	doc, err := LoadSpec(g.Spec)
+	if err != nil {
+		return nil, err
+	}
+	src, err := newImporter(doc).Source(g.Package, filepath.Base(g.Spec))
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(g.OutDir, g.Package)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		g.genfiles = append(g.genfiles, dir)
+	}
+	filename := filepath.Join(dir, "design.go")
+	if err := os.WriteFile(filename, src, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, filename)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invocation of Generate.
+func (g *Generator) Cleanup() {
+	for i := len(g.genfiles) - 1; i >= 0; i-- {
+		os.Remove(g.genfiles[i])
+	}
+	g.genfiles = nil
+}
+
+// LoadSpec loads the Swagger 2.0 or OpenAPI 3 document at the given path or URL. The document may
+// be written in JSON or YAML, OpenAPI 3 documents are converted to Swagger 2.0.
+func LoadSpec(path string) (*spec.Swagger, error) {
+	b, err := swag.LoadFromFileOrHTTP(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %s", path, err)
+	}
+	if trimmed := bytes.TrimSpace(b); len(trimmed) == 0 || trimmed[0] != '{' {
+		yml, err := swag.BytesToYAMLDoc(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+		}
+		if b, err = swag.YAMLToJSON(yml); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+		}
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+	}
+	if v, ok := raw["openapi"].(string); ok {
+		if !strings.HasPrefix(v, "3.") {
+			return nil, fmt.Errorf("unsupported OpenAPI version %#v", v)
+		}
+		converted, err := convertOpenAPI3(raw)
+		if err != nil {
+			return nil, err
+		}
+		if b, err = json.Marshal(converted); err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := loads.Analyzed(json.RawMessage(b), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze %s: %s", path, err)
+	}
+	return doc.Spec(), nil
+}
+
+// Source returns the formatted source code of the design package.
+func (imp *importer) Source(pkg, source string) ([]byte, error) {
+	raw := imp.write(pkg, source)
+	src, err := format.Source(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated design: %s\n%s", err, raw)
+	}
+	return src, nil
+}
@@ -0,0 +1,194 @@
+package genimport_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/shogo82148/shogoa/shogoagen/codegen"
+	genimport "github.com/shogo82148/shogoa/shogoagen/gen_import"
+	"github.com/shogo82148/shogoa/version"
+)
+
+// compareGolden compares the content of the generated design with the golden file of the given
+// name in the testdata directory.
+func compareGolden(filename, name string) {
+	content, err := os.ReadFile(filename)
+	Ω(err).ShouldNot(HaveOccurred())
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		Ω(os.WriteFile(golden, content, 0644)).Should(Succeed())
+	}
+	expected, err := os.ReadFile(golden)
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(string(content)).Should(Equal(string(expected)))
+}
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir, spec string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = os.MkdirTemp(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		spec = ""
+	})
+
+	JustBeforeEach(func() {
+		os.Args = []string{"shogoagen", "--out=" + outDir, "--spec=" + spec, "--version=" + version.String()}
+		files, genErr = genimport.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with no specification", func() {
+		It("fails", func() {
+			Ω(genErr).Should(MatchError("missing specification, set it with --spec"))
+			Ω(files).Should(BeEmpty())
+		})
+	})
+
+	Context("with a missing specification", func() {
+		BeforeEach(func() {
+			spec = filepath.Join("testdata", "missing.json")
+		})
+
+		It("fails", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(files).Should(BeEmpty())
+		})
+	})
+
+	Context("with a Swagger 2.0 specification", func() {
+		BeforeEach(func() {
+			spec = filepath.Join("testdata", "petstore.json")
+		})
+
+		It("generates the design package", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			filename := filepath.Join(outDir, "design", "design.go")
+			Ω(files).Should(Equal([]string{filepath.Join(outDir, "design"), filename}))
+			compareGolden(filename, "petstore")
+		})
+	})
+
+	Context("with an OpenAPI 3 specification", func() {
+		BeforeEach(func() {
+			spec = filepath.Join("testdata", "todo.yaml")
+		})
+
+		It("generates the design package", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			compareGolden(filepath.Join(outDir, "design", "design.go"), "todo")
+		})
+	})
+})
+
+var _ = Describe("Generated design", func() {
+	// shogoagen runs the shogoagen command with the given arguments in dir.
+	shogoagen := func(dir string, args ...string) {
+		cmd := exec.Command("go", append([]string{"run", "github.com/shogo82148/shogoa/shogoagen"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		Ω(err).ShouldNot(HaveOccurred(), string(out))
+	}
+
+	for _, name := range []string{"petstore", "todo"} {
+		It("generates app and client packages that compile from the "+name+" design", func() {
+			if testing.Short() {
+				Skip("compiles the generated packages")
+			}
+			// The design package must be part of the module to be loaded by shogoagen.
+			dir, err := os.MkdirTemp(".", "_"+name)
+			Ω(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			golden, err := os.ReadFile(filepath.Join("testdata", name+".golden"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(os.Mkdir(filepath.Join(dir, "design"), 0755)).Should(Succeed())
+			Ω(os.WriteFile(filepath.Join(dir, "design", "design.go"), golden, 0644)).Should(Succeed())
+
+			design := "github.com/shogo82148/shogoa/shogoagen/gen_import/" + filepath.Base(dir) + "/design"
+			shogoagen(dir, "app", "--design="+design)
+			shogoagen(dir, "client", "--design="+design)
+			cmd := exec.Command("go", "vet", "./...")
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			Ω(err).ShouldNot(HaveOccurred(), string(out))
+		})
+	}
+})
+
+var _ = Describe("NewGenerator", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = os.MkdirTemp(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("uses the package name option", func() {
+		g := genimport.NewGenerator(
+			genimport.Spec(filepath.Join("testdata", "todo.yaml")),
+			genimport.OutDir(outDir),
+			genimport.Package("todo"),
+		)
+		files, err := g.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		filename := filepath.Join(outDir, "todo", "design.go")
+		Ω(files).Should(ContainElement(filename))
+		content, err := os.ReadFile(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("\npackage todo\n"))
+	})
+})
+
+var _ = Describe("LoadSpec", func() {
+	It("converts OpenAPI 3 documents", func() {
+		doc, err := genimport.LoadSpec(filepath.Join("testdata", "todo.yaml"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(doc.Swagger).Should(Equal("2.0"))
+		Ω(doc.Host).Should(Equal("eu.todo.example.com"))
+		Ω(doc.BasePath).Should(Equal("/api"))
+		Ω(doc.Schemes).Should(Equal([]string{"https", "http"}))
+		Ω(doc.Definitions).Should(HaveKey("Todo"))
+		Ω(doc.Definitions["Todo"].AllOf[0].Ref.String()).Should(Equal("#/definitions/TodoPayload"))
+		Ω(doc.Definitions["TodoPayload"].Properties["due"].Type).Should(ConsistOf("string"))
+		Ω(doc.SecurityDefinitions["bearer"].Type).Should(Equal("apiKey"))
+		Ω(doc.SecurityDefinitions["bearer"].Extensions).Should(HaveKeyWithValue("x-shogoa-jwt", true))
+		Ω(doc.SecurityDefinitions["client"].Flow).Should(Equal("application"))
+
+		op := doc.Paths.Paths["/todos"].Get
+		Ω(op.Parameters).Should(HaveLen(1))
+		Ω(op.Parameters[0].Ref.String()).Should(Equal("#/parameters/Page"))
+		body := doc.Paths.Paths["/todos"].Post.Parameters[0]
+		Ω(body.In).Should(Equal("body"))
+		Ω(body.Required).Should(BeTrue())
+		form := doc.Paths.Paths["/todos/{id}/attachments"].Post.Parameters
+		Ω(form).Should(HaveLen(3))
+		Ω(form[1].Name).Should(Equal("file"))
+		Ω(form[1].Type).Should(Equal("file"))
+	})
+
+	It("rejects unsupported versions", func() {
+		_, err := genimport.LoadSpec(filepath.Join("testdata", "unsupported.json"))
+		Ω(err).Should(MatchError(`unsupported OpenAPI version "4.0.0"`))
+	})
+})
@@ -0,0 +1,1048 @@
+package genimport
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/shogo82148/shogoa/design"
+	"github.com/shogo82148/shogoa/design/apidsl"
+	"github.com/shogo82148/shogoa/shogoagen/codegen"
+)
+
+// jwtExtension is the vendor extension that flags API key security schemes carrying JWT bearer
+// tokens. OpenAPI 3 "bearer" HTTP security schemes using the "JWT" format are converted to such
+// schemes.
+const jwtExtension = "x-shogoa-jwt"
+
+var (
+	// pathParamRegex matches the parameters of a Swagger path template.
+	pathParamRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+	// invalidWildcardRegex matches the characters that may not appear in a route wildcard name.
+	invalidWildcardRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	// invalidAPINameRegex matches the runs of characters replaced with dashes in API names.
+	invalidAPINameRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+	// httpMethods lists the HTTP methods supported by Swagger in the order they are written.
+	httpMethods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH"}
+)
+
+type (
+	// importer produces the source code of a design package from a Swagger document.
+	importer struct {
+		doc        *spec.Swagger
+		statuses   map[int]string          // HTTP status codes to response names
+		vars       map[string]bool         // Package variable names in use
+		types      map[string]*spec.Schema // User type names to schemas
+		typeVars   map[string]string       // User type names to variable names
+		typeNames  map[string]string       // User type names to design type names
+		typeQueue  []string                // User types left to write
+		mediaVars  map[string]string       // User type names to media type variable names
+		mediaQueue []string                // Media types left to write
+		secVars    map[string]string       // Security scheme names to variable names
+		usesDesign bool                    // Whether the design package identifiers are used
+	}
+
+	// operation is a Swagger operation together with its location.
+	operation struct {
+		path   string
+		method string
+		op     *spec.Operation
+		params []spec.Parameter
+	}
+
+	// resource groups the operations that belong to the same design resource.
+	resource struct {
+		name        string
+		description string
+		operations  []*operation
+	}
+)
+
+// newImporter returns an importer for the given document.
+func newImporter(doc *spec.Swagger) *importer {
+	imp := &importer{
+		doc:       doc,
+		statuses:  make(map[int]string),
+		vars:      make(map[string]bool),
+		types:     make(map[string]*spec.Schema),
+		typeVars:  make(map[string]string),
+		typeNames: make(map[string]string),
+		mediaVars: make(map[string]string),
+		secVars:   make(map[string]string),
+	}
+	for _, r := range design.NewAPIDefinition().DefaultResponses {
+		imp.statuses[r.Status] = r.Name
+	}
+	for _, name := range sortedKeys(doc.Definitions) {
+		def := doc.Definitions[name]
+		if imp.isObject(&def) {
+			imp.addType(name, &def)
+		}
+	}
+	return imp
+}
+
+// write returns the unformatted source code of the design package.
+func (imp *importer) write(pkg, source string) []byte {
+	var sec, api, res, media, types bytes.Buffer
+	imp.writeSecurities(&sec)
+	imp.writeAPI(&api)
+	imp.writeResources(&res)
+	for len(imp.mediaQueue) > 0 {
+		name := imp.mediaQueue[0]
+		imp.mediaQueue = imp.mediaQueue[1:]
+		imp.writeMediaType(&media, name)
+	}
+	for len(imp.typeQueue) > 0 {
+		name := imp.typeQueue[0]
+		imp.typeQueue = imp.typeQueue[1:]
+		imp.writeType(&types, name)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Package %s contains the API design imported from %s.\n", pkg, source)
+	buf.WriteString("// Not all the constructs of the specification can be expressed with the DSL,\n")
+	buf.WriteString("// review the design before generating code from it.\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", pkg)
+	if imp.usesDesign {
+		buf.WriteString("\t. \"github.com/shogo82148/shogoa/design\"\n")
+	}
+	buf.WriteString("\t. \"github.com/shogo82148/shogoa/design/apidsl\"\n)\n")
+	for _, b := range []*bytes.Buffer{&api, &sec, &res, &media, &types} {
+		if b.Len() > 0 {
+			buf.WriteString("\n")
+			buf.Write(b.Bytes())
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeAPI writes the API definition.
+func (imp *importer) writeAPI(w *bytes.Buffer) {
+	name := "api"
+	info := imp.doc.Info
+	if info != nil && info.Title != "" {
+		name = strings.Trim(invalidAPINameRegex.ReplaceAllString(strings.ToLower(info.Title), "-"), "-")
+	}
+	fmt.Fprintf(w, "var _ = API(%q, func() {\n", name)
+	if info != nil {
+		if info.Title != "" {
+			fmt.Fprintf(w, "Title(%q)\n", info.Title)
+		}
+		if info.Description != "" {
+			fmt.Fprintf(w, "Description(%q)\n", info.Description)
+		}
+		if info.Version != "" {
+			fmt.Fprintf(w, "Version(%q)\n", info.Version)
+		}
+		if info.TermsOfService != "" {
+			fmt.Fprintf(w, "TermsOfService(%q)\n", info.TermsOfService)
+		}
+		if c := info.Contact; c != nil {
+			w.WriteString("Contact(func() {\n")
+			if c.Name != "" {
+				fmt.Fprintf(w, "Name(%q)\n", c.Name)
+			}
+			if c.Email != "" {
+				fmt.Fprintf(w, "Email(%q)\n", c.Email)
+			}
+			if c.URL != "" {
+				fmt.Fprintf(w, "URL(%q)\n", c.URL)
+			}
+			w.WriteString("})\n")
+		}
+		if l := info.License; l != nil {
+			w.WriteString("License(func() {\n")
+			if l.Name != "" {
+				fmt.Fprintf(w, "Name(%q)\n", l.Name)
+			}
+			if l.URL != "" {
+				fmt.Fprintf(w, "URL(%q)\n", l.URL)
+			}
+			w.WriteString("})\n")
+		}
+	}
+	if imp.doc.Host != "" {
+		fmt.Fprintf(w, "Host(%q)\n", imp.doc.Host)
+	}
+	if len(imp.doc.Schemes) > 0 {
+		fmt.Fprintf(w, "Scheme(%s)\n", quoteAll(imp.doc.Schemes))
+	}
+	if bp := strings.TrimSuffix(imp.doc.BasePath, "/"); bp != "" {
+		fmt.Fprintf(w, "BasePath(%q)\n", bp)
+	}
+	if mimes := knownMIMETypes(imp.doc.Consumes); len(mimes) > 0 {
+		fmt.Fprintf(w, "Consumes(%s)\n", quoteAll(mimes))
+	}
+	if mimes := knownMIMETypes(imp.doc.Produces); len(mimes) > 0 {
+		fmt.Fprintf(w, "Produces(%s)\n", quoteAll(mimes))
+	}
+	imp.writeSecurity(w, imp.doc.Security)
+	w.WriteString("})\n")
+}
+
+// writeSecurities writes the security scheme definitions.
+func (imp *importer) writeSecurities(w *bytes.Buffer) {
+	for _, name := range sortedKeys(imp.doc.SecurityDefinitions) {
+		s := imp.doc.SecurityDefinitions[name]
+		var fn string
+		var body bytes.Buffer
+		if s.Description != "" {
+			fmt.Fprintf(&body, "Description(%q)\n", s.Description)
+		}
+		switch s.Type {
+		case "basic":
+			fn = "BasicAuthSecurity"
+		case "apiKey":
+			fn = "APIKeySecurity"
+			if jwt, _ := s.Extensions.GetBool(jwtExtension); jwt {
+				fn = "JWTSecurity"
+			}
+			if s.In == "query" {
+				fmt.Fprintf(&body, "Query(%q)\n", s.Name)
+			} else {
+				fmt.Fprintf(&body, "Header(%q)\n", s.Name)
+			}
+		case "oauth2":
+			fn = "OAuth2Security"
+			switch s.Flow {
+			case "accessCode":
+				fmt.Fprintf(&body, "AccessCodeFlow(%q, %q)\n", s.AuthorizationURL, s.TokenURL)
+			case "application":
+				fmt.Fprintf(&body, "ApplicationFlow(%q)\n", s.TokenURL)
+			case "password":
+				fmt.Fprintf(&body, "PasswordFlow(%q)\n", s.TokenURL)
+			case "implicit":
+				fmt.Fprintf(&body, "ImplicitFlow(%q)\n", s.AuthorizationURL)
+			}
+			for _, scope := range sortedKeys(s.Scopes) {
+				fmt.Fprintf(&body, "Scope(%q, %q)\n", scope, s.Scopes[scope])
+			}
+		default:
+			continue
+		}
+		v := imp.varName(name, "Security")
+		imp.secVars[name] = v
+		fmt.Fprintf(w, "var %s = %s(%q", v, fn, name)
+		if body.Len() > 0 {
+			w.WriteString(", func() {\n")
+			w.Write(body.Bytes())
+			w.WriteString("}")
+		}
+		w.WriteString(")\n\n")
+	}
+}
+
+// writeSecurity writes the Security DSL corresponding to the given security requirements. The
+// DSL supports a single scheme so only the first one is taken into account.
+func (imp *importer) writeSecurity(w *bytes.Buffer, reqs []map[string][]string) {
+	if len(reqs) == 0 {
+		return
+	}
+	for _, name := range sortedKeys(reqs[0]) {
+		v, ok := imp.secVars[name]
+		if !ok {
+			continue
+		}
+		scopes := reqs[0][name]
+		if len(scopes) == 0 {
+			fmt.Fprintf(w, "Security(%s)\n", v)
+			return
+		}
+		fmt.Fprintf(w, "Security(%s, func() {\n", v)
+		for _, s := range scopes {
+			fmt.Fprintf(w, "Scope(%q)\n", s)
+		}
+		w.WriteString("})\n")
+		return
+	}
+}
+
+// writeResources writes one resource per group of operations.
+func (imp *importer) writeResources(w *bytes.Buffer) {
+	for _, res := range imp.resources() {
+		fmt.Fprintf(w, "var _ = Resource(%q, func() {\n", res.name)
+		if res.description != "" {
+			fmt.Fprintf(w, "Description(%q)\n", res.description)
+		}
+		names := make(map[string]bool)
+		for i, o := range res.operations {
+			name := actionName(o)
+			for j := 2; names[name]; j++ {
+				name = fmt.Sprintf("%s%d", actionName(o), j)
+			}
+			names[name] = true
+			if i > 0 || res.description != "" {
+				w.WriteString("\n")
+			}
+			imp.writeAction(w, name, o)
+		}
+		w.WriteString("})\n\n")
+	}
+}
+
+// resources groups the document operations by resource. Operations are grouped by their first
+// tag or the first segment of their path if they have no tag.
+func (imp *importer) resources() []*resource {
+	descs := make(map[string]string)
+	for _, t := range imp.doc.Tags {
+		descs[resourceName(t.Name)] = t.Description
+	}
+	byName := make(map[string]*resource)
+	var paths []string
+	if imp.doc.Paths != nil {
+		paths = sortedKeys(imp.doc.Paths.Paths)
+	}
+	for _, p := range paths {
+		item := imp.doc.Paths.Paths[p]
+		ops := map[string]*spec.Operation{
+			"GET": item.Get, "PUT": item.Put, "POST": item.Post, "DELETE": item.Delete,
+			"OPTIONS": item.Options, "HEAD": item.Head, "PATCH": item.Patch,
+		}
+		for _, m := range httpMethods {
+			op := ops[m]
+			if op == nil {
+				continue
+			}
+			key := "root"
+			if len(op.Tags) > 0 {
+				key = op.Tags[0]
+			} else {
+				for _, seg := range strings.Split(p, "/") {
+					if seg != "" && !strings.HasPrefix(seg, "{") {
+						key = seg
+						break
+					}
+				}
+			}
+			name := resourceName(key)
+			res, ok := byName[name]
+			if !ok {
+				res = &resource{name: name, description: descs[name]}
+				byName[name] = res
+			}
+			res.operations = append(res.operations, &operation{
+				path:   p,
+				method: m,
+				op:     op,
+				params: imp.operationParams(item.Parameters, op.Parameters),
+			})
+		}
+	}
+	res := make([]*resource, 0, len(byName))
+	for _, name := range sortedKeys(byName) {
+		res = append(res, byName[name])
+	}
+	return res
+}
+
+// operationParams merges the path and operation parameters resolving references.
+func (imp *importer) operationParams(pathParams, opParams []spec.Parameter) []spec.Parameter {
+	var res []spec.Parameter
+	index := make(map[string]int)
+	for _, params := range [][]spec.Parameter{pathParams, opParams} {
+		for _, p := range params {
+			if ref := p.Ref.String(); ref != "" {
+				resolved, ok := imp.doc.Parameters[strings.TrimPrefix(ref, "#/parameters/")]
+				if !ok {
+					continue
+				}
+				p = resolved
+			}
+			key := p.In + ":" + p.Name
+			if i, ok := index[key]; ok {
+				res[i] = p
+				continue
+			}
+			index[key] = len(res)
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// writeAction writes the action corresponding to the given operation.
+func (imp *importer) writeAction(w *bytes.Buffer, name string, o *operation) {
+	op := o.op
+	fmt.Fprintf(w, "Action(%q, func() {\n", name)
+	if desc := description(op.Summary, op.Description); desc != "" {
+		fmt.Fprintf(w, "Description(%q)\n", desc)
+	}
+	path := pathParamRegex.ReplaceAllStringFunc(o.path, func(m string) string {
+		return ":" + wildcardName(m[1:len(m)-1])
+	})
+	fmt.Fprintf(w, "Routing(%s(%q))\n", o.method, path)
+
+	var params, headers, form []spec.Parameter
+	var body *spec.Parameter
+	for i, p := range o.params {
+		switch p.In {
+		case "path", "query":
+			params = append(params, p)
+		case "header":
+			headers = append(headers, p)
+		case "formData":
+			form = append(form, p)
+		case "body":
+			body = &o.params[i]
+		}
+	}
+	if len(params) > 0 {
+		w.WriteString("Params(func() {\n")
+		var required []string
+		for _, p := range params {
+			pname := p.Name
+			if p.In == "path" {
+				pname = wildcardName(pname)
+			}
+			imp.writeAttribute(w, "Param", pname, paramSchema(&p), false)
+			if p.Required {
+				required = append(required, pname)
+			}
+		}
+		writeRequired(w, required)
+		w.WriteString("})\n")
+	}
+	if len(headers) > 0 {
+		w.WriteString("Headers(func() {\n")
+		var required []string
+		for _, p := range headers {
+			imp.writeAttribute(w, "Header", p.Name, paramSchema(&p), false)
+			if p.Required {
+				required = append(required, p.Name)
+			}
+		}
+		writeRequired(w, required)
+		w.WriteString("})\n")
+	}
+
+	if op.Security != nil && len(op.Security) == 0 {
+		if len(imp.doc.Security) > 0 {
+			w.WriteString("NoSecurity()\n")
+		}
+	} else {
+		imp.writeSecurity(w, op.Security)
+	}
+
+	switch {
+	case body != nil && body.Schema != nil:
+		fn := "OptionalPayload"
+		if body.Required {
+			fn = "Payload"
+		}
+		hint := codegen.Goify(name, true) + "Payload"
+		if imp.isInlineObject(body.Schema) {
+			fmt.Fprintf(w, "%s(func() {\n", fn)
+			imp.writeAttributes(w, body.Schema, hint, false)
+			w.WriteString("})\n")
+		} else {
+			fmt.Fprintf(w, "%s(%s)\n", fn, imp.typeExpr(body.Schema, hint, false))
+		}
+	case len(form) > 0:
+		consumes := op.Consumes
+		if len(consumes) == 0 {
+			consumes = imp.doc.Consumes
+		}
+		multipart := false
+		for _, c := range consumes {
+			if c == "multipart/form-data" {
+				multipart = true
+			}
+		}
+		if multipart {
+			// The generated code only supports files in the attributes of user types, multipart
+			// payloads are declared as user types.
+			s := &spec.Schema{}
+			s.Type = spec.StringOrArray{"object"}
+			s.Properties = make(map[string]spec.Schema, len(form))
+			for _, p := range form {
+				s.Properties[p.Name] = *paramSchema(&p)
+				if p.Required {
+					s.Required = append(s.Required, p.Name)
+				}
+			}
+			w.WriteString("MultipartForm()\n")
+			fmt.Fprintf(w, "Payload(%s)\n", imp.typeExpr(s, codegen.Goify(name, true)+"Payload", false))
+			break
+		}
+		w.WriteString("Payload(func() {\n")
+		var required []string
+		for _, p := range form {
+			s := paramSchema(&p)
+			if p.Type == "file" {
+				s.Type = spec.StringOrArray{"string"}
+			}
+			imp.writeAttribute(w, "Attribute", p.Name, s, false)
+			if p.Required {
+				required = append(required, p.Name)
+			}
+		}
+		writeRequired(w, required)
+		w.WriteString("})\n")
+	}
+
+	if op.Responses != nil {
+		codes := make([]int, 0, len(op.Responses.StatusCodeResponses))
+		for code := range op.Responses.StatusCodeResponses {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			imp.writeResponse(w, code, op.Responses.StatusCodeResponses[code])
+		}
+	}
+	w.WriteString("})\n")
+}
+
+// writeResponse writes the response with the given status code. Responses whose status code does
+// not have a corresponding default response are skipped.
+func (imp *importer) writeResponse(w *bytes.Buffer, code int, r spec.Response) {
+	name, ok := imp.statuses[code]
+	if !ok {
+		return
+	}
+	if ref := r.Ref.String(); ref != "" {
+		resolved, ok := imp.doc.Responses[strings.TrimPrefix(ref, "#/responses/")]
+		if !ok {
+			return
+		}
+		r = resolved
+	}
+	imp.usesDesign = true
+	args := []string{name}
+	if r.Schema != nil {
+		if mt := imp.mediaExpr(r.Schema); mt != "" {
+			args = append(args, mt)
+		}
+	}
+	var body bytes.Buffer
+	if r.Description != "" && r.Description != http.StatusText(code) {
+		fmt.Fprintf(&body, "Description(%q)\n", r.Description)
+	}
+	if len(r.Headers) > 0 {
+		body.WriteString("Headers(func() {\n")
+		for _, h := range sortedKeys(r.Headers) {
+			header := r.Headers[h]
+			s := simpleSchema(&header.SimpleSchema, &header.CommonValidations)
+			s.Description = header.Description
+			imp.writeAttribute(&body, "Header", h, s, false)
+		}
+		body.WriteString("})\n")
+	}
+	if body.Len() > 0 {
+		args = append(args, "func() {\n"+body.String()+"}")
+	}
+	fmt.Fprintf(w, "Response(%s)\n", strings.Join(args, ", "))
+}
+
+// mediaExpr returns the expression of the media type used to render responses described by the
+// given schema. It returns an empty string if the schema does not describe a user type or a
+// collection of user types.
+func (imp *importer) mediaExpr(s *spec.Schema) string {
+	if name := refName(s); name != "" {
+		if _, ok := imp.types[name]; ok {
+			return imp.media(name)
+		}
+		def, ok := imp.doc.Definitions[name]
+		if !ok || def.Items == nil || def.Items.Schema == nil {
+			return ""
+		}
+		s = &def
+	}
+	if s.Type.Contains("array") && s.Items != nil && s.Items.Schema != nil {
+		if name := refName(s.Items.Schema); name != "" {
+			if _, ok := imp.types[name]; ok {
+				return "CollectionOf(" + imp.media(name) + ")"
+			}
+		}
+	}
+	return ""
+}
+
+// media returns the name of the variable holding the media type that renders the given user
+// type, scheduling the media type for writing if needed.
+func (imp *importer) media(name string) string {
+	if v, ok := imp.mediaVars[name]; ok {
+		return v
+	}
+	v := imp.varName(name+"Media", "Type")
+	imp.mediaVars[name] = v
+	imp.mediaQueue = append(imp.mediaQueue, name)
+	return v
+}
+
+// writeMediaType writes the media type that renders the given user type.
+func (imp *importer) writeMediaType(w *bytes.Buffer, name string) {
+	s := imp.types[name]
+	v := imp.mediaVars[name]
+	props, required := imp.flatten(s)
+	identifier := "application/vnd." + codegen.KebabCase(codegen.Goify(name, false)) + "+json"
+	fmt.Fprintf(w, "var %s = MediaType(%q, func() {\n", v, identifier)
+	if s.Description != "" {
+		fmt.Fprintf(w, "Description(%q)\n", s.Description)
+	}
+	fmt.Fprintf(w, "TypeName(%q)\n", v)
+	fmt.Fprintf(w, "Reference(%s)\n", imp.typeVars[name])
+	w.WriteString("Attributes(func() {\n")
+	for _, p := range sortedKeys(props) {
+		fmt.Fprintf(w, "Attribute(%q)\n", p)
+	}
+	writeRequired(w, required)
+	w.WriteString("})\n")
+	w.WriteString("View(\"default\", func() {\n")
+	for _, p := range sortedKeys(props) {
+		fmt.Fprintf(w, "Attribute(%q)\n", p)
+	}
+	w.WriteString("})\n")
+	w.WriteString("})\n\n")
+}
+
+// writeType writes the user type with the given name. User types refer to other user types by
+// name so that recursive definitions do not cause initialization cycles.
+func (imp *importer) writeType(w *bytes.Buffer, name string) {
+	s := imp.types[name]
+	fmt.Fprintf(w, "var %s = Type(%q, func() {\n", imp.typeVars[name], imp.typeNames[name])
+	if s.Description != "" {
+		fmt.Fprintf(w, "Description(%q)\n", s.Description)
+	}
+	imp.writeAttributes(w, s, name, true)
+	w.WriteString("})\n\n")
+}
+
+// writeAttributes writes the attributes of the given object schema.
+func (imp *importer) writeAttributes(w *bytes.Buffer, s *spec.Schema, hint string, byName bool) {
+	props, required := imp.flatten(s)
+	for _, p := range sortedKeys(props) {
+		imp.writeAttribute(w, "Attribute", p, props[p], byName, hint+codegen.Goify(p, true))
+	}
+	writeRequired(w, required)
+}
+
+// writeAttribute writes an attribute using the given DSL function (Attribute, Param or Header).
+func (imp *importer) writeAttribute(w *bytes.Buffer, fn, name string, s *spec.Schema, byName bool, hint ...string) {
+	h := codegen.Goify(name, true)
+	if len(hint) > 0 {
+		h = hint[0]
+	}
+	if imp.isInlineObject(s) {
+		fmt.Fprintf(w, "%s(%q, func() {\n", fn, name)
+		if s.Description != "" {
+			fmt.Fprintf(w, "Description(%q)\n", s.Description)
+		}
+		imp.writeAttributes(w, s, h, byName)
+		w.WriteString("})\n")
+		return
+	}
+	args := []string{strconv.Quote(name), imp.typeExpr(s, h, byName)}
+	desc := s.Description
+	if desc == "" && refName(s) == "" {
+		desc = s.Title
+	}
+	if desc != "" {
+		args = append(args, strconv.Quote(desc))
+	}
+	if v := imp.validations(s); v != "" {
+		args = append(args, "func() {\n"+v+"}")
+	}
+	fmt.Fprintf(w, "%s(%s)\n", fn, strings.Join(args, ", "))
+}
+
+// validations returns the validation DSL corresponding to the given schema.
+func (imp *importer) validations(s *spec.Schema) string {
+	if refName(s) != "" {
+		return ""
+	}
+	var b bytes.Buffer
+	kind := schemaKind(s)
+	if len(s.Enum) > 0 {
+		vals := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			if lit := literal(e, kind); lit != "" {
+				vals = append(vals, lit)
+			}
+		}
+		if len(vals) > 0 {
+			fmt.Fprintf(&b, "Enum(%s)\n", strings.Join(vals, ", "))
+		}
+	}
+	if kind == "string" && s.Format != "" && s.Format != "date-time" && s.Format != "uuid" {
+		for _, f := range apidsl.SupportedValidationFormats {
+			if f == s.Format {
+				fmt.Fprintf(&b, "Format(%q)\n", f)
+				break
+			}
+		}
+	}
+	if kind == "string" && s.Pattern != "" {
+		fmt.Fprintf(&b, "Pattern(%q)\n", s.Pattern)
+	}
+	if kind == "integer" || kind == "number" {
+		if s.Minimum != nil {
+			fmt.Fprintf(&b, "Minimum(%s)\n", literal(*s.Minimum, kind))
+		}
+		if s.Maximum != nil {
+			fmt.Fprintf(&b, "Maximum(%s)\n", literal(*s.Maximum, kind))
+		}
+	}
+	minLen, maxLen := s.MinLength, s.MaxLength
+	if kind == "array" {
+		minLen, maxLen = s.MinItems, s.MaxItems
+	}
+	if kind == "string" || kind == "array" {
+		if minLen != nil {
+			fmt.Fprintf(&b, "MinLength(%d)\n", *minLen)
+		}
+		if maxLen != nil {
+			fmt.Fprintf(&b, "MaxLength(%d)\n", *maxLen)
+		}
+	}
+	if s.Default != nil {
+		if lit := literal(s.Default, kind); lit != "" {
+			fmt.Fprintf(&b, "Default(%s)\n", lit)
+		}
+	}
+	if s.Example != nil {
+		if lit := literal(s.Example, kind); lit != "" {
+			fmt.Fprintf(&b, "Example(%s)\n", lit)
+		}
+	}
+	return b.String()
+}
+
+// typeExpr returns the expression of the data type described by the given schema. User types are
+// referred to by name if byName is true, by variable otherwise. Inline object schemas are turned
+// into user types named after hint.
+func (imp *importer) typeExpr(s *spec.Schema, hint string, byName bool) string {
+	if name := refName(s); name != "" {
+		if _, ok := imp.types[name]; ok {
+			if byName {
+				return strconv.Quote(imp.typeNames[name])
+			}
+			return imp.typeVars[name]
+		}
+		def, ok := imp.doc.Definitions[name]
+		if !ok || refName(&def) != "" {
+			return imp.design("Any")
+		}
+		return imp.typeExpr(&def, codegen.Goify(name, true), byName)
+	}
+	if len(s.AllOf) == 1 && len(s.Properties) == 0 {
+		return imp.typeExpr(&s.AllOf[0], hint, byName)
+	}
+	if imp.isObject(s) {
+		name := hint
+		for i := 2; imp.types[name] != nil; i++ {
+			name = fmt.Sprintf("%s%d", hint, i)
+		}
+		imp.addType(name, s)
+		if byName {
+			return strconv.Quote(imp.typeNames[name])
+		}
+		return imp.typeVars[name]
+	}
+	switch schemaKind(s) {
+	case "array":
+		elem := imp.design("Any")
+		if s.Items != nil && s.Items.Schema != nil {
+			elem = imp.typeExpr(s.Items.Schema, hint+"Item", byName)
+		}
+		return "ArrayOf(" + elem + ")"
+	case "object":
+		elem := imp.design("Any")
+		if ap := s.AdditionalProperties; ap != nil && ap.Schema != nil {
+			elem = imp.typeExpr(ap.Schema, hint+"Value", byName)
+		}
+		return "HashOf(" + imp.design("String") + ", " + elem + ")"
+	case "integer":
+		return imp.design("Integer")
+	case "number":
+		return imp.design("Number")
+	case "boolean":
+		return imp.design("Boolean")
+	case "file":
+		return imp.design("File")
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return imp.design("DateTime")
+		case "uuid":
+			return imp.design("UUID")
+		}
+		return imp.design("String")
+	}
+	return imp.design("Any")
+}
+
+// design records that the generated code uses the given identifier of the design package.
+func (imp *importer) design(identifier string) string {
+	imp.usesDesign = true
+	return identifier
+}
+
+// isObject returns true if the given schema describes an object with properties.
+func (imp *importer) isObject(s *spec.Schema) bool {
+	if len(s.Properties) > 0 {
+		return true
+	}
+	if len(s.AllOf) > 1 || (len(s.AllOf) == 1 && len(s.Properties) > 0) {
+		return true
+	}
+	return len(s.AllOf) == 1 && refName(s) == "" && imp.isObject(imp.resolve(&s.AllOf[0]))
+}
+
+// isInlineObject returns true if the given schema describes an object with properties and is not
+// a reference.
+func (imp *importer) isInlineObject(s *spec.Schema) bool {
+	return refName(s) == "" && len(s.AllOf) == 0 && imp.isObject(s)
+}
+
+// flatten returns the properties and required properties of the given object schema, merging
+// the properties of the schemas listed in allOf.
+func (imp *importer) flatten(s *spec.Schema) (map[string]*spec.Schema, []string) {
+	props := make(map[string]*spec.Schema)
+	var required []string
+	seen := make(map[string]bool)
+	var visit func(s *spec.Schema, depth int)
+	visit = func(s *spec.Schema, depth int) {
+		if depth > 16 {
+			return
+		}
+		s = imp.resolve(s)
+		for i := range s.AllOf {
+			visit(&s.AllOf[i], depth+1)
+		}
+		for _, p := range sortedKeys(s.Properties) {
+			prop := s.Properties[p]
+			props[p] = &prop
+		}
+		for _, r := range s.Required {
+			if !seen[r] {
+				seen[r] = true
+				required = append(required, r)
+			}
+		}
+	}
+	visit(s, 0)
+	return props, required
+}
+
+// resolve returns the definition referred to by s or s if it isn't a reference.
+func (imp *importer) resolve(s *spec.Schema) *spec.Schema {
+	if name := refName(s); name != "" {
+		if def, ok := imp.doc.Definitions[name]; ok {
+			return &def
+		}
+	}
+	return s
+}
+
+// addType records a user type and returns the name of its variable.
+func (imp *importer) addType(name string, s *spec.Schema) string {
+	v := imp.varName(name, "Type")
+	imp.types[name] = s
+	imp.typeVars[name] = v
+	imp.typeNames[name] = imp.typeName(name)
+	imp.typeQueue = append(imp.typeQueue, name)
+	return v
+}
+
+// typeName returns the design name of the user type with the given name. The generated packages
+// declare the user types with the unexported Go form of their name, names that would shadow a
+// predeclared Go identifier, e.g. "Error", are suffixed with "Body".
+func (imp *importer) typeName(name string) string {
+	if !predeclared[codegen.Goify(name, false)] {
+		return name
+	}
+	used := func(n string) bool {
+		if _, ok := imp.doc.Definitions[n]; ok {
+			return true
+		}
+		if imp.types[n] != nil {
+			return true
+		}
+		for _, tn := range imp.typeNames {
+			if tn == n {
+				return true
+			}
+		}
+		return false
+	}
+	n := name + "Body"
+	for i := 2; used(n); i++ {
+		n = fmt.Sprintf("%sBody%d", name, i)
+	}
+	return n
+}
+
+// varName returns a unique package variable name computed from name. The suffix is appended to
+// names that clash with other variables or with the identifiers of the dot imported packages.
+func (imp *importer) varName(name, suffix string) string {
+	base := codegen.Goify(name, true)
+	if base == "" {
+		base = suffix
+	}
+	v := base
+	if imp.vars[v] || reserved[v] {
+		v = base + suffix
+	}
+	for i := 2; imp.vars[v] || reserved[v]; i++ {
+		v = fmt.Sprintf("%s%s%d", base, suffix, i)
+	}
+	imp.vars[v] = true
+	return v
+}
+
+// paramSchema returns the schema equivalent to a non-body parameter.
+func paramSchema(p *spec.Parameter) *spec.Schema {
+	s := simpleSchema(&p.SimpleSchema, &p.CommonValidations)
+	s.Description = p.Description
+	return s
+}
+
+// simpleSchema returns the schema equivalent to the given simple schema and validations.
+func simpleSchema(ss *spec.SimpleSchema, v *spec.CommonValidations) *spec.Schema {
+	s := &spec.Schema{}
+	if ss.Type != "" {
+		s.Type = spec.StringOrArray{ss.Type}
+	}
+	s.Format = ss.Format
+	s.Default = ss.Default
+	s.Example = ss.Example
+	s.Maximum = v.Maximum
+	s.Minimum = v.Minimum
+	s.MaxLength = v.MaxLength
+	s.MinLength = v.MinLength
+	s.Pattern = v.Pattern
+	s.MaxItems = v.MaxItems
+	s.MinItems = v.MinItems
+	s.Enum = v.Enum
+	if ss.Items != nil {
+		s.Items = &spec.SchemaOrArray{Schema: simpleSchema(&ss.Items.SimpleSchema, &ss.Items.CommonValidations)}
+	}
+	return s
+}
+
+// schemaKind returns the JSON type of the given schema.
+func schemaKind(s *spec.Schema) string {
+	for _, t := range s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	switch {
+	case s.Items != nil:
+		return "array"
+	case s.AdditionalProperties != nil:
+		return "object"
+	}
+	return ""
+}
+
+// refName returns the name of the definition referred to by s, if any.
+func refName(s *spec.Schema) string {
+	return strings.TrimPrefix(s.Ref.String(), "#/definitions/")
+}
+
+// resourceName computes the name of a resource from a tag or a path segment.
+func resourceName(key string) string {
+	name := codegen.SnakeCase(codegen.Goify(key, false))
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+// actionName computes the name of the action corresponding to the given operation.
+func actionName(o *operation) string {
+	if o.op.ID != "" {
+		return codegen.SnakeCase(codegen.Goify(o.op.ID, false))
+	}
+	parts := []string{strings.ToLower(o.method)}
+	for _, seg := range strings.Split(o.path, "/") {
+		if seg == "" {
+			continue
+		}
+		if m := pathParamRegex.FindStringSubmatch(seg); m != nil {
+			parts = append(parts, "by", wildcardName(m[1]))
+			continue
+		}
+		parts = append(parts, seg)
+	}
+	return strings.ToLower(wildcardName(strings.Join(parts, "_")))
+}
+
+// wildcardName returns a valid route wildcard name for the given path parameter name.
+func wildcardName(name string) string {
+	return invalidWildcardRegex.ReplaceAllString(name, "_")
+}
+
+// description returns the description of an operation built from its summary and description.
+func description(summary, desc string) string {
+	switch {
+	case summary == "":
+		return desc
+	case desc == "" || desc == summary:
+		return summary
+	}
+	return summary + "\n\n" + desc
+}
+
+// literal returns the Go literal of a JSON value for an attribute of the given kind. It returns an
+// empty string for values that cannot be represented.
+func literal(v any, kind string) string {
+	switch actual := v.(type) {
+	case string:
+		return strconv.Quote(actual)
+	case bool:
+		return strconv.FormatBool(actual)
+	case float64:
+		if kind == "integer" && actual == float64(int64(actual)) {
+			return strconv.FormatInt(int64(actual), 10)
+		}
+		lit := strconv.FormatFloat(actual, 'g', -1, 64)
+		if kind == "number" && !strings.ContainsAny(lit, ".eE") {
+			lit += ".0"
+		}
+		return lit
+	case int64:
+		return strconv.FormatInt(actual, 10)
+	case int:
+		return strconv.Itoa(actual)
+	}
+	return ""
+}
+
+// writeRequired writes the Required DSL listing the given attribute names.
+func writeRequired(w *bytes.Buffer, required []string) {
+	if len(required) > 0 {
+		fmt.Fprintf(w, "Required(%s)\n", quoteAll(required))
+	}
+}
+
+// knownMIMETypes returns the MIME types that have a default encoder.
+func knownMIMETypes(mimes []string) []string {
+	var res []string
+	for _, m := range mimes {
+		if design.HasKnownEncoder(m) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// quoteAll returns the comma separated list of the quoted values.
+func quoteAll(vals []string) string {
+	quoted := make([]string, len(vals))
+	for i, v := range vals {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,469 @@
+package genimport
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// convertOpenAPI3 converts the generic representation of an OpenAPI 3 document into the
+// equivalent Swagger 2.0 representation. Constructs that have no Swagger 2.0 equivalent (cookie
+// parameters, OpenID Connect security schemes, links, callbacks etc.) are dropped.
+func convertOpenAPI3(doc map[string]any) (map[string]any, error) {
+	c := &converter{doc: doc}
+	res := map[string]any{"swagger": "2.0"}
+	for _, k := range []string{"info", "tags", "externalDocs", "security"} {
+		if v, ok := doc[k]; ok {
+			res[k] = v
+		}
+	}
+	if err := c.servers(res); err != nil {
+		return nil, err
+	}
+
+	comps := object(doc["components"])
+	if schemas := object(comps["schemas"]); len(schemas) > 0 {
+		defs := make(map[string]any, len(schemas))
+		for n, s := range schemas {
+			defs[n] = c.schema(s)
+		}
+		res["definitions"] = defs
+	}
+	if params := object(comps["parameters"]); len(params) > 0 {
+		defs := make(map[string]any, len(params))
+		for n, p := range params {
+			if cp := c.parameter(p); cp != nil {
+				defs[n] = cp
+			}
+		}
+		res["parameters"] = defs
+	}
+	if resps := object(comps["responses"]); len(resps) > 0 {
+		defs := make(map[string]any, len(resps))
+		for n, r := range resps {
+			defs[n] = c.response(r)
+		}
+		res["responses"] = defs
+	}
+	if schemes := object(comps["securitySchemes"]); len(schemes) > 0 {
+		defs := make(map[string]any, len(schemes))
+		for n, s := range schemes {
+			if cs := c.securityScheme(s); cs != nil {
+				defs[n] = cs
+			}
+		}
+		res["securityDefinitions"] = defs
+	}
+
+	paths := make(map[string]any)
+	for p, item := range object(doc["paths"]) {
+		paths[p] = c.pathItem(object(item))
+	}
+	res["paths"] = paths
+	if len(c.consumes) > 0 {
+		res["consumes"] = sortedKeys(c.consumes)
+	}
+	if len(c.produces) > 0 {
+		res["produces"] = sortedKeys(c.produces)
+	}
+
+	return res, nil
+}
+
+// converter holds the state needed while converting an OpenAPI 3 document.
+type converter struct {
+	doc      map[string]any
+	consumes map[string]bool
+	produces map[string]bool
+}
+
+// servers computes the schemes, host and base path from the document servers. Only the servers
+// sharing the host of the first server are taken into account.
+func (c *converter) servers(res map[string]any) error {
+	servers, _ := c.doc["servers"].([]any)
+	var schemes []string
+	for i, s := range servers {
+		so := object(s)
+		raw, _ := so["url"].(string)
+		for n, v := range object(so["variables"]) {
+			def, _ := object(v)["default"].(string)
+			raw = strings.ReplaceAll(raw, "{"+n+"}", def)
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("invalid server URL %#v: %s", raw, err)
+		}
+		if i == 0 {
+			if u.Host != "" {
+				res["host"] = u.Host
+			}
+			if p := strings.TrimSuffix(u.Path, "/"); p != "" {
+				res["basePath"] = p
+			}
+		} else if u.Host != res["host"] {
+			continue
+		}
+		if u.Scheme != "" {
+			schemes = append(schemes, u.Scheme)
+		}
+	}
+	if len(schemes) > 0 {
+		res["schemes"] = schemes
+	}
+	return nil
+}
+
+// pathItem converts a path item object.
+func (c *converter) pathItem(item map[string]any) map[string]any {
+	res := make(map[string]any)
+	if params := c.parameters(item["parameters"]); len(params) > 0 {
+		res["parameters"] = params
+	}
+	for _, m := range []string{"get", "put", "post", "delete", "options", "head", "patch"} {
+		if op, ok := item[m]; ok {
+			res[m] = c.operation(object(op))
+		}
+	}
+	return res
+}
+
+// operation converts an operation object.
+func (c *converter) operation(op map[string]any) map[string]any {
+	res := make(map[string]any)
+	for _, k := range []string{"tags", "summary", "description", "operationId", "deprecated", "security"} {
+		if v, ok := op[k]; ok {
+			res[k] = v
+		}
+	}
+	params := c.parameters(op["parameters"])
+	if body, ok := op["requestBody"]; ok {
+		consumes, bparams := c.requestBody(object(body))
+		if len(consumes) > 0 {
+			res["consumes"] = consumes
+		}
+		params = append(params, bparams...)
+	}
+	if len(params) > 0 {
+		res["parameters"] = params
+	}
+	resps := make(map[string]any)
+	for code, r := range object(op["responses"]) {
+		resps[code] = c.response(r)
+	}
+	res["responses"] = resps
+	return res
+}
+
+// parameters converts a list of parameter objects.
+func (c *converter) parameters(v any) []any {
+	list, _ := v.([]any)
+	var res []any
+	for _, p := range list {
+		if cp := c.parameter(p); cp != nil {
+			res = append(res, cp)
+		}
+	}
+	return res
+}
+
+// parameter converts a parameter object, it returns nil for cookie parameters.
+func (c *converter) parameter(v any) map[string]any {
+	p := object(v)
+	if ref, ok := p["$ref"].(string); ok {
+		return map[string]any{"$ref": strings.Replace(ref, "#/components/parameters/", "#/parameters/", 1)}
+	}
+	if p["in"] == "cookie" {
+		return nil
+	}
+	res := make(map[string]any)
+	for _, k := range []string{"name", "in", "description", "required"} {
+		if v, ok := p[k]; ok {
+			res[k] = v
+		}
+	}
+	for k, v := range c.simpleSchema(p["schema"]) {
+		res[k] = v
+	}
+	return res
+}
+
+// requestBody converts a request body object into the list of consumed MIME types and the
+// corresponding body or form parameters.
+func (c *converter) requestBody(body map[string]any) ([]string, []any) {
+	if ref, ok := body["$ref"].(string); ok {
+		body = object(c.resolve(ref))
+	}
+	content := object(body["content"])
+	if len(content) == 0 {
+		return nil, nil
+	}
+	consumes := sortedKeys(content)
+	if c.consumes == nil {
+		c.consumes = make(map[string]bool)
+	}
+	for _, m := range consumes {
+		c.consumes[m] = true
+	}
+	required, _ := body["required"].(bool)
+	for _, mime := range consumes {
+		if mime != "multipart/form-data" && mime != "application/x-www-form-urlencoded" {
+			continue
+		}
+		schema := object(object(content[mime])["schema"])
+		if ref, ok := schema["$ref"].(string); ok {
+			schema = object(c.resolve(ref))
+		}
+		req := make(map[string]bool)
+		if list, ok := schema["required"].([]any); ok {
+			for _, r := range list {
+				if n, ok := r.(string); ok {
+					req[n] = true
+				}
+			}
+		}
+		props := object(schema["properties"])
+		var params []any
+		for _, n := range sortedKeys(props) {
+			param := map[string]any{"name": n, "in": "formData"}
+			for k, v := range c.simpleSchema(props[n]) {
+				param[k] = v
+			}
+			if param["format"] == "binary" {
+				param["type"] = "file"
+				delete(param, "format")
+			}
+			if req[n] {
+				param["required"] = true
+			}
+			params = append(params, param)
+		}
+		return []string{mime}, params
+	}
+
+	param := map[string]any{"name": "payload", "in": "body", "required": required}
+	if desc, ok := body["description"]; ok {
+		param["description"] = desc
+	}
+	param["schema"] = c.schema(object(content[preferJSON(consumes)])["schema"])
+	return consumes, []any{param}
+}
+
+// response converts a response object.
+func (c *converter) response(v any) map[string]any {
+	r := object(v)
+	if ref, ok := r["$ref"].(string); ok {
+		return map[string]any{"$ref": strings.Replace(ref, "#/components/responses/", "#/responses/", 1)}
+	}
+	desc, _ := r["description"].(string)
+	res := map[string]any{"description": desc}
+	content := object(r["content"])
+	if len(content) > 0 {
+		mimes := sortedKeys(content)
+		if c.produces == nil {
+			c.produces = make(map[string]bool)
+		}
+		for _, m := range mimes {
+			c.produces[m] = true
+		}
+		if s, ok := object(content[preferJSON(mimes)])["schema"]; ok {
+			res["schema"] = c.schema(s)
+		}
+	}
+	if headers := object(r["headers"]); len(headers) > 0 {
+		hs := make(map[string]any, len(headers))
+		for n, h := range headers {
+			ho := object(h)
+			if ref, ok := ho["$ref"].(string); ok {
+				ho = object(c.resolve(ref))
+			}
+			ch := c.simpleSchema(ho["schema"])
+			if d, ok := ho["description"]; ok {
+				ch["description"] = d
+			}
+			hs[n] = ch
+		}
+		res["headers"] = hs
+	}
+	return res
+}
+
+// securityScheme converts a security scheme object, it returns nil for schemes that cannot be
+// represented in Swagger 2.0.
+func (c *converter) securityScheme(v any) map[string]any {
+	s := object(v)
+	res := make(map[string]any)
+	if d, ok := s["description"]; ok {
+		res["description"] = d
+	}
+	switch s["type"] {
+	case "http":
+		scheme, _ := s["scheme"].(string)
+		switch strings.ToLower(scheme) {
+		case "basic":
+			res["type"] = "basic"
+		case "bearer":
+			res["type"] = "apiKey"
+			res["in"] = "header"
+			res["name"] = "Authorization"
+			if format, _ := s["bearerFormat"].(string); strings.EqualFold(format, "JWT") {
+				res[jwtExtension] = true
+			}
+		default:
+			return nil
+		}
+	case "apiKey":
+		if s["in"] == "cookie" {
+			return nil
+		}
+		res["type"] = "apiKey"
+		res["in"] = s["in"]
+		res["name"] = s["name"]
+	case "oauth2":
+		flows := object(s["flows"])
+		for _, f := range []struct{ oas3, swagger string }{
+			{"authorizationCode", "accessCode"},
+			{"clientCredentials", "application"},
+			{"password", "password"},
+			{"implicit", "implicit"},
+		} {
+			flow, ok := flows[f.oas3]
+			if !ok {
+				continue
+			}
+			fo := object(flow)
+			res["type"] = "oauth2"
+			res["flow"] = f.swagger
+			for _, k := range []string{"authorizationUrl", "tokenUrl", "scopes"} {
+				if v, ok := fo[k]; ok {
+					res[k] = v
+				}
+			}
+			return res
+		}
+		return nil
+	default:
+		return nil
+	}
+	return res
+}
+
+// simpleSchema converts a schema into the fields used by Swagger 2.0 non-body parameters,
+// headers and items.
+func (c *converter) simpleSchema(v any) map[string]any {
+	s := object(v)
+	if ref, ok := s["$ref"].(string); ok {
+		s = object(c.resolve(ref))
+	}
+	s = object(c.schema(s))
+	res := make(map[string]any)
+	for k, v := range s {
+		switch k {
+		case "$ref", "properties", "additionalProperties", "allOf", "oneOf", "anyOf", "required":
+		case "items":
+			res[k] = c.simpleSchema(v)
+		default:
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// schema converts a schema object. It rewrites the references to the component schemas and the
+// JSON schema constructs that Swagger 2.0 does not support.
+func (c *converter) schema(v any) any {
+	switch actual := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(actual))
+		for k, v := range actual {
+			switch k {
+			case "$ref":
+				if ref, ok := v.(string); ok {
+					res[k] = strings.Replace(ref, "#/components/schemas/", "#/definitions/", 1)
+				}
+			case "type":
+				res[k] = schemaType(v)
+			case "const":
+				res["enum"] = []any{v}
+			case "nullable", "writeOnly", "deprecated", "discriminator", "xml":
+			case "properties", "patternProperties", "$defs":
+				props := object(v)
+				cp := make(map[string]any, len(props))
+				for n, p := range props {
+					cp[n] = c.schema(p)
+				}
+				res[k] = cp
+			default:
+				res[k] = c.schema(v)
+			}
+		}
+		return res
+	case []any:
+		res := make([]any, len(actual))
+		for i, e := range actual {
+			res[i] = c.schema(e)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// resolve returns the value pointed to by a local JSON reference.
+func (c *converter) resolve(ref string) any {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var cur any = c.doc
+	for _, tok := range strings.Split(ref[2:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		cur = object(cur)[tok]
+	}
+	return cur
+}
+
+// schemaType converts OpenAPI 3.1 type arrays, the "null" type is dropped since design types
+// cannot express nullability.
+func schemaType(v any) any {
+	list, ok := v.([]any)
+	if !ok {
+		return v
+	}
+	var types []any
+	for _, t := range list {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return types
+}
+
+// preferJSON returns the first JSON MIME type of the given list or the first MIME type if there
+// is none.
+func preferJSON(mimes []string) string {
+	for _, m := range mimes {
+		if strings.Contains(m, "json") {
+			return m
+		}
+	}
+	return mimes[0]
+}
+
+// object returns v as a JSON object or nil if it isn't one.
+func object(v any) map[string]any {
+	o, _ := v.(map[string]any)
+	return o
+}
+
+// sortedKeys returns the keys of m in alphabetical order.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,25 @@
+package genimport
+
+// Option a generator option definition
+type Option func(*Generator)
+
+// Spec Path or URL to the Swagger or OpenAPI document
+func Spec(spec string) Option {
+	return func(g *Generator) {
+		g.Spec = spec
+	}
+}
+
+// OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+// Package Name of the generated design package
+func Package(pkg string) Option {
+	return func(g *Generator) {
+		g.Package = pkg
+	}
+}
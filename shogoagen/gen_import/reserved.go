@@ -0,0 +1,277 @@
+package genimport
+
+// reserved lists the exported identifiers of the dot imported design and apidsl packages, package
+// variables of the generated design must not use these names.
+var reserved = map[string]bool{
+	"API":                          true,
+	"APIDefinition":                true,
+	"APIKeySecurity":               true,
+	"APIKeySecurityKind":           true,
+	"Accepted":                     true,
+	"AccessCodeFlow":               true,
+	"Action":                       true,
+	"ActionDefinition":             true,
+	"ActionIterator":               true,
+	"Any":                          true,
+	"AnyKind":                      true,
+	"ApplicationFlow":              true,
+	"Array":                        true,
+	"ArrayKind":                    true,
+	"ArrayOf":                      true,
+	"ArrayVal":                     true,
+	"Attribute":                    true,
+	"AttributeDefinition":          true,
+	"Attributes":                   true,
+	"BadGateway":                   true,
+	"BadRequest":                   true,
+	"BasePath":                     true,
+	"BasicAuthSecurity":            true,
+	"BasicAuthSecurityKind":        true,
+	"Boolean":                      true,
+	"BooleanKind":                  true,
+	"ByFilePath":                   true,
+	"CONNECT":                      true,
+	"CORSDefinition":               true,
+	"CacheControl":                 true,
+	"Cacheable":                    true,
+	"CanonicalActionName":          true,
+	"CanonicalIdentifier":          true,
+	"CollectionOf":                 true,
+	"Conflict":                     true,
+	"Consumes":                     true,
+	"Contact":                      true,
+	"ContactDefinition":            true,
+	"ContainerDefinition":          true,
+	"ContentType":                  true,
+	"Continue":                     true,
+	"Created":                      true,
+	"Credentials":                  true,
+	"DELETE":                       true,
+	"DataStructure":                true,
+	"DataType":                     true,
+	"DateTime":                     true,
+	"DateTimeKind":                 true,
+	"Default":                      true,
+	"DefaultDecoders":              true,
+	"DefaultEncoders":              true,
+	"DefaultMedia":                 true,
+	"DefaultView":                  true,
+	"Description":                  true,
+	"Design":                       true,
+	"Docs":                         true,
+	"DocsDefinition":               true,
+	"Dup":                          true,
+	"DupAtt":                       true,
+	"Email":                        true,
+	"EncodingDefinition":           true,
+	"Enum":                         true,
+	"Error":                        true,
+	"ErrorDefinition":              true,
+	"ErrorMedia":                   true,
+	"ErrorMediaIdentifier":         true,
+	"Example":                      true,
+	"ExpectationFailed":            true,
+	"Expose":                       true,
+	"ExtractWildcards":             true,
+	"File":                         true,
+	"FileKind":                     true,
+	"FileServerDefinition":         true,
+	"Files":                        true,
+	"Forbidden":                    true,
+	"Format":                       true,
+	"Found":                        true,
+	"Function":                     true,
+	"GET":                          true,
+	"GatewayTimeout":               true,
+	"GeneratedMediaTypes":          true,
+	"GobContentTypes":              true,
+	"Gone":                         true,
+	"HEAD":                         true,
+	"HTTPVersionNotSupported":      true,
+	"HasFile":                      true,
+	"HasKnownEncoder":              true,
+	"Hash":                         true,
+	"HashKind":                     true,
+	"HashOf":                       true,
+	"HashVal":                      true,
+	"Header":                       true,
+	"HeaderDefinition":             true,
+	"HeaderIterator":               true,
+	"Headers":                      true,
+	"Host":                         true,
+	"ImplicitFlow":                 true,
+	"Integer":                      true,
+	"IntegerKind":                  true,
+	"InternalServerError":          true,
+	"JSONContentTypes":             true,
+	"JWTSecurity":                  true,
+	"JWTSecurityKind":              true,
+	"Kind":                         true,
+	"KnownEncoderFunctions":        true,
+	"KnownEncoders":                true,
+	"LengthRequired":               true,
+	"License":                      true,
+	"LicenseDefinition":            true,
+	"Link":                         true,
+	"LinkDefinition":               true,
+	"Links":                        true,
+	"MaxAge":                       true,
+	"MaxLength":                    true,
+	"Maximum":                      true,
+	"Media":                        true,
+	"MediaType":                    true,
+	"MediaTypeDefinition":          true,
+	"MediaTypeIterator":            true,
+	"MediaTypeKind":                true,
+	"MediaTypeRoot":                true,
+	"Member":                       true,
+	"Metadata":                     true,
+	"MethodNotAllowed":             true,
+	"Methods":                      true,
+	"MinLength":                    true,
+	"Minimum":                      true,
+	"MovedPermanently":             true,
+	"MultipartForm":                true,
+	"MultipleChoices":              true,
+	"Name":                         true,
+	"NewAPIDefinition":             true,
+	"NewMediaTypeDefinition":       true,
+	"NewRandomGenerator":           true,
+	"NewResourceDefinition":        true,
+	"NewUserTypeDefinition":        true,
+	"NextCursor":                   true,
+	"NoContent":                    true,
+	"NoExample":                    true,
+	"NoSecurity":                   true,
+	"NoSecurityKind":               true,
+	"NonAuthoritativeInfo":         true,
+	"NotAcceptable":                true,
+	"NotFound":                     true,
+	"NotImplemented":               true,
+	"NotModified":                  true,
+	"Number":                       true,
+	"NumberKind":                   true,
+	"OAuth2Security":               true,
+	"OAuth2SecurityKind":           true,
+	"OK":                           true,
+	"OPTIONS":                      true,
+	"Object":                       true,
+	"ObjectKind":                   true,
+	"OptionalPayload":              true,
+	"Origin":                       true,
+	"PATCH":                        true,
+	"POST":                         true,
+	"PUT":                          true,
+	"Package":                      true,
+	"PageItems":                    true,
+	"Paginated":                    true,
+	"PaginationDefinition":         true,
+	"Param":                        true,
+	"Params":                       true,
+	"Parent":                       true,
+	"PartialContent":               true,
+	"PasswordFlow":                 true,
+	"Pattern":                      true,
+	"Payload":                      true,
+	"PaymentRequired":              true,
+	"PreconditionFailed":           true,
+	"Primitive":                    true,
+	"ProblemMedia":                 true,
+	"ProblemMediaIdentifier":       true,
+	"Produces":                     true,
+	"ProjectedMediaTypes":          true,
+	"ProxyAuthRequired":            true,
+	"Query":                        true,
+	"RandomGenerator":              true,
+	"ReadOnly":                     true,
+	"Reference":                    true,
+	"RequestEntityTooLarge":        true,
+	"RequestTimeout":               true,
+	"RequestURITooLong":            true,
+	"RequestedRangeNotSatisfiable": true,
+	"Required":                     true,
+	"ResetContent":                 true,
+	"Resource":                     true,
+	"ResourceDefinition":           true,
+	"ResourceIterator":             true,
+	"Response":                     true,
+	"ResponseDefinition":           true,
+	"ResponseIterator":             true,
+	"ResponseTemplate":             true,
+	"ResponseTemplateDefinition":   true,
+	"RouteDefinition":              true,
+	"Routing":                      true,
+	"Scheme":                       true,
+	"Scope":                        true,
+	"Security":                     true,
+	"SecurityDefinition":           true,
+	"SecuritySchemeDefinition":     true,
+	"SecuritySchemeKind":           true,
+	"SeeOther":                     true,
+	"ServiceUnavailable":           true,
+	"Status":                       true,
+	"String":                       true,
+	"StringKind":                   true,
+	"SupportedValidationFormats":   true,
+	"SwitchingProtocols":           true,
+	"TRACE":                        true,
+	"Teapot":                       true,
+	"TemporaryRedirect":            true,
+	"TermsOfService":               true,
+	"Title":                        true,
+	"TokenURL":                     true,
+	"Trait":                        true,
+	"Type":                         true,
+	"TypeName":                     true,
+	"URL":                          true,
+	"UUID":                         true,
+	"UUIDKind":                     true,
+	"Unauthorized":                 true,
+	"UnprocessableEntity":          true,
+	"UnsupportedMediaType":         true,
+	"UseProxy":                     true,
+	"UseTrait":                     true,
+	"UserTypeDefinition":           true,
+	"UserTypeIterator":             true,
+	"UserTypeKind":                 true,
+	"UserTypes":                    true,
+	"Version":                      true,
+	"View":                         true,
+	"ViewDefinition":               true,
+	"ViewIterator":                 true,
+	"WildcardRegex":                true,
+	"XMLContentTypes":              true,
+}
+
+// predeclared lists the predeclared Go identifiers that codegen.Goify does not escape, the
+// generated user types must not shadow these names.
+var predeclared = map[string]bool{
+	"any":        true,
+	"append":     true,
+	"bool":       true,
+	"cap":        true,
+	"clear":      true,
+	"close":      true,
+	"comparable": true,
+	"complex":    true,
+	"copy":       true,
+	"delete":     true,
+	"error":      true,
+	"false":      true,
+	"imag":       true,
+	"iota":       true,
+	"len":        true,
+	"make":       true,
+	"max":        true,
+	"min":        true,
+	"new":        true,
+	"nil":        true,
+	"panic":      true,
+	"print":      true,
+	"println":    true,
+	"real":       true,
+	"recover":    true,
+	"true":       true,
+	"uint":       true,
+	"uintptr":    true,
+}
@@ -0,0 +1,10 @@
+/*
+Package genjs provides a generator for a JavaScript client module.
+The module exports a factory function that returns a client object exposing one function per API
+action. The functions rely on the fetch API, available in all modern browsers and in Node.js 18
+and later, to make the HTTP requests. The functions of the actions whose responses stream values
+(see the Stream and ServerSentEvents DSLs) return an async iterable over the values instead.
+The generator also produces an example HTML page and a controller that serves it (see the
+--noexample flag to skip their generation).
+*/
+package genjs
@@ -0,0 +1,613 @@
+package genjs
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shogo82148/shogoa/design"
+	"github.com/shogo82148/shogoa/shogoagen/codegen"
+	"github.com/shogo82148/shogoa/shogoagen/utils"
+	"github.com/shogo82148/shogoa/version"
+)
+
+// NewGenerator returns an initialized instance of a JavaScript Client Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the JavaScript client code generator.
+type Generator struct {
+	API       *design.APIDefinition // The API definition
+	OutDir    string                // Destination directory
+	Timeout   time.Duration         // Timeout used by JavaScript client when making requests
+	Scheme    string                // Scheme used by JavaScript client
+	Host      string                // Host addressed by JavaScript client
+	NoExample bool                  // Do not generate an HTML example file
+	genfiles  []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver  string
+		timeout      time.Duration
+		scheme, host string
+		noexample    bool
+	)
+
+	set := flag.NewFlagSet("js", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.String("design", "", "")
+	set.DurationVar(&timeout, "timeout", time.Duration(20)*time.Second, "")
+	set.StringVar(&scheme, "scheme", "", "")
+	set.StringVar(&host, "host", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.BoolVar(&noexample, "noexample", false, "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{
+		OutDir:    outDir,
+		Timeout:   timeout,
+		Scheme:    scheme,
+		Host:      host,
+		NoExample: noexample,
+		API:       design.Design,
+	}
+
+	return g.Generate()
+}
+
+// Generate produces the JavaScript client module and the optional example.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Timeout == 0 {
+		g.Timeout = 20 * time.Second
+	}
+	if g.Scheme == "" && len(g.API.Schemes) > 0 {
+		g.Scheme = g.API.Schemes[0]
+	}
+	if g.Scheme == "" {
+		g.Scheme = "http"
+	}
+	if g.Host == "" {
+		g.Host = g.API.Host
+	}
+	if g.Host == "" {
+		return nil, fmt.Errorf("missing host value, set it with --host")
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "js")
+	if err = os.RemoveAll(g.OutDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+
+	// Generate client.js
+	exampleAction, err := g.generateJS(filepath.Join(g.OutDir, "client.js"))
+	if err != nil {
+		return
+	}
+
+	if exampleAction != nil && !g.NoExample {
+		// Generate index.html
+		if err = g.generateIndexHTML(filepath.Join(g.OutDir, "index.html"), exampleAction); err != nil {
+			return
+		}
+
+		// Generate example.go
+		if err = g.generateExample(filepath.Join(g.OutDir, "example.go")); err != nil {
+			return
+		}
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invocation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// generateJS writes the client module and returns the action used by the example if any.
+func (g *Generator) generateJS(jsFile string) (_ *jsAction, err error) {
+	file, err := os.Create(jsFile)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, jsFile)
+
+	var (
+		actions       []*jsAction
+		exampleAction *jsAction
+	)
+	for res := range g.API.AllResources() {
+		for action := range res.AllActions() {
+			if len(action.Routes) == 0 || action.WebSocket() {
+				continue
+			}
+			a := newJSAction(action)
+			if exampleAction == nil && a.Verb == "GET" && len(a.PathParams) == 0 && !a.HasPayload && !a.Stream {
+				exampleAction = a
+			}
+			actions = append(actions, a)
+		}
+	}
+
+	data := map[string]any{
+		"API":     g.API,
+		"Host":    g.Host,
+		"Scheme":  g.Scheme,
+		"Timeout": int64(g.Timeout / time.Millisecond),
+		"Version": version.String(),
+		"Actions": actions,
+	}
+	err = moduleTmpl.Execute(file, data)
+	return exampleAction, err
+}
+
+// generateIndexHTML writes the example HTML page that loads the client module and calls the
+// example action.
+func (g *Generator) generateIndexHTML(htmlFile string, exampleAction *jsAction) error {
+	file, err := os.Create(htmlFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, htmlFile)
+
+	data := map[string]any{
+		"API":           g.API,
+		"ExampleAction": exampleAction,
+	}
+	return exampleTmpl.Execute(file, data)
+}
+
+// generateExample writes the controller that serves the example files.
+func (g *Generator) generateExample(controllerFile string) (err error) {
+	file, err := codegen.SourceFileFor(controllerFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.NewImport("shogoa", "github.com/shogo82148/shogoa"),
+	}
+	title := fmt.Sprintf("%s: JavaScript Client Example", g.API.Context())
+	if err = file.WriteHeader(title, "js", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, controllerFile)
+
+	return file.ExecuteTemplate("examples", exampleCtrlT, nil, nil)
+}
+
+// jsAction is the data structure used to render the client function of an action.
+type jsAction struct {
+	// Name is the name of the generated function.
+	Name string
+	// Action is the underlying action definition.
+	Action *design.ActionDefinition
+	// Verb is the HTTP method of the action first route.
+	Verb string
+	// Path is the action first route full path.
+	Path string
+	// PathTemplate is the JavaScript template literal used to build the request path.
+	PathTemplate string
+	// PathParams lists the route path parameters in the order they appear in the path.
+	PathParams []*jsParam
+	// QueryParams lists the query string parameters sorted by name.
+	QueryParams []*jsParam
+	// HasPayload is true if the action accepts a request body.
+	HasPayload bool
+	// Multipart is true if the request body is encoded as multipart form data.
+	Multipart bool
+	// Stream is true if the action success responses stream a sequence of values.
+	Stream bool
+}
+
+// jsParam describes a path or query string parameter.
+type jsParam struct {
+	// Name is the name of the parameter as defined in the design.
+	Name string
+	// VarName is the name of the JavaScript variable holding the parameter value.
+	VarName string
+	// Required is true if the parameter must be provided.
+	Required bool
+}
+
+// newJSAction computes the data needed to render the client function of the given action.
+func newJSAction(action *design.ActionDefinition) *jsAction {
+	route := action.Routes[0]
+	a := &jsAction{
+		Name:       jsify(action.Name + "_" + action.Parent.Name),
+		Action:     action,
+		Verb:       route.Verb,
+		Path:       route.FullPath(),
+		HasPayload: action.Payload != nil,
+		Multipart:  action.PayloadMultipart,
+		Stream:     streams(action),
+	}
+	for _, p := range route.Params() {
+		a.PathParams = append(a.PathParams, &jsParam{Name: p, VarName: jsify(p), Required: true})
+	}
+	a.PathTemplate = design.WildcardRegex.ReplaceAllStringFunc(a.Path, func(w string) string {
+		fn := "encodeURIComponent"
+		if w[1] == '*' {
+			// Wildcards may span multiple path segments.
+			fn = "encodeURI"
+		}
+		return fmt.Sprintf("/${%s(%s)}", fn, jsify(w[2:]))
+	})
+	if action.QueryParams != nil {
+		obj := action.QueryParams.Type.ToObject()
+		names := make([]string, 0, len(obj))
+		for n := range obj {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			a.QueryParams = append(a.QueryParams, &jsParam{
+				Name:     n,
+				VarName:  jsify(n),
+				Required: action.QueryParams.IsRequired(n),
+			})
+		}
+	}
+	return a
+}
+
+// streams returns true if one of the action success responses streams a sequence of values.
+func streams(action *design.ActionDefinition) bool {
+	for _, r := range action.Responses {
+		if r.Status >= 200 && r.Status < 300 && r.Stream != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Args returns the names of the arguments of the generated function, excluding the trailing
+// config argument.
+func (a *jsAction) Args() []string {
+	args := make([]string, 0, len(a.PathParams)+2)
+	for _, p := range a.PathParams {
+		args = append(args, p.VarName)
+	}
+	if a.HasPayload {
+		args = append(args, "data")
+	}
+	if len(a.QueryParams) > 0 {
+		args = append(args, "params")
+	}
+	return args
+}
+
+// jsReserved lists the JavaScript reserved words that cannot be used as identifiers.
+var jsReserved = map[string]bool{
+	"await": true, "break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true, "do": true,
+	"else": true, "enum": true, "export": true, "extends": true, "false": true,
+	"finally": true, "for": true, "function": true, "if": true, "implements": true,
+	"import": true, "in": true, "instanceof": true, "interface": true, "let": true,
+	"new": true, "null": true, "package": true, "private": true, "protected": true,
+	"public": true, "return": true, "static": true, "super": true, "switch": true,
+	"this": true, "throw": true, "true": true, "try": true, "typeof": true, "var": true,
+	"void": true, "while": true, "with": true, "yield": true,
+}
+
+// jsify returns a valid JavaScript identifier in lower camel case built from the given name.
+func jsify(name string) string {
+	id := codegen.Goify(name, false)
+	// Goify appends an underscore to Go reserved words, JavaScript has its own list.
+	if strings.HasSuffix(id, "_") && !strings.HasSuffix(name, "_") {
+		id = strings.TrimSuffix(id, "_")
+	}
+	if jsReserved[id] {
+		id += "_"
+	}
+	return id
+}
+
+// quoteNames returns the given parameter names quoted and comma separated.
+func quoteNames(params []*jsParam) string {
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = fmt.Sprintf("%q", p.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// jsComment produces JavaScript line comments indented with the given prefix.
+func jsComment(prefix, text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(prefix+"// "+strings.TrimSpace(l), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	funcMap = template.FuncMap{
+		"join":       strings.Join,
+		"jsComment":  jsComment,
+		"quoteNames": quoteNames,
+	}
+	moduleTmpl  = template.Must(template.New("module").Funcs(funcMap).Parse(moduleT))
+	exampleTmpl = template.Must(template.New("example").Funcs(funcMap).Parse(exampleT))
+)
+
+const moduleT = `// Code generated by shogoagen {{ .Version }}, DO NOT EDIT.
+//
+// This module exports a function that creates a client for the {{ .API.Name }} API hosted at
+// {{ .Host }}.
+// It uses the fetch API to make the actual HTTP requests.
+
+// merge returns a new object containing the properties of obj1 overridden by the properties of
+// obj2. The headers property is merged recursively.
+function merge(obj1, obj2) {
+  const obj3 = Object.assign({}, obj1, obj2);
+  if (obj1 && obj2 && obj1.headers && obj2.headers) {
+    obj3.headers = Object.assign({}, obj1.headers, obj2.headers);
+  }
+  return obj3;
+}
+
+// buildQuery returns the query string built from the given parameters. Arrays produce one value
+// per element, undefined and null values are skipped.
+function buildQuery(params) {
+  const query = new URLSearchParams();
+  for (const [key, value] of Object.entries(params || {})) {
+    if (value === undefined || value === null) {
+      continue;
+    }
+    if (Array.isArray(value)) {
+      value.forEach((v) => query.append(key, v));
+    } else {
+      query.append(key, value);
+    }
+  }
+  const qs = query.toString();
+  return qs ? '?' + qs : '';
+}
+
+// readResponse returns a promise that resolves with an object holding the status, headers and
+// body (data) of the given response, JSON bodies are decoded.
+async function readResponse(resp) {
+  const contentType = resp.headers.get('Content-Type') || '';
+  let data = null;
+  if (resp.status !== 204) {
+    data = /^application\/([^;]*\+)?json\s*(;|$)/i.test(contentType) ? await resp.json() : await resp.text();
+  }
+  return { status: resp.status, headers: resp.headers, data: data };
+}
+
+// responseError returns the error reported for the given 4xx or 5xx response.
+function responseError(result) {
+  const err = new Error('request failed with status ' + result.status);
+  err.response = result;
+  return err;
+}
+
+// readLines yields the lines of the given body without their line terminators.
+async function* readLines(body) {
+  const reader = body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+  try {
+    for (;;) {
+      const { done, value } = await reader.read();
+      buffer += done ? decoder.decode() : decoder.decode(value, { stream: true });
+      // A carriage return ending the buffer may be followed by a line feed in the next chunk.
+      const lines = buffer.split(done ? /\r\n|\r|\n/ : /\r\n|\r(?!$)|\n/);
+      buffer = lines.pop();
+      yield* lines;
+      if (done) {
+        if (buffer !== '') {
+          yield buffer;
+        }
+        return;
+      }
+    }
+  } finally {
+    // Close the connection if the iteration stops early.
+    reader.cancel().catch(() => undefined);
+  }
+}
+
+// client creates a client for the {{ .API.Name }} API.
+// scheme, host and timeout (in milliseconds) are optional and default to the values defined
+// in the API design.
+export default function client(scheme, host, timeout) {
+  scheme = scheme || '{{ .Scheme }}';
+  host = host || '{{ .Host }}';
+  timeout = timeout || {{ .Timeout }};
+
+  // urlPrefix is the URL prefix for all API requests.
+  const urlPrefix = scheme + '://' + host;
+
+  // request sends the request described by cfg. It returns a promise that resolves with an object
+  // holding the response status, headers and decoded body (data). The promise is rejected if the
+  // request times out or if the HTTP response status is 4xx or 5xx, the error response property
+  // holds the response in the latter case.
+  async function request(cfg) {
+    const controller = new AbortController();
+    const timer = setTimeout(() => controller.abort(), cfg.timeout);
+    try {
+      const result = await readResponse(await send(cfg, controller.signal));
+      if (result.status < 200 || result.status > 299) {
+        throw responseError(result);
+      }
+      return result;
+    } finally {
+      clearTimeout(timer);
+    }
+  }
+
+  // stream sends the request described by cfg and yields the values streamed in the response body
+  // as newline delimited JSON or as the data of server-sent events depending on the response
+  // content type. The request has no timeout, cfg.signal aborts it. The iteration fails if the
+  // HTTP response status is 4xx or 5xx, the error response property holds the response.
+  async function* stream(cfg) {
+    const resp = await send(cfg, cfg.signal);
+    if (!resp.ok) {
+      throw responseError(await readResponse(resp));
+    }
+    if (!resp.body) {
+      return;
+    }
+    const sse = /^text\/event-stream/i.test(resp.headers.get('Content-Type') || '');
+    let data = [];
+    for await (const line of readLines(resp.body)) {
+      if (!sse) {
+        if (line.trim() !== '') {
+          yield JSON.parse(line);
+        }
+      } else if (line === '') {
+        // An empty line dispatches the event.
+        if (data.length > 0) {
+          yield JSON.parse(data.join('\n'));
+          data = [];
+        }
+      } else if (line.startsWith('data:')) {
+        data.push(line.slice(line.startsWith('data: ') ? 6 : 5));
+      }
+    }
+  }
+
+  // send sends the request described by cfg and returns a promise that resolves with the response.
+  function send(cfg, signal) {
+    const init = {
+      method: cfg.method,
+      headers: Object.assign({}, cfg.headers),
+      signal: signal
+    };
+    if (cfg.data !== undefined) {
+      if (cfg.multipart) {
+        const form = new FormData();
+        for (const [key, value] of Object.entries(cfg.data)) {
+          if (value !== undefined && value !== null) {
+            form.append(key, value);
+          }
+        }
+        init.body = form;
+      } else {
+        init.headers['Content-Type'] = init.headers['Content-Type'] || 'application/json';
+        init.body = JSON.stringify(cfg.data);
+      }
+    }
+    return fetch(urlPrefix + cfg.path + buildQuery(cfg.params), init);
+  }
+
+  const c = {};
+{{ range .Actions }}
+{{ if .Action.Description }}{{ jsComment "  " .Action.Description }}
+{{ else }}  // {{ .Name }} calls the {{ .Action.Name }} action of the {{ .Action.Parent.Name }} resource.
+{{ end }}  // The request path is "{{ .Path }}".
+{{ if .HasPayload }}  // data contains the action payload (request body){{ if .Multipart }}, it is sent as multipart form data{{ end }}.
+{{ end }}{{ if .QueryParams }}  // params is an object holding the query string parameters: {{ quoteNames .QueryParams }}.
+{{ end }}{{ if .Stream }}  // It returns an async iterable over the streamed values, the request has no timeout.
+  // config is an optional object merged into the request configuration, it may override the
+  // headers property and set the signal property to abort the request.
+  c.{{ .Name }} = function ({{ range .Args }}{{ . }}, {{ end }}config) {
+    return stream(merge({
+      method: '{{ .Verb }}',
+      path: ` + "`{{ .PathTemplate }}`" + `,{{ if .QueryParams }}
+      params: params,{{ end }}{{ if .HasPayload }}
+      data: data,{{ end }}{{ if .Multipart }}
+      multipart: true,{{ end }}
+    }, config));
+  };{{ else }}  // config is an optional object merged into the request configuration, it may override the
+  // headers and timeout properties.
+  c.{{ .Name }} = function ({{ range .Args }}{{ . }}, {{ end }}config) {
+    return request(merge({
+      method: '{{ .Verb }}',
+      path: ` + "`{{ .PathTemplate }}`" + `,{{ if .QueryParams }}
+      params: params,{{ end }}{{ if .HasPayload }}
+      data: data,{{ end }}{{ if .Multipart }}
+      multipart: true,{{ end }}
+      timeout: timeout
+    }, config));
+  };{{ end }}
+{{ end }}
+  return c;
+}
+`
+
+const exampleT = `<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>{{ .API.Name }} JavaScript client example</title>
+  </head>
+  <body>
+    <h1>{{ .API.Name }} JavaScript client example</h1>
+    <p>Response of the {{ .ExampleAction.Action.Name }} action of the {{ .ExampleAction.Action.Parent.Name }} resource:</p>
+    <pre id="response"></pre>
+    <script type="module">
+      import client from './client.js';
+
+      const output = document.getElementById('response');
+      client().{{ .ExampleAction.Name }}()
+        .then((resp) => {
+          output.textContent = JSON.stringify(resp.data, null, 2);
+        })
+        .catch((err) => {
+          output.textContent = err.response ? JSON.stringify(err.response.data, null, 2) : String(err);
+        });
+    </script>
+  </body>
+</html>
+`
+
+const exampleCtrlT = `// MountController mounts the JavaScript example controller under "/js".
+// The files are served from the "js" directory relative to the service working directory.
+// This is just an example, not the best way to do this. A better way would be to specify a file
+// server using the Files DSL in the design.
+// Use --noexample to prevent this file from being generated.
+func MountController(service *shogoa.Service) {
+	// Serve static files under js
+	service.ServeFiles("/js/*filepath", "js")
+	service.LogInfo("mount", "ctrl", "JS", "action", "ServeFiles", "route", "GET /js/*")
+}
+`
@@ -0,0 +1,228 @@
+package genjs_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/shogo82148/shogoa/design"
+	"github.com/shogo82148/shogoa/design/apidsl"
+	"github.com/shogo82148/shogoa/dslengine"
+	"github.com/shogo82148/shogoa/shogoagen/codegen"
+	genjs "github.com/shogo82148/shogoa/shogoagen/gen_js"
+	"github.com/shogo82148/shogoa/version"
+)
+
+// compareGolden compares the content of the generated file with the golden file of the same
+// name in the testdata directory.
+func compareGolden(outDir, name string) {
+	content, err := os.ReadFile(filepath.Join(outDir, "js", name))
+	Ω(err).ShouldNot(HaveOccurred())
+	actual := strings.ReplaceAll(string(content), version.String(), "{{VERSION}}")
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		Ω(os.WriteFile(golden, []byte(actual), 0644)).Should(Succeed())
+	}
+	expected, err := os.ReadFile(golden)
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(actual).Should(Equal(string(expected)))
+}
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = os.MkdirTemp(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"shogoagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genjs.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a design", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Host("localhost:8080")
+				apidsl.Scheme("https")
+				apidsl.BasePath("/api")
+			})
+			var Bottle = apidsl.Type("bottle", func() {
+				apidsl.Attribute("name", design.String)
+				apidsl.Attribute("vintage", design.Integer)
+				apidsl.Required("name")
+			})
+			var BottleMedia = apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Reference(Bottle)
+				apidsl.Attributes(func() {
+					apidsl.Attribute("name")
+					apidsl.Attribute("vintage")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("name")
+					apidsl.Attribute("vintage")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Params(func() {
+						apidsl.Param("years", apidsl.ArrayOf(design.Integer))
+						apidsl.Param("sort", design.String)
+					})
+					apidsl.Response(design.OK)
+				})
+				apidsl.Action("show", func() {
+					apidsl.Description("show returns the bottle with the given id.")
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+					})
+					apidsl.Response(design.OK)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(Bottle)
+					apidsl.Response(design.Created)
+				})
+				apidsl.Action("upload", func() {
+					apidsl.Routing(apidsl.POST("/:id/files/*filepath"))
+					apidsl.MultipartForm()
+					apidsl.Payload(func() {
+						apidsl.Attribute("file", design.File)
+					})
+					apidsl.Response(design.OK)
+				})
+				apidsl.Action("delete", func() {
+					apidsl.Routing(apidsl.DELETE("/:id"))
+					apidsl.Response(design.NoContent)
+				})
+				apidsl.Action("watch", func() {
+					apidsl.Routing(apidsl.GET("/watch"))
+					apidsl.Response(design.OK, BottleMedia, func() {
+						apidsl.ServerSentEvents()
+					})
+				})
+			})
+			Ω(dslengine.Run()).Should(Succeed())
+		})
+
+		It("generates the client module", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(HaveLen(4))
+			compareGolden(outDir, "client.js")
+		})
+
+		It("generates the example", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			compareGolden(outDir, "index.html")
+			content, err := os.ReadFile(filepath.Join(outDir, "js", "example.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`service.ServeFiles("/js/*filepath", "js")`))
+		})
+
+		Context("with --noexample", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--noexample")
+			})
+
+			It("does not generate the example", func() {
+				Ω(genErr).ShouldNot(HaveOccurred())
+				Ω(files).Should(HaveLen(2))
+				_, err := os.Stat(filepath.Join(outDir, "js", "index.html"))
+				Ω(os.IsNotExist(err)).Should(BeTrue())
+				_, err = os.Stat(filepath.Join(outDir, "js", "example.go"))
+				Ω(os.IsNotExist(err)).Should(BeTrue())
+			})
+		})
+
+		Context("with --scheme, --host and --timeout", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--scheme=http", "--host=example.com", "--timeout=5s")
+			})
+
+			It("uses the given values as defaults", func() {
+				Ω(genErr).ShouldNot(HaveOccurred())
+				content, err := os.ReadFile(filepath.Join(outDir, "js", "client.js"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("scheme = scheme || 'http';"))
+				Ω(string(content)).Should(ContainSubstring("host = host || 'example.com';"))
+				Ω(string(content)).Should(ContainSubstring("timeout = timeout || 5000;"))
+			})
+		})
+	})
+
+	Context("with a design that does not define a host", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			Ω(dslengine.Run()).Should(Succeed())
+		})
+
+		It("fails", func() {
+			Ω(genErr).Should(MatchError("missing host value, set it with --host"))
+			Ω(files).Should(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genjs.Generator
+
+	var args = struct {
+		api       *design.APIDefinition
+		outDir    string
+		timeout   time.Duration
+		scheme    string
+		host      string
+		noExample bool
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:    "out_dir",
+		timeout:   time.Millisecond * 500,
+		scheme:    "http",
+		host:      "localhost",
+		noExample: true,
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genjs.NewGenerator(
+				genjs.API(args.api),
+				genjs.OutDir(args.outDir),
+				genjs.Timeout(args.timeout),
+				genjs.Scheme(args.scheme),
+				genjs.Host(args.host),
+				genjs.NoExample(args.noExample),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Timeout).Should(Equal(args.timeout))
+			Ω(generator.Scheme).Should(Equal(args.scheme))
+			Ω(generator.Host).Should(Equal(args.host))
+			Ω(generator.NoExample).Should(Equal(args.noExample))
+		})
+	})
+})
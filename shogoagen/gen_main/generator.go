@@ -357,6 +357,9 @@ func okResp(a *design.ActionDefinition, appPkg string) map[string]interface{} {
 			typeref = "&" + typeref[1:]
 		}
 		typeref += "{}"
+		if ok.Stream != "" {
+			typeref = fmt.Sprintf("func(yield func(%s%s.%s) bool) {\n\t\tyield(%s)\n\t}", pointer, appPkg, name, typeref)
+		}
 	}
 	var nameSuffix string
 	if view != "default" {
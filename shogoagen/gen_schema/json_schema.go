@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/shogo82148/shogoa/design"
 )
@@ -221,6 +222,7 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 				Schema:       requestSchema,
 				TargetSchema: targetSchema,
 				MediaType:    identifier,
+				Description:  errorsDescription(a),
 			}
 			if i == 0 {
 				if ca := a.Parent.CanonicalAction(); ca != nil {
@@ -235,6 +237,22 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 	})
 }
 
+// errorsDescription lists the codes and statuses of the errors declared by the action, it returns
+// an empty string if the action declares no error.
+func errorsDescription(a *design.ActionDefinition) string {
+	if len(a.Errors) == 0 {
+		return ""
+	}
+	lines := make([]string, len(a.Errors))
+	for i, e := range a.Errors {
+		lines[i] = fmt.Sprintf("  * `%s` (%d)", e.Name, e.Status)
+		if e.Description != "" {
+			lines[i] += ": " + e.Description
+		}
+	}
+	return "Error codes:\n" + strings.Join(lines, "\n")
+}
+
 // MediaTypeRef produces the JSON reference to the media type definition with the given view.
 func MediaTypeRef(api *design.APIDefinition, mt *design.MediaTypeDefinition, view string) string {
 	projected, _, err := mt.Project(view)
@@ -320,6 +338,12 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	return s
 }
 
+// AttributeSchema produces the JSON schema corresponding to the given attribute including its
+// validations.
+func AttributeSchema(api *design.APIDefinition, at *design.AttributeDefinition) *JSONSchema {
+	return buildAttributeSchema(api, NewJSONSchema(), at)
+}
+
 type mergeItems []struct {
 	a, b   interface{}
 	needed bool
@@ -79,3 +79,33 @@ var _ = Describe("TypeSchema", func() {
 
 	})
 })
+
+var _ = Describe("GenerateResourceDefinition", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	Context("with an action that declares errors", func() {
+		BeforeEach(func() {
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Error("not_found", design.NotFound, func() {
+						apidsl.Description("The bottle does not exist")
+					})
+					apidsl.Error("gone", design.Gone, design.ProblemMedia)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			genschema.GenerateResourceDefinition(design.Design, design.Design.Resources["bottle"])
+		})
+
+		It("documents the error codes in the action link", func() {
+			s := genschema.Definitions["bottle"]
+			Ω(s.Links).Should(HaveLen(1))
+			Ω(s.Links[0].Description).Should(Equal("Error codes:\n  * `not_found` (404): The bottle does not exist\n  * `gone` (410)"))
+		})
+	})
+})
@@ -5,5 +5,8 @@ This JSON swagger can be used to generate API documentation, ruby and Go API cli
 See the blog post (https://blog.heroku.com/archives/2014/1/8/json_swagger_for_heroku_platform_api)
 describing how Heroku leverages the JSON Hyper-swagger standard (http://json-swagger.org/latest/json-swagger-hypermedia.html)
 for more information.
+
+The package can also produce an OpenAPI 3.1 document from the same design (see the "openapi"
+command of shogoagen).
 */
 package genswagger
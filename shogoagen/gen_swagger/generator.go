@@ -29,11 +29,22 @@ func NewGenerator(options ...Option) *Generator {
 type Generator struct {
 	API      *design.APIDefinition // The API definition
 	OutDir   string                // Path to output directory
+	OpenAPI  bool                  // Generate an OpenAPI 3.1 document instead of Swagger 2.0
 	genfiles []string              // Generated files
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
+	return generate(false)
+}
+
+// GenerateOpenAPI is the generator entry point called by the meta generator to produce an
+// OpenAPI 3.1 document.
+func GenerateOpenAPI() (files []string, err error) {
+	return generate(true)
+}
+
+func generate(openAPI bool) (files []string, err error) {
 	var (
 		outDir, toolDir, target, ver string
 		notool, regen                bool
@@ -55,7 +66,7 @@ func Generate() (files []string, err error) {
 		return nil, err
 	}
 
-	g := &Generator{OutDir: outDir, API: design.Design}
+	g := &Generator{OutDir: outDir, API: design.Design, OpenAPI: openAPI}
 
 	return g.Generate()
 }
@@ -74,12 +85,21 @@ func (g *Generator) Generate() (_ []string, err error) {
 		}
 	}()
 
-	s, err := New(g.API)
+	var (
+		s    any
+		name = "swagger"
+	)
+	if g.OpenAPI {
+		name = "openapi"
+		s, err = NewOpenAPI(g.API)
+	} else {
+		s, err = New(g.API)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	swaggerDir := filepath.Join(g.OutDir, "swagger")
+	swaggerDir := filepath.Join(g.OutDir, name)
 	os.RemoveAll(swaggerDir)
 	if err = os.MkdirAll(swaggerDir, 0755); err != nil {
 		return nil, err
@@ -91,7 +111,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err != nil {
 		return nil, err
 	}
-	swaggerFile := filepath.Join(swaggerDir, "swagger.json")
+	swaggerFile := filepath.Join(swaggerDir, name+".json")
 	if err := os.WriteFile(swaggerFile, rawJSON, 0644); err != nil {
 		return nil, err
 	}
@@ -102,7 +122,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err != nil {
 		return nil, err
 	}
-	swaggerFile = filepath.Join(swaggerDir, "swagger.yaml")
+	swaggerFile = filepath.Join(swaggerDir, name+".yaml")
 	if err := os.WriteFile(swaggerFile, rawYAML, 0644); err != nil {
 		return nil, err
 	}
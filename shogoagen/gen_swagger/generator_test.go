@@ -11,13 +11,15 @@ var _ = Describe("NewGenerator", func() {
 	var generator *genswagger.Generator
 
 	var args = struct {
-		api    *design.APIDefinition
-		outDir string
+		api     *design.APIDefinition
+		outDir  string
+		openAPI bool
 	}{
 		api: &design.APIDefinition{
 			Name: "test api",
 		},
-		outDir: "out_dir",
+		outDir:  "out_dir",
+		openAPI: true,
 	}
 
 	Context("with options all options set", func() {
@@ -26,6 +28,7 @@ var _ = Describe("NewGenerator", func() {
 			generator = genswagger.NewGenerator(
 				genswagger.API(args.api),
 				genswagger.OutDir(args.outDir),
+				genswagger.OpenAPIOutput(args.openAPI),
 			)
 		})
 
@@ -33,6 +36,7 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator).ShouldNot(BeNil())
 			Ω(generator.API.Name).Should(Equal(args.api.Name))
 			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.OpenAPI).Should(Equal(args.openAPI))
 		})
 	})
 })
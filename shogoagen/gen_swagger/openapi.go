@@ -0,0 +1,708 @@
+package genswagger
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shogo82148/shogoa/design"
+	genschema "github.com/shogo82148/shogoa/shogoagen/gen_schema"
+)
+
+type (
+	// OpenAPI represents an instance of an OpenAPI 3.1 document.
+	// See https://spec.openapis.org/oas/v3.1.0
+	OpenAPI struct {
+		OpenAPI      string         `json:"openapi"`
+		Info         *Info          `json:"info"`
+		Servers      []*Server      `json:"servers,omitempty"`
+		Paths        map[string]any `json:"paths"`
+		Components   *Components    `json:"components,omitempty"`
+		Tags         []*Tag         `json:"tags,omitempty"`
+		ExternalDocs *ExternalDocs  `json:"externalDocs,omitempty"`
+	}
+
+	// Server represents a server hosting the API.
+	Server struct {
+		// URL to the target host, it may be relative to the location of the document.
+		URL string `json:"url"`
+		// Description of the host designated by the URL.
+		Description string `json:"description,omitempty"`
+	}
+
+	// Components holds the reusable objects of the document.
+	Components struct {
+		// Schemas holds the schemas of the user types and media types.
+		Schemas map[string]*genschema.JSONSchema `json:"schemas,omitempty"`
+		// Responses holds the responses defined at the API level.
+		Responses map[string]*OpenAPIResponse `json:"responses,omitempty"`
+		// SecuritySchemes holds the security schemes used by the operations.
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// PathItem describes the operations available on a single path.
+	PathItem struct {
+		// Get defines a GET operation on this path.
+		Get *OpenAPIOperation `json:"get,omitempty"`
+		// Put defines a PUT operation on this path.
+		Put *OpenAPIOperation `json:"put,omitempty"`
+		// Post defines a POST operation on this path.
+		Post *OpenAPIOperation `json:"post,omitempty"`
+		// Delete defines a DELETE operation on this path.
+		Delete *OpenAPIOperation `json:"delete,omitempty"`
+		// Options defines a OPTIONS operation on this path.
+		Options *OpenAPIOperation `json:"options,omitempty"`
+		// Head defines a HEAD operation on this path.
+		Head *OpenAPIOperation `json:"head,omitempty"`
+		// Patch defines a PATCH operation on this path.
+		Patch *OpenAPIOperation `json:"patch,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]any `json:"-"`
+	}
+
+	// OpenAPIOperation describes a single API operation on a path.
+	OpenAPIOperation struct {
+		// Tags is a list of tags for API documentation control.
+		Tags []string `json:"tags,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs points to additional external documentation for this operation.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Parameters is a list of parameters that are applicable for this operation.
+		Parameters []*OpenAPIParameter `json:"parameters,omitempty"`
+		// RequestBody describes the request body of the operation if any.
+		RequestBody *RequestBody `json:"requestBody,omitempty"`
+		// Responses is the list of possible responses indexed by HTTP status code.
+		Responses map[string]*OpenAPIResponse `json:"responses"`
+		// Deprecated declares this operation to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Security is a declaration of which security schemes are applied for this operation.
+		Security []map[string][]string `json:"security,omitempty"`
+		// Servers overrides the API servers for this operation.
+		Servers []*Server `json:"servers,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]any `json:"-"`
+	}
+
+	// OpenAPIParameter describes a single operation parameter.
+	OpenAPIParameter struct {
+		// Name of the parameter. Parameter names are case sensitive.
+		Name string `json:"name"`
+		// In is the location of the parameter.
+		// Possible values are "query", "header", "path" or "cookie".
+		In string `json:"in"`
+		// Description is a brief description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this parameter is mandatory.
+		Required bool `json:"required,omitempty"`
+		// Schema defines the type used for the parameter.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]any `json:"-"`
+	}
+
+	// RequestBody describes a single request body.
+	RequestBody struct {
+		// Description is a brief description of the request body.
+		Description string `json:"description,omitempty"`
+		// Content lists the request body schemas indexed by content type.
+		Content map[string]*MediaTypeObject `json:"content"`
+		// Required determines whether the request body is mandatory.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// MediaTypeObject provides the schema for the content type it is indexed with.
+	MediaTypeObject struct {
+		// Schema defines the content of the request or response body.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// OpenAPIResponse describes an operation response.
+	OpenAPIResponse struct {
+		// Description of the response.
+		Description string `json:"description"`
+		// Headers is a list of headers that are sent with the response.
+		Headers map[string]*OpenAPIHeader `json:"headers,omitempty"`
+		// Content lists the response body schemas indexed by content type.
+		Content map[string]*MediaTypeObject `json:"content,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]any `json:"-"`
+	}
+
+	// OpenAPIHeader represents a response header.
+	OpenAPIHeader struct {
+		// Description is a brief description of the header.
+		Description string `json:"description,omitempty"`
+		// Required determines whether the header is always sent.
+		Required bool `json:"required,omitempty"`
+		// Schema defines the type used for the header.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// SecurityScheme defines a security scheme that can be used by the operations.
+	SecurityScheme struct {
+		// Type of the security scheme. Valid values are "apiKey", "http", "mutualTLS",
+		// "oauth2" or "openIdConnect".
+		Type string `json:"type"`
+		// Description for security scheme
+		Description string `json:"description,omitempty"`
+		// Name of the header, query or cookie parameter to be used when type is "apiKey".
+		Name string `json:"name,omitempty"`
+		// In is the location of the API key when type is "apiKey".
+		In string `json:"in,omitempty"`
+		// Scheme is the name of the HTTP Authorization scheme when type is "http".
+		Scheme string `json:"scheme,omitempty"`
+		// BearerFormat is a hint to the client to identify how the bearer token is formatted.
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		// Flows contains configuration information for the flows when type is "oauth2".
+		Flows *OAuthFlows `json:"flows,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]any `json:"-"`
+	}
+
+	// OAuthFlows lists the supported OAuth2 flows.
+	OAuthFlows struct {
+		Implicit          *OAuthFlow `json:"implicit,omitempty"`
+		Password          *OAuthFlow `json:"password,omitempty"`
+		ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+		AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	}
+
+	// OAuthFlow describes a single OAuth2 flow.
+	OAuthFlow struct {
+		// AuthorizationURL is the authorization URL to be used for this flow.
+		AuthorizationURL string `json:"authorizationUrl,omitempty"`
+		// TokenURL is the token URL to be used for this flow.
+		TokenURL string `json:"tokenUrl,omitempty"`
+		// Scopes list the available scopes, the map may be empty but must be present.
+		Scopes map[string]string `json:"scopes"`
+	}
+
+	// These types are used in marshalJSON() to avoid recursive call of json.Marshal().
+	_PathItem         PathItem
+	_OpenAPIOperation OpenAPIOperation
+	_OpenAPIParameter OpenAPIParameter
+	_OpenAPIResponse  OpenAPIResponse
+	_SecurityScheme   SecurityScheme
+)
+
+// MarshalJSON returns the JSON encoding of p.
+func (p PathItem) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_PathItem(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of o.
+func (o OpenAPIOperation) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIOperation(o), o.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p OpenAPIParameter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIParameter(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r OpenAPIResponse) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIResponse(r), r.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of s.
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_SecurityScheme(s), s.Extensions)
+}
+
+// NewOpenAPI creates an OpenAPI 3.1 document from an API definition.
+func NewOpenAPI(api *design.APIDefinition) (*OpenAPI, error) {
+	if api == nil {
+		return nil, nil
+	}
+	basePath := api.BasePath
+	if hasAbsoluteRoutes(api) {
+		basePath = ""
+	}
+	o := &OpenAPI{
+		OpenAPI: "3.1.0",
+		Info: &Info{
+			Title:          api.Title,
+			Description:    api.Description,
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+			Version:        api.Version,
+			Extensions:     extensionsFromDefinition(api.Metadata),
+		},
+		Servers:      serversFromDefinition(api, basePath),
+		Paths:        make(map[string]any),
+		Components:   &Components{SecuritySchemes: securitySchemesFromDefinition(api.SecuritySchemes)},
+		Tags:         tagsFromDefinition(api.Metadata),
+		ExternalDocs: docsFromDefinition(api.Docs),
+	}
+
+	for r := range api.AllResponses() {
+		resp, err := openAPIResponseFromDefinition(api, r)
+		if err != nil {
+			return nil, err
+		}
+		if o.Components.Responses == nil {
+			o.Components.Responses = make(map[string]*OpenAPIResponse)
+		}
+		o.Components.Responses[r.Name] = resp
+	}
+
+	for res := range api.AllResources() {
+		for k, v := range extensionsFromDefinition(res.Metadata) {
+			o.Paths[k] = v
+		}
+		for fs := range res.AllFileServers() {
+			if !mustGenerate(fs.Metadata) {
+				continue
+			}
+			buildOpenAPIPathFromFileServer(o, api, fs, basePath)
+		}
+		for a := range res.AllActions() {
+			if !mustGenerate(a.Metadata) {
+				continue
+			}
+			for _, route := range a.Routes {
+				if err := buildOpenAPIPathFromDefinition(o, api, route, basePath); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if len(genschema.Definitions) > 0 {
+		o.Components.Schemas = make(map[string]*genschema.JSONSchema, len(genschema.Definitions))
+		for n, d := range genschema.Definitions {
+			o.Components.Schemas[n] = openAPISchema(d)
+		}
+	}
+	if o.Components.Schemas == nil && o.Components.Responses == nil && o.Components.SecuritySchemes == nil {
+		o.Components = nil
+	}
+	return o, nil
+}
+
+// serversFromDefinition returns one server per API scheme.
+func serversFromDefinition(api *design.APIDefinition, basePath string) []*Server {
+	if api.Host == "" {
+		if basePath == "" {
+			return nil
+		}
+		return []*Server{{URL: basePath}}
+	}
+	schemes := api.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	servers := make([]*Server, len(schemes))
+	for i, s := range schemes {
+		servers[i] = &Server{URL: s + "://" + api.Host + basePath}
+	}
+	return servers
+}
+
+// securitySchemesFromDefinition returns the OpenAPI security schemes corresponding to the given
+// design security schemes. JWT security schemes are described as HTTP bearer schemes.
+func securitySchemesFromDefinition(schemes []*design.SecuritySchemeDefinition) map[string]*SecurityScheme {
+	if len(schemes) == 0 {
+		return nil
+	}
+
+	defs := make(map[string]*SecurityScheme)
+	for _, scheme := range schemes {
+		def := &SecurityScheme{
+			Description: scheme.Description,
+			Extensions:  extensionsFromDefinition(scheme.Metadata),
+		}
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			def.Type = "http"
+			def.Scheme = "basic"
+		case design.APIKeySecurityKind:
+			def.Type = "apiKey"
+			def.Name = scheme.Name
+			def.In = scheme.In
+		case design.JWTSecurityKind:
+			def.Type = "http"
+			def.Scheme = "bearer"
+			def.BearerFormat = "JWT"
+			if scheme.TokenURL != "" {
+				def.Description += fmt.Sprintf("\n\n**Token URL**: %s", scheme.TokenURL)
+			}
+			if len(scheme.Scopes) != 0 {
+				def.Description += fmt.Sprintf("\n\n**Security Scopes**:\n%s", scopesMapList(scheme.Scopes))
+			}
+		case design.OAuth2SecurityKind:
+			def.Type = "oauth2"
+			scopes := scheme.Scopes
+			if scopes == nil {
+				scopes = make(map[string]string)
+			}
+			flow := &OAuthFlow{
+				AuthorizationURL: scheme.AuthorizationURL,
+				TokenURL:         scheme.TokenURL,
+				Scopes:           scopes,
+			}
+			def.Flows = &OAuthFlows{}
+			switch scheme.Flow {
+			case "implicit":
+				def.Flows.Implicit = flow
+			case "password":
+				def.Flows.Password = flow
+			case "application":
+				def.Flows.ClientCredentials = flow
+			case "accessCode":
+				def.Flows.AuthorizationCode = flow
+			}
+		default:
+			continue
+		}
+		defs[scheme.SchemeName] = def
+	}
+	return defs
+}
+
+// openAPIParamsFromDefinition returns the path and query string parameters of the given
+// attribute.
+func openAPIParamsFromDefinition(api *design.APIDefinition, params *design.AttributeDefinition, path string) ([]*OpenAPIParameter, error) {
+	if params == nil {
+		return nil, nil
+	}
+	obj := params.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid parameters definition, not an object")
+	}
+	res := make([]*OpenAPIParameter, 0, len(obj))
+	wildcards := design.ExtractWildcards(path)
+	for n, at := range obj.AllAttributes() {
+		in := "query"
+		required := params.IsRequired(n)
+		for _, w := range wildcards {
+			if n == w {
+				in = "path"
+				required = true
+				break
+			}
+		}
+		res = append(res, openAPIParamFor(api, at, n, in, required))
+	}
+	return res, nil
+}
+
+// openAPIParamFor returns the parameter corresponding to the given attribute.
+func openAPIParamFor(api *design.APIDefinition, at *design.AttributeDefinition, name, in string, required bool) *OpenAPIParameter {
+	schema := openAPISchema(genschema.AttributeSchema(api, at))
+	schema.Description = ""
+	return &OpenAPIParameter{
+		Name:        name,
+		In:          in,
+		Description: at.Description,
+		Required:    required,
+		Schema:      schema,
+		Extensions:  extensionsFromDefinition(at.Metadata),
+	}
+}
+
+// openAPIResponseFromDefinition returns the response corresponding to the given definition.
+func openAPIResponseFromDefinition(api *design.APIDefinition, r *design.ResponseDefinition) (*OpenAPIResponse, error) {
+	var content map[string]*MediaTypeObject
+	if r.MediaType != "" {
+		obj := &MediaTypeObject{}
+		contentType := r.MediaType
+		if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			obj.Schema = &genschema.JSONSchema{Ref: openAPIRef(genschema.MediaTypeRef(api, mt, view))}
+			if mt.ContentType != "" {
+				contentType = mt.ContentType
+			}
+		} else if r.Type != nil {
+			obj.Schema = openAPISchema(genschema.TypeSchema(api, r.Type))
+		}
+		if r.Stream != "" {
+			// The schema describes each value of the stream.
+			contentType = r.Stream
+		}
+		content = map[string]*MediaTypeObject{contentType: obj}
+	}
+	var headers map[string]*OpenAPIHeader
+	if r.Headers != nil {
+		obj := r.Headers.Type.ToObject()
+		if obj == nil {
+			return nil, fmt.Errorf("invalid headers definition, not an object")
+		}
+		headers = make(map[string]*OpenAPIHeader, len(obj))
+		for n, at := range obj.AllAttributes() {
+			schema := openAPISchema(genschema.AttributeSchema(api, at))
+			schema.Description = ""
+			headers[n] = &OpenAPIHeader{
+				Description: at.Description,
+				Required:    r.Headers.IsRequired(n),
+				Schema:      schema,
+			}
+		}
+	}
+	desc := r.Description
+	if desc == "" {
+		// The description is required by OpenAPI.
+		desc = http.StatusText(r.Status)
+	}
+	return &OpenAPIResponse{
+		Description: desc,
+		Headers:     headers,
+		Content:     content,
+		Extensions:  extensionsFromDefinition(r.Metadata),
+	}, nil
+}
+
+// requestBodyFromDefinition returns the request body of the given action with one schema per
+// content type the API consumes.
+func requestBodyFromDefinition(api *design.APIDefinition, action *design.ActionDefinition) *RequestBody {
+	if action.Payload == nil {
+		return nil
+	}
+	schema := openAPISchema(genschema.TypeSchema(api, action.Payload))
+	var contentTypes []string
+	if action.PayloadMultipart {
+		contentTypes = []string{"multipart/form-data"}
+	} else {
+		for _, c := range api.Consumes {
+			contentTypes = append(contentTypes, c.MIMETypes...)
+		}
+		if len(contentTypes) == 0 {
+			contentTypes = design.JSONContentTypes
+		}
+	}
+	content := make(map[string]*MediaTypeObject, len(contentTypes))
+	for _, ct := range contentTypes {
+		content[ct] = &MediaTypeObject{Schema: schema}
+	}
+	return &RequestBody{
+		Description: action.Payload.Description,
+		Content:     content,
+		Required:    !action.PayloadOptional,
+	}
+}
+
+func buildOpenAPIPathFromFileServer(o *OpenAPI, api *design.APIDefinition, fs *design.FileServerDefinition, basePath string) {
+	wcs := design.ExtractWildcards(fs.RequestPath)
+	var params []*OpenAPIParameter
+	if len(wcs) > 0 {
+		params = []*OpenAPIParameter{{
+			In:          "path",
+			Name:        wcs[0],
+			Description: "Relative file path",
+			Required:    true,
+			Schema:      &genschema.JSONSchema{Type: genschema.JSONString},
+		}}
+	}
+
+	responses := map[string]*OpenAPIResponse{
+		"200": {
+			Description: "File downloaded",
+			Content: map[string]*MediaTypeObject{
+				"*/*": {Schema: &genschema.JSONSchema{Type: genschema.JSONString, Format: "binary"}},
+			},
+		},
+	}
+	if len(wcs) > 0 {
+		schema := openAPISchema(genschema.TypeSchema(api, design.ErrorMedia))
+		responses["404"] = &OpenAPIResponse{
+			Description: "File not found",
+			Content:     map[string]*MediaTypeObject{design.ErrorMedia.Identifier: {Schema: schema}},
+		}
+	}
+
+	operation := &OpenAPIOperation{
+		Description:  fs.Description,
+		Summary:      summaryFromDefinition(fmt.Sprintf("Download %s", fs.FilePath), fs.Metadata),
+		ExternalDocs: docsFromDefinition(fs.Docs),
+		OperationID:  fmt.Sprintf("%s#%s", fs.Parent.Name, fs.RequestPath),
+		Parameters:   params,
+		Responses:    responses,
+	}
+	operation.Security = openAPISecurity(fs.Security, &operation.Description)
+
+	p := openAPIPathItem(o, fs.RequestPath, basePath)
+	p.Get = operation
+	p.Extensions = extensionsFromDefinition(fs.Metadata)
+}
+
+func buildOpenAPIPathFromDefinition(o *OpenAPI, api *design.APIDefinition, route *design.RouteDefinition, basePath string) error {
+	action := route.Parent
+
+	tagNames := tagNamesFromDefinitions(action.Parent.Metadata, action.Metadata)
+	if len(tagNames) == 0 {
+		// By default tag with resource name
+		tagNames = []string{route.Parent.Parent.Name}
+	}
+	params, err := openAPIParamsFromDefinition(api, action.AllParams(), route.FullPath())
+	if err != nil {
+		return err
+	}
+	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
+		params = append(params, openAPIParamFor(api, header, name, "header", required))
+		return nil
+	})
+
+	responses := make(map[string]*OpenAPIResponse, len(action.Responses))
+	for _, r := range action.Responses {
+		resp, err := openAPIResponseFromDefinition(api, r)
+		if err != nil {
+			return err
+		}
+		describeErrors(&resp.Description, action, r)
+		if cc := cachePolicy(action, r); cc != "" {
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]*OpenAPIHeader)
+			}
+			resp.Headers["Cache-Control"] = &OpenAPIHeader{
+				Description: "Caching policy of the response",
+				Schema:      &genschema.JSONSchema{Type: genschema.JSONString, DefaultValue: cc},
+			}
+		}
+		responses[strconv.Itoa(r.Status)] = resp
+	}
+
+	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	for i, rt := range action.Routes {
+		if rt == route && i > 0 {
+			operationID = fmt.Sprintf("%s#%d", operationID, i)
+			break
+		}
+	}
+
+	operation := &OpenAPIOperation{
+		Tags:         tagNames,
+		Description:  action.Description,
+		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		ExternalDocs: docsFromDefinition(action.Docs),
+		OperationID:  operationID,
+		Parameters:   params,
+		RequestBody:  requestBodyFromDefinition(api, action),
+		Responses:    responses,
+		Extensions:   extensionsFromDefinition(route.Metadata),
+	}
+	if len(action.Schemes) > 0 && api.Host != "" {
+		operation.Servers = make([]*Server, len(action.Schemes))
+		for i, s := range action.Schemes {
+			operation.Servers[i] = &Server{URL: s + "://" + api.Host + basePath}
+		}
+	}
+	operation.Security = openAPISecurity(action.Security, &operation.Description)
+
+	p := openAPIPathItem(o, route.FullPath(), basePath)
+	switch route.Verb {
+	case "GET":
+		p.Get = operation
+	case "PUT":
+		p.Put = operation
+	case "POST":
+		p.Post = operation
+	case "DELETE":
+		p.Delete = operation
+	case "OPTIONS":
+		p.Options = operation
+	case "HEAD":
+		p.Head = operation
+	case "PATCH":
+		p.Patch = operation
+	}
+	p.Extensions = extensionsFromDefinition(action.Metadata)
+	return nil
+}
+
+// openAPIPathItem returns the path item for the given path creating it if needed. The path is
+// made relative to the base path which is part of the server URLs.
+func openAPIPathItem(o *OpenAPI, path, basePath string) *PathItem {
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		path,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	bp := design.WildcardRegex.ReplaceAllStringFunc(
+		basePath,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if bp != "/" {
+		key = strings.TrimPrefix(key, bp)
+	}
+	if key == "" {
+		key = "/"
+	}
+	if p, ok := o.Paths[key].(*PathItem); ok {
+		return p
+	}
+	p := new(PathItem)
+	o.Paths[key] = p
+	return p
+}
+
+// openAPISecurity returns the security requirements of an operation. The required scopes of JWT
+// schemes are also appended to the operation description.
+func openAPISecurity(security *design.SecurityDefinition, description *string) []map[string][]string {
+	if security == nil || security.Scheme.Kind == design.NoSecurityKind {
+		return nil
+	}
+	if security.Scheme.Kind == design.JWTSecurityKind && len(security.Scopes) > 0 {
+		if *description != "" {
+			*description += "\n\n"
+		}
+		*description += fmt.Sprintf("Required security scopes:\n%s", scopesList(security.Scopes))
+	}
+	scopes := security.Scopes
+	if scopes == nil {
+		scopes = make([]string, 0)
+	}
+	return []map[string][]string{{security.Scheme.SchemeName: scopes}}
+}
+
+// openAPIRef converts a JSON schema definition reference into a components reference.
+func openAPIRef(ref string) string {
+	if name, ok := strings.CutPrefix(ref, "#/definitions/"); ok {
+		return "#/components/schemas/" + name
+	}
+	return ref
+}
+
+// openAPISchema returns a copy of the given JSON schema suitable for OpenAPI: references point
+// to the components schemas, files are binary strings and hyper-schema fields are removed.
+func openAPISchema(s *genschema.JSONSchema) *genschema.JSONSchema {
+	if s == nil {
+		return nil
+	}
+	res := *s
+	res.Schema = ""
+	res.Media = nil
+	res.Links = nil
+	res.Definitions = nil
+	res.Ref = openAPIRef(s.Ref)
+	if res.Type == genschema.JSONFile {
+		res.Type = genschema.JSONString
+		res.Format = "binary"
+	}
+	res.Items = openAPISchema(s.Items)
+	res.Properties = nil
+	if len(s.Properties) > 0 {
+		res.Properties = make(map[string]*genschema.JSONSchema, len(s.Properties))
+		for n, p := range s.Properties {
+			res.Properties[n] = openAPISchema(p)
+		}
+	}
+	res.AnyOf = nil
+	for _, a := range s.AnyOf {
+		res.AnyOf = append(res.AnyOf, openAPISchema(a))
+	}
+	return &res
+}
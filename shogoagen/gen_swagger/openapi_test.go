@@ -0,0 +1,271 @@
+package genswagger_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/shogo82148/shogoa/design"
+	"github.com/shogo82148/shogoa/design/apidsl"
+	"github.com/shogo82148/shogoa/dslengine"
+	genschema "github.com/shogo82148/shogoa/shogoagen/gen_schema"
+	genswagger "github.com/shogo82148/shogoa/shogoagen/gen_swagger"
+)
+
+var _ = Describe("NewOpenAPI", func() {
+	var openapi *genswagger.OpenAPI
+	var newErr error
+
+	BeforeEach(func() {
+		openapi = nil
+		newErr = nil
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		openapi, newErr = genswagger.NewOpenAPI(design.Design)
+	})
+
+	Context("with a valid API definition", func() {
+		BeforeEach(func() {
+			apidsl.API("test", func() {
+				apidsl.Title("title")
+				apidsl.Host("example.com")
+				apidsl.Scheme("http", "https")
+				apidsl.BasePath("/base")
+				apidsl.Consumes("application/json", "application/xml")
+			})
+		})
+
+		It("sets the version and the servers", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(openapi.OpenAPI).Should(Equal("3.1.0"))
+			Ω(openapi.Info.Title).Should(Equal("title"))
+			Ω(openapi.Servers).Should(Equal([]*genswagger.Server{
+				{URL: "http://example.com/base"},
+				{URL: "https://example.com/base"},
+			}))
+			Ω(openapi.Components).Should(BeNil())
+		})
+
+		Context("with resources", func() {
+			BeforeEach(func() {
+				bottle := apidsl.Type("bottle", func() {
+					apidsl.Attribute("name", design.String)
+				})
+				mt := apidsl.MediaType("application/vnd.bottle+json", func() {
+					apidsl.TypeName("BottleMedia")
+					apidsl.Attributes(func() {
+						apidsl.Attribute("id", design.Integer)
+						apidsl.Attribute("bottle", bottle)
+					})
+					apidsl.View("default", func() {
+						apidsl.Attribute("id")
+						apidsl.Attribute("bottle")
+					})
+				})
+				apidsl.Resource("bottle", func() {
+					apidsl.BasePath("/bottles")
+					apidsl.Action("create", func() {
+						apidsl.Routing(apidsl.POST("/:id"))
+						apidsl.Params(func() {
+							apidsl.Param("id", design.Integer, func() {
+								apidsl.Minimum(1)
+							})
+							apidsl.Param("dry", design.Boolean)
+						})
+						apidsl.Headers(func() {
+							apidsl.Header("X-Request-Id")
+						})
+						apidsl.Payload(bottle)
+						apidsl.Response(design.OK, mt)
+						apidsl.Response(design.NoContent)
+					})
+					apidsl.Action("upload", func() {
+						apidsl.Routing(apidsl.PUT("/:id/image"))
+						apidsl.MultipartForm()
+						apidsl.CacheControl("no-store")
+						apidsl.Payload(func() {
+							apidsl.Attribute("image", design.File)
+						})
+						apidsl.Response(design.NoContent)
+						apidsl.Error("bottle_not_found", design.NotFound, func() {
+							apidsl.Description("The bottle does not exist")
+						})
+					})
+				})
+			})
+
+			It("builds the paths", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(openapi.Paths).Should(HaveKey("/bottles/{id}"))
+				op := openapi.Paths["/bottles/{id}"].(*genswagger.PathItem).Post
+				Ω(op.OperationID).Should(Equal("bottle#create"))
+				Ω(op.Parameters).Should(HaveLen(3))
+				Ω(op.Parameters[0].Name).Should(Equal("dry"))
+				Ω(op.Parameters[0].In).Should(Equal("query"))
+				Ω(op.Parameters[0].Schema.Type).Should(Equal(genschema.JSONType(genschema.JSONBoolean)))
+				Ω(op.Parameters[1].Name).Should(Equal("id"))
+				Ω(op.Parameters[1].In).Should(Equal("path"))
+				Ω(op.Parameters[1].Required).Should(BeTrue())
+				Ω(*op.Parameters[1].Schema.Minimum).Should(Equal(1.0))
+				Ω(op.Parameters[2].Name).Should(Equal("X-Request-Id"))
+				Ω(op.Parameters[2].In).Should(Equal("header"))
+			})
+
+			It("builds the request bodies", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				body := openapi.Paths["/bottles/{id}"].(*genswagger.PathItem).Post.RequestBody
+				Ω(body.Required).Should(BeTrue())
+				Ω(body.Content).Should(HaveLen(2))
+				Ω(body.Content["application/json"].Schema.Ref).Should(Equal("#/components/schemas/bottle"))
+				Ω(body.Content["application/xml"].Schema.Ref).Should(Equal("#/components/schemas/bottle"))
+
+				body = openapi.Paths["/bottles/{id}/image"].(*genswagger.PathItem).Put.RequestBody
+				Ω(body.Content).Should(HaveLen(1))
+				Ω(body.Content).Should(HaveKey("multipart/form-data"))
+			})
+
+			It("builds the responses", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				responses := openapi.Paths["/bottles/{id}"].(*genswagger.PathItem).Post.Responses
+				Ω(responses["200"].Content).Should(HaveKey("application/vnd.bottle+json"))
+				Ω(responses["200"].Content["application/vnd.bottle+json"].Schema.Ref).Should(Equal("#/components/schemas/BottleMedia"))
+				Ω(responses["204"].Description).Should(Equal("No Content"))
+				Ω(responses["204"].Content).Should(BeNil())
+
+				responses = openapi.Paths["/bottles/{id}/image"].(*genswagger.PathItem).Put.Responses
+				Ω(responses["404"].Content).Should(HaveKey("application/vnd.shogoa.error"))
+				Ω(responses["404"].Description).Should(Equal("Not Found\n\nError codes:\n  * `bottle_not_found`: The bottle does not exist"))
+				Ω(responses["404"].Headers).ShouldNot(HaveKey("Cache-Control"))
+				Ω(responses["204"].Headers).Should(HaveKey("Cache-Control"))
+				Ω(responses["204"].Headers["Cache-Control"].Schema.DefaultValue).Should(Equal("no-store"))
+			})
+
+			It("builds the component schemas", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				schemas := openapi.Components.Schemas
+				Ω(schemas).Should(HaveKey("bottle"))
+				Ω(schemas).Should(HaveKey("BottleMedia"))
+				Ω(schemas["BottleMedia"].Media).Should(BeNil())
+				Ω(schemas["BottleMedia"].Properties["bottle"].Ref).Should(Equal("#/components/schemas/bottle"))
+				Ω(schemas["UploadBottlePayload"].Properties["image"].Type).Should(Equal(genschema.JSONType(genschema.JSONString)))
+				Ω(schemas["UploadBottlePayload"].Properties["image"].Format).Should(Equal("binary"))
+
+				b, err := json.Marshal(openapi)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(b)).ShouldNot(ContainSubstring("#/definitions/"))
+			})
+		})
+
+		Context("with security schemes", func() {
+			BeforeEach(func() {
+				base := design.Design.DSLFunc
+				design.Design.DSLFunc = func() {
+					base()
+					apidsl.BasicAuthSecurity("basic")
+					apidsl.APIKeySecurity("key", func() {
+						apidsl.Header("X-API-Key")
+					})
+					apidsl.JWTSecurity("jwt", func() {
+						apidsl.Header("Authorization")
+						apidsl.TokenURL("http://example.com/token")
+						apidsl.Scope("api:read", "Read access")
+					})
+					apidsl.OAuth2Security("oauth2", func() {
+						apidsl.ApplicationFlow("http://example.com/token")
+						apidsl.Scope("api:write", "Write access")
+					})
+				}
+				apidsl.Resource("res", func() {
+					apidsl.Action("act", func() {
+						apidsl.Routing(apidsl.GET("/"))
+						apidsl.Security("jwt", func() {
+							apidsl.Scope("api:read")
+						})
+						apidsl.Response(design.NoContent)
+					})
+				})
+			})
+
+			It("builds the security schemes", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				schemes := openapi.Components.SecuritySchemes
+				Ω(schemes).Should(HaveLen(4))
+				Ω(schemes["basic"].Type).Should(Equal("http"))
+				Ω(schemes["basic"].Scheme).Should(Equal("basic"))
+				Ω(schemes["key"].Type).Should(Equal("apiKey"))
+				Ω(schemes["key"].In).Should(Equal("header"))
+				Ω(schemes["key"].Name).Should(Equal("X-API-Key"))
+				Ω(schemes["jwt"].Type).Should(Equal("http"))
+				Ω(schemes["jwt"].Scheme).Should(Equal("bearer"))
+				Ω(schemes["jwt"].BearerFormat).Should(Equal("JWT"))
+				Ω(schemes["oauth2"].Type).Should(Equal("oauth2"))
+				Ω(schemes["oauth2"].Flows.ClientCredentials).Should(Equal(&genswagger.OAuthFlow{
+					TokenURL: "http://example.com/token",
+					Scopes:   map[string]string{"api:write": "Write access"},
+				}))
+			})
+
+			It("sets the operation security requirements", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := openapi.Paths["/"].(*genswagger.PathItem).Get
+				Ω(op.Security).Should(Equal([]map[string][]string{{"jwt": {"api:read"}}}))
+			})
+		})
+
+		Context("with metadata", func() {
+			const extension = `{"foo":"bar"}`
+
+			var (
+				unmarshaled map[string]interface{}
+				_           = json.Unmarshal([]byte(extension), &unmarshaled)
+			)
+
+			BeforeEach(func() {
+				apidsl.Resource("res", func() {
+					apidsl.Metadata("swagger:extension:x-resource", extension)
+					apidsl.Action("act", func() {
+						apidsl.Metadata("swagger:extension:x-action", extension)
+						apidsl.Routing(
+							apidsl.PUT("/", func() {
+								apidsl.Metadata("swagger:extension:x-put", extension)
+							}),
+						)
+						apidsl.Params(func() {
+							apidsl.Param("param", func() {
+								apidsl.Metadata("swagger:extension:x-param", extension)
+							})
+						})
+						apidsl.Response(design.NoContent, func() {
+							apidsl.Metadata("swagger:extension:x-response", extension)
+						})
+					})
+				})
+				base := design.Design.DSLFunc
+				design.Design.DSLFunc = func() {
+					base()
+					apidsl.Metadata("swagger:extension:x-api", extension)
+				}
+			})
+
+			It("sets the extensions", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(openapi.Info.Extensions["x-api"]).Should(Equal(unmarshaled))
+				Ω(openapi.Paths["x-resource"]).Should(Equal(unmarshaled))
+				p := openapi.Paths["/"].(*genswagger.PathItem)
+				Ω(p.Extensions["x-action"]).Should(Equal(unmarshaled))
+				Ω(p.Put.Extensions["x-put"]).Should(Equal(unmarshaled))
+				Ω(p.Put.Parameters[0].Extensions["x-param"]).Should(Equal(unmarshaled))
+				Ω(p.Put.Responses["204"].Extensions["x-response"]).Should(Equal(unmarshaled))
+
+				b, err := json.Marshal(p)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(b)).Should(ContainSubstring(`"x-action":{"foo":"bar"}`))
+			})
+		})
+	})
+})
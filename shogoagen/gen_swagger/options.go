@@ -18,3 +18,10 @@ func OutDir(outDir string) Option {
 		g.OutDir = outDir
 	}
 }
+
+// OpenAPIOutput Generate an OpenAPI 3.1 document instead of Swagger 2.0
+func OpenAPIOutput(openAPI bool) Option {
+	return func(g *Generator) {
+		g.OpenAPI = openAPI
+	}
+}
@@ -922,6 +922,17 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		if err != nil {
 			return err
 		}
+		describeErrors(&resp.Description, action, r)
+		if cc := cachePolicy(action, r); cc != "" {
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]*Header)
+			}
+			resp.Headers["Cache-Control"] = &Header{
+				Description: "Caching policy of the response",
+				Type:        "string",
+				Default:     cc,
+			}
+		}
 		responses[strconv.Itoa(r.Status)] = resp
 	}
 
@@ -991,7 +1002,10 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefinition) {
 	produces := make(map[string]struct{})
 	action.IterateResponses(func(resp *design.ResponseDefinition) error {
-		if resp.MediaType != "" {
+		if resp.Stream != "" {
+			// streamed responses are sent with the stream content type
+			produces[resp.Stream] = struct{}{}
+		} else if resp.MediaType != "" {
 			produces[resp.MediaType] = struct{}{}
 		}
 		return nil
@@ -1093,6 +1107,38 @@ func scopesList(scopes []string) string {
 	return strings.Join(lines, "\n")
 }
 
+// describeErrors appends the list of the codes of the action errors rendered with the response r
+// to description.
+func describeErrors(description *string, action *design.ActionDefinition, r *design.ResponseDefinition) {
+	var lines []string
+	for _, e := range action.Errors {
+		if e.Response != r.Name {
+			continue
+		}
+		line := fmt.Sprintf("  * `%s`", e.Name)
+		if e.Description != "" {
+			line += ": " + e.Description
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return
+	}
+	if *description != "" {
+		*description += "\n\n"
+	}
+	*description += fmt.Sprintf("Error codes:\n%s", strings.Join(lines, "\n"))
+}
+
+// cachePolicy returns the Cache-Control header value the generated code sets on the response r of
+// action, only successful responses have one.
+func cachePolicy(action *design.ActionDefinition, r *design.ResponseDefinition) string {
+	if r.Status < 200 || r.Status >= 300 {
+		return ""
+	}
+	return action.CachePolicy()
+}
+
 func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
 	if docs == nil {
 		return nil
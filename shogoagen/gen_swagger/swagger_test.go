@@ -236,6 +236,80 @@ var _ = Describe("New", func() {
 			})
 		})
 
+		Context("with a problem details response", func() {
+			BeforeEach(func() {
+				apidsl.Resource("res", func() {
+					apidsl.Action("act", func() {
+						apidsl.Routing(
+							apidsl.GET("/"),
+						)
+						apidsl.Response(design.OK)
+						apidsl.Response(design.BadRequest, design.ProblemMedia)
+					})
+				})
+			})
+
+			It("uses the problem details schema", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Get
+				Ω(op.Produces).Should(ContainElement("application/problem+json"))
+				Ω(op.Responses["400"].Schema.Ref).Should(Equal("#/definitions/problem"))
+				Ω(genschema.Definitions).Should(HaveKey("problem"))
+				Ω(genschema.Definitions["problem"].Properties).Should(HaveKey("errors"))
+				validateSwagger(swagger)
+			})
+		})
+
+		Context("with action errors", func() {
+			BeforeEach(func() {
+				apidsl.Resource("res", func() {
+					apidsl.Action("act", func() {
+						apidsl.Routing(
+							apidsl.GET("/"),
+						)
+						apidsl.Response(design.OK)
+						apidsl.Error("invalid_name", design.BadRequest)
+						apidsl.Error("invalid_color", design.BadRequest, func() {
+							apidsl.Description("Unknown color")
+						})
+					})
+				})
+			})
+
+			It("lists the error codes in the response description", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Get
+				Ω(op.Responses["400"].Schema.Ref).Should(Equal("#/definitions/error"))
+				Ω(op.Responses["400"].Description).Should(Equal("Bad Request\n\nError codes:\n  * `invalid_name`\n  * `invalid_color`: Unknown color"))
+				Ω(op.Responses["200"].Description).Should(Equal("OK"))
+				validateSwagger(swagger)
+			})
+		})
+
+		Context("with a cache policy", func() {
+			BeforeEach(func() {
+				apidsl.Resource("res", func() {
+					apidsl.CacheControl("public, max-age=60")
+					apidsl.Action("act", func() {
+						apidsl.Routing(
+							apidsl.GET("/"),
+						)
+						apidsl.Response(design.OK)
+						apidsl.Response(design.NotFound)
+					})
+				})
+			})
+
+			It("documents the Cache-Control header of the successful responses", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Get
+				Ω(op.Responses["200"].Headers).Should(HaveKey("Cache-Control"))
+				Ω(op.Responses["200"].Headers["Cache-Control"].Default).Should(Equal("public, max-age=60"))
+				Ω(op.Responses["404"].Headers).ShouldNot(HaveKey("Cache-Control"))
+				validateSwagger(swagger)
+			})
+		})
+
 		Context("with a payload of type Any", func() {
 			BeforeEach(func() {
 				apidsl.Resource("res", func() {
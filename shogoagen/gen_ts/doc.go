@@ -0,0 +1,9 @@
+/*
+Package gents provides a generator for a TypeScript client.
+The generator produces two modules: "types.ts" declares one TypeScript type per user type, media
+type view and action payload defined in the design, "client.ts" exports a client class exposing
+one typed method per API action. The client relies on the fetch API to make the HTTP requests.
+The methods of the actions whose responses stream values (see the Stream and ServerSentEvents
+DSLs) return an AsyncIterable over the values instead and have no timeout.
+*/
+package gents
@@ -0,0 +1,18 @@
+package gents_test
+
+import (
+	"flag"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+// update causes the golden files to be rewritten with the generated content.
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenTS(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenTS Suite")
+}
@@ -0,0 +1,625 @@
+package gents
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shogo82148/shogoa/design"
+	"github.com/shogo82148/shogoa/shogoagen/codegen"
+	"github.com/shogo82148/shogoa/shogoagen/utils"
+	"github.com/shogo82148/shogoa/version"
+)
+
+// NewGenerator returns an initialized instance of a TypeScript Client Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the TypeScript client code generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Destination directory
+	Timeout  time.Duration         // Timeout used by TypeScript client when making requests
+	Scheme   string                // Scheme used by TypeScript client
+	Host     string                // Host addressed by TypeScript client
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver  string
+		timeout      time.Duration
+		scheme, host string
+	)
+
+	set := flag.NewFlagSet("ts", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.String("design", "", "")
+	set.DurationVar(&timeout, "timeout", time.Duration(20)*time.Second, "")
+	set.StringVar(&scheme, "scheme", "", "")
+	set.StringVar(&host, "host", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.Parse(os.Args[1:])
+
+	// First check compatibility
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	// Now proceed
+	g := &Generator{
+		OutDir:  outDir,
+		Timeout: timeout,
+		Scheme:  scheme,
+		Host:    host,
+		API:     design.Design,
+	}
+
+	return g.Generate()
+}
+
+// Generate produces the TypeScript types and client modules.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Timeout == 0 {
+		g.Timeout = 20 * time.Second
+	}
+	if g.Scheme == "" && len(g.API.Schemes) > 0 {
+		g.Scheme = g.API.Schemes[0]
+	}
+	if g.Scheme == "" {
+		g.Scheme = "http"
+	}
+	if g.Host == "" {
+		g.Host = g.API.Host
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "ts")
+	if err = os.RemoveAll(g.OutDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+
+	w := newTypeWriter("")
+	if err = g.collectTypes(w); err != nil {
+		return
+	}
+
+	// Compute the client methods first so that the types they reference get declared.
+	w.prefix = "types."
+	actions, err := g.clientActions(w)
+	if err != nil {
+		return
+	}
+	w.prefix = ""
+
+	if err = g.generateTypes(filepath.Join(g.OutDir, "types.ts"), w); err != nil {
+		return
+	}
+	if err = g.generateClient(filepath.Join(g.OutDir, "client.ts"), actions); err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invocation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// collectTypes records the user types, the media type views and the action payloads defined in
+// the design.
+func (g *Generator) collectTypes(w *typeWriter) error {
+	for ut := range g.API.AllUserTypes() {
+		w.UserType(ut)
+	}
+	for mt := range g.API.AllMediaTypes() {
+		views := make([]string, 0, len(mt.Views))
+		for name := range mt.Views {
+			views = append(views, name)
+		}
+		sort.Strings(views)
+		for _, view := range views {
+			if _, err := w.MediaType(mt, view); err != nil {
+				return err
+			}
+		}
+	}
+	for res := range g.API.AllResources() {
+		for action := range res.AllActions() {
+			if action.Payload != nil {
+				w.UserType(action.Payload)
+			}
+		}
+	}
+	return nil
+}
+
+// generateTypes writes the types module.
+func (g *Generator) generateTypes(tsFile string, w *typeWriter) error {
+	file, err := os.Create(tsFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, tsFile)
+
+	// Declaring a type may discover new types so do not use range.
+	decls := make([]string, 0, len(w.defs))
+	for i := 0; i < len(w.defs); i++ {
+		decls = append(decls, w.Declare(w.defs[i]))
+	}
+
+	data := map[string]any{
+		"API":          g.API,
+		"Version":      version.String(),
+		"Declarations": decls,
+	}
+	return typesTmpl.Execute(file, data)
+}
+
+// generateClient writes the client module.
+func (g *Generator) generateClient(tsFile string, actions []*tsAction) error {
+	file, err := os.Create(tsFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, tsFile)
+
+	data := map[string]any{
+		"API":     g.API,
+		"Host":    g.Host,
+		"Scheme":  g.Scheme,
+		"Timeout": int64(g.Timeout / time.Millisecond),
+		"Version": version.String(),
+		"Actions": actions,
+	}
+	return clientTmpl.Execute(file, data)
+}
+
+// tsAction is the data structure used to render the client method of an action.
+type tsAction struct {
+	// Name is the name of the generated method.
+	Name string
+	// Action is the underlying action definition.
+	Action *design.ActionDefinition
+	// Verb is the HTTP method of the action first route.
+	Verb string
+	// Path is the action first route full path.
+	Path string
+	// PathTemplate is the template literal used to build the request path.
+	PathTemplate string
+	// Args lists the method arguments with their types, excluding the trailing config argument.
+	Args []string
+	// HasParams is true if the action defines query string parameters.
+	HasParams bool
+	// HasPayload is true if the action accepts a request body.
+	HasPayload bool
+	// Multipart is true if the request body is encoded as multipart form data.
+	Multipart bool
+	// ResultType is the type of the decoded response body, the type of the streamed values if
+	// Stream is true.
+	ResultType string
+	// Stream is true if the action success responses stream a sequence of values.
+	Stream bool
+}
+
+// clientActions computes the data needed to render the client methods.
+func (g *Generator) clientActions(w *typeWriter) ([]*tsAction, error) {
+	var actions []*tsAction
+	for res := range g.API.AllResources() {
+		for action := range res.AllActions() {
+			if len(action.Routes) == 0 || action.WebSocket() {
+				continue
+			}
+			a, err := g.newTSAction(action, w)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, a)
+		}
+	}
+	return actions, nil
+}
+
+// newTSAction computes the data needed to render the client method of the given action.
+func (g *Generator) newTSAction(action *design.ActionDefinition, w *typeWriter) (*tsAction, error) {
+	route := action.Routes[0]
+	a := &tsAction{
+		Name:       tsify(action.Name + "_" + action.Parent.Name),
+		Action:     action,
+		Verb:       route.Verb,
+		Path:       route.FullPath(),
+		HasPayload: action.Payload != nil,
+		Multipart:  action.PayloadMultipart,
+		Stream:     streams(action),
+	}
+
+	var params design.Object
+	if action.Params != nil {
+		params = action.Params.Type.ToObject()
+	}
+	for _, p := range route.Params() {
+		typ := "string"
+		if att, ok := params[p]; ok {
+			typ = w.Expr(att, "  ")
+		}
+		a.Args = append(a.Args, fmt.Sprintf("%s: %s", tsify(p), typ))
+	}
+	a.PathTemplate = design.WildcardRegex.ReplaceAllStringFunc(a.Path, func(wc string) string {
+		fn := "encodeURIComponent"
+		if wc[1] == '*' {
+			// Wildcards may span multiple path segments.
+			fn = "encodeURI"
+		}
+		return fmt.Sprintf("/${%s(String(%s))}", fn, tsify(wc[2:]))
+	})
+
+	if a.HasPayload {
+		opt := ""
+		if action.PayloadOptional {
+			opt = "?"
+		}
+		a.Args = append(a.Args, fmt.Sprintf("data%s: %s", opt, w.UserType(action.Payload)))
+	}
+	if action.QueryParams != nil && len(action.QueryParams.Type.ToObject()) > 0 {
+		a.HasParams = true
+		opt := "?"
+		if len(action.QueryParams.AllRequired()) > 0 {
+			opt = ""
+		}
+		a.Args = append(a.Args, fmt.Sprintf("params%s: %s", opt, w.ObjectExpr(action.QueryParams, "  ")))
+	}
+
+	result, err := g.resultType(action, w, a.Stream)
+	if err != nil {
+		return nil, err
+	}
+	a.ResultType = result
+
+	return a, nil
+}
+
+// streams returns true if one of the action success responses streams a sequence of values.
+func streams(action *design.ActionDefinition) bool {
+	for _, r := range action.Responses {
+		if r.Status >= 200 && r.Status < 300 && r.Stream != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resultType returns the union of the types of the bodies of the action success responses. Only
+// the streamed responses are considered if stream is true, the types are then the types of the
+// streamed values.
+func (g *Generator) resultType(action *design.ActionDefinition, w *typeWriter, stream bool) (string, error) {
+	names := make([]string, 0, len(action.Responses))
+	for n, r := range action.Responses {
+		if r.Status >= 200 && r.Status < 300 && (!stream || r.Stream != "") {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	var types []string
+	seen := make(map[string]bool)
+	for _, n := range names {
+		r := action.Responses[n]
+		var typ string
+		if mt, ok := r.Type.(*design.MediaTypeDefinition); ok {
+			var err error
+			if typ, err = w.MediaType(mt, r.ViewName); err != nil {
+				return "", err
+			}
+		} else if r.Type != nil {
+			typ = w.Expr(&design.AttributeDefinition{Type: r.Type}, "  ")
+		} else if mt := g.API.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+			var err error
+			if typ, err = w.MediaType(mt, r.ViewName); err != nil {
+				return "", err
+			}
+		} else if r.MediaType != "" {
+			typ = "string"
+		} else {
+			typ = "null"
+		}
+		if !seen[typ] {
+			seen[typ] = true
+			types = append(types, typ)
+		}
+	}
+	if len(types) == 0 {
+		return "null", nil
+	}
+	return strings.Join(types, " | "), nil
+}
+
+// tsReserved lists the TypeScript reserved words that cannot be used as identifiers.
+var tsReserved = map[string]bool{
+	"await": true, "break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true, "do": true,
+	"else": true, "enum": true, "export": true, "extends": true, "false": true,
+	"finally": true, "for": true, "function": true, "if": true, "implements": true,
+	"import": true, "in": true, "instanceof": true, "interface": true, "let": true,
+	"new": true, "null": true, "package": true, "private": true, "protected": true,
+	"public": true, "return": true, "static": true, "super": true, "switch": true,
+	"this": true, "throw": true, "true": true, "try": true, "typeof": true, "var": true,
+	"void": true, "while": true, "with": true, "yield": true,
+}
+
+// tsify returns a valid TypeScript identifier in lower camel case built from the given name.
+func tsify(name string) string {
+	id := codegen.Goify(name, false)
+	// Goify appends an underscore to Go reserved words, TypeScript has its own list.
+	if strings.HasSuffix(id, "_") && !strings.HasSuffix(name, "_") {
+		id = strings.TrimSuffix(id, "_")
+	}
+	if tsReserved[id] {
+		id += "_"
+	}
+	return id
+}
+
+var (
+	funcMap = template.FuncMap{
+		"join":       strings.Join,
+		"docComment": docComment,
+	}
+	typesTmpl  = template.Must(template.New("types").Funcs(funcMap).Parse(typesT))
+	clientTmpl = template.Must(template.New("client").Funcs(funcMap).Parse(clientT))
+)
+
+const typesT = `// Code generated by shogoagen {{ .Version }}, DO NOT EDIT.
+//
+// This module declares the types used by the {{ .API.Name }} API.
+{{ range .Declarations }}
+{{ . }}{{ end }}`
+
+const clientT = `// Code generated by shogoagen {{ .Version }}, DO NOT EDIT.
+//
+// This module exports a client for the {{ .API.Name }} API.
+// It uses the fetch API to make the actual HTTP requests.
+
+import * as types from './types';
+
+/** ClientOptions configures a Client. */
+export interface ClientOptions {
+  /** scheme is the URL scheme used to make requests, defaults to "{{ .Scheme }}". */
+  scheme?: string;
+  /** host is the API host, defaults to "{{ .Host }}". Requests are relative to the page origin if empty. */
+  host?: string;
+  /** timeout is the request timeout in milliseconds, defaults to {{ .Timeout }}. */
+  timeout?: number;
+  /** headers are added to all requests. */
+  headers?: Record<string, string>;
+}
+
+/** RequestConfig overrides the client options for a single request. */
+export interface RequestConfig {
+  /** timeout is the request timeout in milliseconds. */
+  timeout?: number;
+  /** headers are added to the request. */
+  headers?: Record<string, string>;
+}
+
+/**
+ * StreamConfig overrides the client options for a single streaming request. Streaming requests
+ * have no timeout, use signal to abort them.
+ */
+export interface StreamConfig {
+  /** signal aborts the request when triggered. */
+  signal?: AbortSignal;
+  /** headers are added to the request. */
+  headers?: Record<string, string>;
+}
+
+/** ClientResponse is the result of a successful request. */
+export interface ClientResponse<T> {
+  /** status is the HTTP response status code. */
+  status: number;
+  /** headers are the HTTP response headers. */
+  headers: Headers;
+  /** data is the decoded response body. */
+  data: T;
+}
+
+/** ClientError is thrown when the API responds with a 4xx or 5xx status code. */
+export class ClientError extends Error {
+  /** response is the API response. */
+  readonly response: ClientResponse<unknown>;
+
+  constructor(response: ClientResponse<unknown>) {
+    super('request failed with status ' + response.status);
+    this.name = 'ClientError';
+    this.response = response;
+  }
+}
+
+/** Query is the type of the query string parameters. */
+type Query = Record<string, unknown>;
+
+/** Request describes a request made by the client. */
+interface Request {
+  method: string;
+  path: string;
+  params?: Query;
+  data?: unknown;
+  multipart?: boolean;
+}
+
+/** Client is the client for the {{ .API.Name }} API. */
+export class Client {
+  private readonly urlPrefix: string;
+  private readonly timeout: number;
+  private readonly headers: Record<string, string>;
+
+  constructor(options: ClientOptions = {}) {
+    const scheme = options.scheme || '{{ .Scheme }}';
+    const host = options.host ?? '{{ .Host }}';
+    this.urlPrefix = host ? scheme + '://' + host : '';
+    this.timeout = options.timeout || {{ .Timeout }};
+    this.headers = Object.assign({}, options.headers);
+  }
+{{ range .Actions }}
+{{ if .Action.Description }}{{ docComment "  " .Action.Description }}{{ else }}  /** {{ .Name }} calls the {{ .Action.Name }} action of the {{ .Action.Parent.Name }} resource. */
+{{ end }}{{ if .Stream }}  {{ .Name }}({{ range .Args }}{{ . }}, {{ end }}config?: StreamConfig): AsyncIterable<{{ .ResultType }}> {
+    return this.stream<{{ .ResultType }}>({{ else }}  {{ .Name }}({{ range .Args }}{{ . }}, {{ end }}config?: RequestConfig): Promise<ClientResponse<{{ .ResultType }}>> {
+    return this.request<{{ .ResultType }}>({{ end }}{
+      method: '{{ .Verb }}',
+      path: ` + "`{{ .PathTemplate }}`" + `,{{ if .HasParams }}
+      params: params,{{ end }}{{ if .HasPayload }}
+      data: data,{{ end }}{{ if .Multipart }}
+      multipart: true,{{ end }}
+    }, config);
+  }
+{{ end }}
+  private async request<T>(req: Request, config: RequestConfig = {}): Promise<ClientResponse<T>> {
+    const controller = new AbortController();
+    const timer = setTimeout(() => controller.abort(), config.timeout || this.timeout);
+    try {
+      const result = await readResponse(await this.send(req, config.headers, controller.signal));
+      if (result.status < 200 || result.status > 299) {
+        throw new ClientError(result);
+      }
+      return result as ClientResponse<T>;
+    } finally {
+      clearTimeout(timer);
+    }
+  }
+
+  // stream sends the request and yields the values streamed in the response body as newline
+  // delimited JSON or as the data of server-sent events depending on the response content type.
+  private async *stream<T>(req: Request, config: StreamConfig = {}): AsyncGenerator<T> {
+    const resp = await this.send(req, config.headers, config.signal);
+    if (!resp.ok) {
+      throw new ClientError(await readResponse(resp));
+    }
+    if (!resp.body) {
+      return;
+    }
+    const sse = /^text\/event-stream/i.test(resp.headers.get('Content-Type') || '');
+    let data: string[] = [];
+    for await (const line of readLines(resp.body)) {
+      if (!sse) {
+        if (line.trim() !== '') {
+          yield JSON.parse(line) as T;
+        }
+      } else if (line === '') {
+        // An empty line dispatches the event.
+        if (data.length > 0) {
+          yield JSON.parse(data.join('\n')) as T;
+          data = [];
+        }
+      } else if (line.startsWith('data:')) {
+        data.push(line.slice(line.startsWith('data: ') ? 6 : 5));
+      }
+    }
+  }
+
+  private send(req: Request, extra: Record<string, string> | undefined, signal?: AbortSignal): Promise<Response> {
+    const headers: Record<string, string> = Object.assign({}, this.headers, extra);
+    const init: RequestInit = { method: req.method, headers: headers, signal: signal };
+    if (req.data !== undefined) {
+      if (req.multipart) {
+        const form = new FormData();
+        for (const [key, value] of Object.entries(req.data as Record<string, unknown>)) {
+          for (const v of Array.isArray(value) ? value : [value]) {
+            if (v !== undefined && v !== null) {
+              form.append(key, v instanceof Blob ? v : String(v));
+            }
+          }
+        }
+        init.body = form;
+      } else {
+        headers['Content-Type'] = headers['Content-Type'] || 'application/json';
+        init.body = JSON.stringify(req.data);
+      }
+    }
+    return fetch(this.urlPrefix + req.path + buildQuery(req.params), init);
+  }
+}
+
+// readResponse reads the given response body, JSON bodies are decoded.
+async function readResponse(resp: Response): Promise<ClientResponse<unknown>> {
+  const contentType = resp.headers.get('Content-Type') || '';
+  let data: unknown = null;
+  if (resp.status !== 204) {
+    data = /^application\/([^;]*\+)?json\s*(;|$)/i.test(contentType) ? await resp.json() : await resp.text();
+  }
+  return { status: resp.status, headers: resp.headers, data: data };
+}
+
+// readLines yields the lines of the given body without their line terminators.
+async function* readLines(body: ReadableStream<Uint8Array>): AsyncGenerator<string> {
+  const reader = body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+  try {
+    for (;;) {
+      const { done, value } = await reader.read();
+      buffer += done ? decoder.decode() : decoder.decode(value, { stream: true });
+      // A carriage return ending the buffer may be followed by a line feed in the next chunk.
+      const lines = buffer.split(done ? /\r\n|\r|\n/ : /\r\n|\r(?!$)|\n/);
+      buffer = lines.pop() ?? '';
+      yield* lines;
+      if (done) {
+        if (buffer !== '') {
+          yield buffer;
+        }
+        return;
+      }
+    }
+  } finally {
+    // Close the connection if the iteration stops early.
+    reader.cancel().catch(() => undefined);
+  }
+}
+
+// buildQuery returns the query string built from the given parameters. Arrays produce one value
+// per element, undefined and null values are skipped.
+function buildQuery(params?: Query): string {
+  const query = new URLSearchParams();
+  for (const [key, value] of Object.entries(params || {})) {
+    for (const v of Array.isArray(value) ? value : [value]) {
+      if (v !== undefined && v !== null) {
+        query.append(key, String(v));
+      }
+    }
+  }
+  const qs = query.toString();
+  return qs ? '?' + qs : '';
+}
+`
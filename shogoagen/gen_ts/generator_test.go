@@ -0,0 +1,238 @@
+package gents_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/shogo82148/shogoa/design"
+	"github.com/shogo82148/shogoa/design/apidsl"
+	"github.com/shogo82148/shogoa/dslengine"
+	"github.com/shogo82148/shogoa/shogoagen/codegen"
+	gents "github.com/shogo82148/shogoa/shogoagen/gen_ts"
+	"github.com/shogo82148/shogoa/version"
+)
+
+// compareGolden compares the content of the generated file with the golden file of the same
+// name in the testdata directory.
+func compareGolden(outDir, name string) {
+	content, err := os.ReadFile(filepath.Join(outDir, "ts", name))
+	Ω(err).ShouldNot(HaveOccurred())
+	actual := strings.ReplaceAll(string(content), version.String(), "{{VERSION}}")
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		Ω(os.WriteFile(golden, []byte(actual), 0644)).Should(Succeed())
+	}
+	expected, err := os.ReadFile(golden)
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(actual).Should(Equal(string(expected)))
+}
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = os.MkdirTemp(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"shogoagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = gents.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a design", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			apidsl.API("test api", func() {
+				apidsl.Host("localhost:8080")
+				apidsl.Scheme("https")
+				apidsl.BasePath("/api")
+			})
+			var Bottle = apidsl.Type("bottle", func() {
+				apidsl.Attribute("name", design.String, "Name of the bottle")
+				apidsl.Attribute("vintage", design.Integer)
+				apidsl.Attribute("color", design.String, func() {
+					apidsl.Enum("red", "white", "rose")
+				})
+				apidsl.Attribute("tags", apidsl.HashOf(design.String, design.Any))
+				apidsl.Attribute("content-type", design.String)
+				apidsl.Required("name", "color")
+			})
+			var Winery = apidsl.MediaType("application/vnd.winery+json", func() {
+				apidsl.TypeName("Winery")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", design.Integer)
+					apidsl.Attribute("name", design.String)
+					apidsl.Required("id", "name")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+				apidsl.View("tiny", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			var BottleMedia = apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Description("A bottle of wine")
+				apidsl.TypeName("BottleMedia")
+				apidsl.Reference(Bottle)
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", design.Integer)
+					apidsl.Attribute("name")
+					apidsl.Attribute("color")
+					apidsl.Attribute("ratings", apidsl.ArrayOf(design.Number))
+					apidsl.Attribute("winery", Winery)
+					apidsl.Required("id", "name")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+					apidsl.Attribute("color")
+					apidsl.Attribute("ratings")
+					apidsl.Attribute("winery", func() {
+						apidsl.View("tiny")
+					})
+				})
+				apidsl.View("tiny", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Params(func() {
+						apidsl.Param("years", apidsl.ArrayOf(design.Integer))
+						apidsl.Param("sort", design.String, func() {
+							apidsl.Enum("name", "vintage")
+						})
+					})
+					apidsl.Response(design.OK, apidsl.CollectionOf(BottleMedia))
+				})
+				apidsl.Action("show", func() {
+					apidsl.Description("show returns the bottle with the given id.")
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+					})
+					apidsl.Response(design.OK, func() {
+						apidsl.Media(BottleMedia, "tiny")
+					})
+					apidsl.Response(design.NotFound)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(Bottle)
+					apidsl.Response(design.Created)
+				})
+				apidsl.Action("upload", func() {
+					apidsl.Routing(apidsl.POST("/:id/files/*filepath"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+						apidsl.Param("filepath", design.String)
+					})
+					apidsl.MultipartForm()
+					apidsl.Payload(func() {
+						apidsl.Attribute("file", design.File)
+						apidsl.Required("file")
+					})
+					apidsl.Response(design.OK, "text/plain")
+				})
+				apidsl.Action("watch", func() {
+					apidsl.Routing(apidsl.GET("/watch"))
+					apidsl.Params(func() {
+						apidsl.Param("sort", design.String)
+					})
+					apidsl.Response(design.OK, BottleMedia, func() {
+						apidsl.Stream()
+					})
+				})
+			})
+			Ω(dslengine.Run()).Should(Succeed())
+		})
+
+		It("generates the types module", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(HaveLen(3))
+			compareGolden(outDir, "types.ts")
+		})
+
+		It("generates the client module", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			compareGolden(outDir, "client.ts")
+		})
+
+		Context("with --scheme, --host and --timeout", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--scheme=http", "--host=example.com", "--timeout=5s")
+			})
+
+			It("uses the given values as defaults", func() {
+				Ω(genErr).ShouldNot(HaveOccurred())
+				content, err := os.ReadFile(filepath.Join(outDir, "ts", "client.ts"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("const scheme = options.scheme || 'http';"))
+				Ω(string(content)).Should(ContainSubstring("const host = options.host ?? 'example.com';"))
+				Ω(string(content)).Should(ContainSubstring("this.timeout = options.timeout || 5000;"))
+			})
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gents.Generator
+
+	var args = struct {
+		api     *design.APIDefinition
+		outDir  string
+		timeout time.Duration
+		scheme  string
+		host    string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:  "out_dir",
+		timeout: time.Millisecond * 500,
+		scheme:  "http",
+		host:    "localhost",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gents.NewGenerator(
+				gents.API(args.api),
+				gents.OutDir(args.outDir),
+				gents.Timeout(args.timeout),
+				gents.Scheme(args.scheme),
+				gents.Host(args.host),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Timeout).Should(Equal(args.timeout))
+			Ω(generator.Scheme).Should(Equal(args.scheme))
+			Ω(generator.Host).Should(Equal(args.host))
+		})
+	})
+})
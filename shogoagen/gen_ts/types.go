@@ -0,0 +1,209 @@
+package gents
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shogo82148/shogoa/design"
+	"github.com/shogo82148/shogoa/shogoagen/codegen"
+)
+
+// typeDef is a named TypeScript type declared in the types module.
+type typeDef struct {
+	// Name is the TypeScript type name.
+	Name string
+	// Description is the type documentation.
+	Description string
+	// Att is the attribute describing the type.
+	Att *design.AttributeDefinition
+}
+
+// typeWriter renders TypeScript type expressions and keeps track of the named types they
+// reference so that all of them get declared.
+type typeWriter struct {
+	// prefix is prepended to named type references, e.g. "types." when rendering code that
+	// imports the types module as a namespace.
+	prefix string
+	// defs lists the named types to declare in the order they were discovered.
+	defs []*typeDef
+	// seen indexes the named types by name.
+	seen map[string]bool
+}
+
+// newTypeWriter returns a type writer that prefixes named type references with prefix.
+func newTypeWriter(prefix string) *typeWriter {
+	return &typeWriter{prefix: prefix, seen: make(map[string]bool)}
+}
+
+// UserType records the given user type and returns the name of the corresponding TypeScript
+// type.
+func (w *typeWriter) UserType(ut *design.UserTypeDefinition) string {
+	name := codegen.Goify(ut.TypeName, true)
+	if !w.seen[name] {
+		w.seen[name] = true
+		w.defs = append(w.defs, &typeDef{Name: name, Description: ut.Description, Att: ut.AttributeDefinition})
+	}
+	return w.prefix + name
+}
+
+// MediaType records the projection of the given media type with the given view and returns the
+// name of the corresponding TypeScript type.
+func (w *typeWriter) MediaType(mt *design.MediaTypeDefinition, view string) (string, error) {
+	if view == "" {
+		view = design.DefaultView
+	}
+	p, links, err := mt.Project(view)
+	if err != nil {
+		return "", err
+	}
+	if links != nil {
+		w.UserType(links)
+	}
+	return w.UserType(p.UserTypeDefinition), nil
+}
+
+// Expr returns the TypeScript type expression for the given attribute. indent is used to
+// indent the fields of inline object types.
+func (w *typeWriter) Expr(att *design.AttributeDefinition, indent string) string {
+	if att.Validation != nil && len(att.Validation.Values) > 0 && att.Type.IsPrimitive() {
+		return literalUnion(att.Validation.Values)
+	}
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		return primitiveExpr(actual)
+	case *design.Array:
+		elem := w.Expr(actual.ElemType, indent)
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case *design.Hash:
+		key := "string"
+		if k := w.Expr(actual.KeyType, indent); k == "number" || strings.HasPrefix(k, "\"") {
+			key = k
+		}
+		return fmt.Sprintf("Record<%s, %s>", key, w.Expr(actual.ElemType, indent))
+	case design.Object:
+		return w.ObjectExpr(att, indent)
+	case *design.MediaTypeDefinition:
+		name, err := w.MediaType(actual, att.View)
+		if err != nil {
+			return "unknown"
+		}
+		return name
+	case *design.UserTypeDefinition:
+		return w.UserType(actual)
+	default:
+		return "unknown"
+	}
+}
+
+// ObjectExpr returns the TypeScript type literal for the given object attribute.
+func (w *typeWriter) ObjectExpr(att *design.AttributeDefinition, indent string) string {
+	obj := att.Type.ToObject()
+	if len(obj) == 0 {
+		return "Record<string, unknown>"
+	}
+	required := make(map[string]bool)
+	for _, n := range att.AllRequired() {
+		required[n] = true
+	}
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	fieldIndent := indent + "  "
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, n := range names {
+		field := obj[n]
+		if field.Description != "" {
+			b.WriteString(docComment(fieldIndent, field.Description))
+		}
+		opt := "?"
+		if required[n] {
+			opt = ""
+		}
+		fmt.Fprintf(&b, "%s%s%s: %s;\n", fieldIndent, propertyName(n), opt, w.Expr(field, fieldIndent))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// Declare returns the TypeScript declaration of the given named type.
+func (w *typeWriter) Declare(def *typeDef) string {
+	var b strings.Builder
+	if def.Description != "" {
+		b.WriteString(docComment("", def.Description))
+	}
+	if def.Att.Type.IsObject() {
+		fmt.Fprintf(&b, "export interface %s %s\n", def.Name, w.ObjectExpr(def.Att, ""))
+	} else {
+		fmt.Fprintf(&b, "export type %s = %s;\n", def.Name, w.Expr(def.Att, ""))
+	}
+	return b.String()
+}
+
+// primitiveExpr returns the TypeScript type of the given primitive type.
+func primitiveExpr(p design.Primitive) string {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "boolean"
+	case design.IntegerKind, design.NumberKind:
+		return "number"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind:
+		return "string"
+	case design.FileKind:
+		return "Blob"
+	default:
+		return "unknown"
+	}
+}
+
+// literalUnion returns the union of the TypeScript literal types of the given enum values.
+func literalUnion(values []any) string {
+	lits := make([]string, 0, len(values))
+	for _, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		lits = append(lits, string(b))
+	}
+	if len(lits) == 0 {
+		return "unknown"
+	}
+	return strings.Join(lits, " | ")
+}
+
+// identifierRegex matches valid TypeScript identifiers.
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// propertyName returns the given name quoted if it is not a valid TypeScript identifier.
+func propertyName(name string) string {
+	if identifierRegex.MatchString(name) {
+		return name
+	}
+	b, _ := json.Marshal(name)
+	return string(b)
+}
+
+// docComment returns the given text as a JSDoc comment indented with indent.
+func docComment(indent, text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s/** %s */\n", indent, strings.TrimSpace(lines[0]))
+	}
+	var b strings.Builder
+	b.WriteString(indent + "/**\n")
+	for _, l := range lines {
+		b.WriteString(strings.TrimRight(indent+" * "+strings.TrimSpace(l), " ") + "\n")
+	}
+	b.WriteString(indent + " */\n")
+	return b.String()
+}
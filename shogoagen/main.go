@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/shogo82148/shogoa/shogoagen/codegen"
+	genimport "github.com/shogo82148/shogoa/shogoagen/gen_import"
 	"github.com/shogo82148/shogoa/shogoagen/meta"
 	"github.com/shogo82148/shogoa/shogoagen/utils"
 	"github.com/shogo82148/shogoa/version"
@@ -110,6 +111,14 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(swaggerCmd)
 
+	// openapiCmd implements the "openapi" command.
+	openapiCmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Generate OpenAPI 3.1",
+		Run:   func(c *cobra.Command, _ []string) { files, err = runEntry("genswagger", "GenerateOpenAPI", c) },
+	}
+	rootCmd.AddCommand(openapiCmd)
+
 	// jsCmd implements the "js" command.
 	var (
 		timeout      = time.Duration(20) * time.Second
@@ -127,6 +136,17 @@ package and tool and the Swagger specification for the API.
 	jsCmd.Flags().BoolVar(&noexample, "noexample", false, `Skip generation of example HTML and controller`)
 	rootCmd.AddCommand(jsCmd)
 
+	// tsCmd implements the "ts" command.
+	tsCmd := &cobra.Command{
+		Use:   "ts",
+		Short: "Generate TypeScript types and client",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gents", c) },
+	}
+	tsCmd.Flags().DurationVar(&timeout, "timeout", timeout, `the duration before the request times out.`)
+	tsCmd.Flags().StringVar(&scheme, "scheme", "", `the URL scheme used to make requests to the API, defaults to the scheme defined in the API design if any.`)
+	tsCmd.Flags().StringVar(&host, "host", "", `the API hostname, defaults to the hostname defined in the API design if any`)
+	rootCmd.AddCommand(tsCmd)
+
 	// schemaCmd implements the "schema" command.
 	schemaCmd := &cobra.Command{
 		Use:   "schema",
@@ -135,6 +155,19 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(schemaCmd)
 
+	// importCmd implements the "import" command.
+	var (
+		specPath, designPkgName string
+	)
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Generate a design package from a Swagger or OpenAPI specification",
+		Run:   func(c *cobra.Command, _ []string) { files, err = runImport(c, specPath, designPkgName) },
+	}
+	importCmd.Flags().StringVar(&specPath, "spec", "", "path or URL to the Swagger 2.0 or OpenAPI 3 specification (JSON or YAML)")
+	importCmd.Flags().StringVar(&designPkgName, "pkg", "design", "name of the generated design package")
+	rootCmd.AddCommand(importCmd)
+
 	// genCmd implements the "gen" command.
 	var (
 		pkgPath string
@@ -246,6 +279,11 @@ package and tool and the Swagger specification for the API.
 }
 
 func run(pkg string, c *cobra.Command) ([]string, error) {
+	return runEntry(pkg, "Generate", c)
+}
+
+// runEntry runs the generator package entry point with the given name.
+func runEntry(pkg, entry string, c *cobra.Command) ([]string, error) {
 	pkgPath := fmt.Sprintf("github.com/shogo82148/shogoa/shogoagen/gen_%s", pkg[3:])
 	pkgSrcPath, err := codegen.PackageSourcePath(pkgPath)
 	if err != nil {
@@ -255,7 +293,21 @@ func run(pkg string, c *cobra.Command) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid package import path: %s", err)
 	}
-	return generate(pkgName, pkgPath, c, nil)
+	return generate(pkgName+"."+entry, pkgPath, c, nil)
+}
+
+// runImport runs the import generator. The generator does not need a design package so it is
+// invoked directly instead of going through the meta generator.
+func runImport(c *cobra.Command, spec, pkg string) ([]string, error) {
+	out, err := filepath.Abs(c.Flag("out").Value.String())
+	if err != nil {
+		return nil, err
+	}
+	return genimport.NewGenerator(
+		genimport.Spec(spec),
+		genimport.OutDir(out),
+		genimport.Package(pkg),
+	).Generate()
 }
 
 func runGen(c *cobra.Command, args []string) ([]string, error) {
@@ -268,10 +320,10 @@ func runGen(c *cobra.Command, args []string) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid plugin package import path: %s", err)
 	}
-	return generate(pkgName, pkgPath, c, args)
+	return generate(pkgName+".Generate", pkgPath, c, args)
 }
 
-func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]string, error) {
+func generate(genfunc, pkgPath string, c *cobra.Command, args []string) ([]string, error) {
 	m := make(map[string]string)
 	c.Flags().Visit(func(f *pflag.Flag) {
 		if f.Name != "pkg-path" {
@@ -289,7 +341,7 @@ func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]strin
 	}
 
 	gen, err := meta.NewGenerator(
-		pkgName+".Generate",
+		genfunc,
 		[]*codegen.ImportSpec{codegen.SimpleImport(pkgPath)},
 		m,
 		args,
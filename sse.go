@@ -0,0 +1,209 @@
+package shogoa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerSentEvent is an event written to an EventStream.
+type ServerSentEvent struct {
+	// ID is the event ID, clients send the ID of the last event they received in the
+	// Last-Event-ID header when reconnecting.
+	ID string
+	// Event is the event type, clients dispatch events with no type as "message" events.
+	Event string
+	// Retry is the reconnection time the client should use if the connection is lost.
+	Retry time.Duration
+	// Data is the event payload encoded with the service JSON encoder, no data is sent if nil.
+	Data any
+}
+
+// EventStream writes server-sent events (text/event-stream) to the response of a request. Use
+// NewEventStream to create an event stream from the request context:
+//
+//	func (c *NotificationController) Watch(ctx *app.WatchNotificationContext) error {
+//		stream, err := shogoa.NewEventStream(ctx, 15*time.Second)
+//		if err != nil {
+//			return err
+//		}
+//		defer stream.Close()
+//		for {
+//			select {
+//			case n := <-c.notifications:
+//				if err := stream.Send(&shogoa.ServerSentEvent{ID: n.ID, Data: n}); err != nil {
+//					return err
+//				}
+//			case <-stream.Done():
+//				return nil
+//			}
+//		}
+//	}
+//
+// The stream is done when the request context is cancelled - for example because the client went
+// away, when Service.CancelAll is called or when the stream is closed.
+type EventStream struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	resp        *ResponseData
+	encoder     *encoderPool
+	lastEventID string
+
+	mu  sync.Mutex   // Serializes writes
+	buf bytes.Buffer // Event being written
+	wg  sync.WaitGroup
+}
+
+// NewEventStream writes the headers of a server-sent events response and returns the stream used
+// to send events. If heartbeat is greater than 0 the stream sends a comment to the client every
+// heartbeat when idle so that proxies do not close the connection. The stream must be closed before
+// the request handler returns.
+func NewEventStream(ctx context.Context, heartbeat time.Duration) (*EventStream, error) {
+	return newEventStream(ctx, http.StatusOK, heartbeat)
+}
+
+func newEventStream(ctx context.Context, code int, heartbeat time.Duration) (*EventStream, error) {
+	r := ContextResponse(ctx)
+	if r == nil {
+		return nil, fmt.Errorf("no response data in context")
+	}
+	s := &EventStream{resp: r, encoder: jsonEncoder(r.Service)}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	if r.Service != nil && r.Service.Context != nil {
+		stop := context.AfterFunc(r.Service.Context, s.cancel)
+		context.AfterFunc(s.ctx, func() { stop() })
+	}
+	if req := ContextRequest(ctx); req != nil && req.Request != nil {
+		s.lastEventID = req.Header.Get("Last-Event-ID")
+	}
+
+	r.Header().Set("Content-Type", EventStreamContentType)
+	r.Header().Set("Cache-Control", "no-cache")
+	r.WriteHeader(code)
+	if err := flush(r); err != nil {
+		s.cancel()
+		return nil, err
+	}
+
+	if heartbeat > 0 {
+		s.wg.Add(1)
+		go s.heartbeat(heartbeat)
+	}
+	return s, nil
+}
+
+// LastEventID returns the value of the Last-Event-ID request header. Clients set the header to
+// the ID of the last event they received when reconnecting so that the stream may resume from it.
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Context returns the stream context, it is cancelled when the stream is done.
+func (s *EventStream) Context() context.Context {
+	return s.ctx
+}
+
+// Done returns a channel that is closed when the stream is done.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send writes the event to the client and flushes the response. It returns the stream context
+// error if the stream is done.
+func (s *EventStream) Send(ev *ServerSentEvent) error {
+	if strings.ContainsAny(ev.ID, "\r\n\x00") {
+		return fmt.Errorf("invalid event ID %#v", ev.ID)
+	}
+	if strings.ContainsAny(ev.Event, "\r\n") {
+		return fmt.Errorf("invalid event type %#v", ev.Event)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.Reset()
+	if ev.ID != "" {
+		s.buf.WriteString("id: " + ev.ID + "\n")
+	}
+	if ev.Event != "" {
+		s.buf.WriteString("event: " + ev.Event + "\n")
+	}
+	if ev.Retry > 0 {
+		s.buf.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+	if ev.Data != nil {
+		var data bytes.Buffer
+		if err := s.encode(ev.Data, &data); err != nil {
+			return err
+		}
+		for _, line := range strings.Split(strings.TrimRight(data.String(), "\r\n"), "\n") {
+			s.buf.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+		}
+	}
+	s.buf.WriteString("\n")
+	return s.write(s.buf.Bytes())
+}
+
+// Close stops the heartbeats and marks the stream as done. Close does not close the underlying
+// connection, the response completes when the request handler returns.
+func (s *EventStream) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// heartbeat sends a comment every interval until the stream is done.
+func (s *EventStream) heartbeat(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.write([]byte(":\n\n"))
+			s.mu.Unlock()
+		}
+	}
+}
+
+// write writes b to the response and flushes it, the stream is done if writing fails.
+// The caller must hold s.mu.
+func (s *EventStream) write(b []byte) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.resp.Write(b); err != nil {
+		s.cancel()
+		return err
+	}
+	if err := flush(s.resp); err != nil {
+		s.cancel()
+		return err
+	}
+	return nil
+}
+
+// encode writes the JSON encoding of v to w.
+func (s *EventStream) encode(v any, w *bytes.Buffer) error {
+	if s.encoder != nil {
+		return s.encoder.Encode(v, w)
+	}
+	return json.NewEncoder(w).Encode(v)
+}
+
+// jsonEncoder returns the JSON encoder registered on the service if any.
+func jsonEncoder(service *Service) *encoderPool {
+	if service == nil || service.Encoder == nil {
+		return nil
+	}
+	p, _, _ := service.Encoder.negotiate("application/json")
+	return p
+}
@@ -0,0 +1,99 @@
+package shogoa
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStream(t *testing.T) {
+	newStream := func(t *testing.T, service *Service, heartbeat time.Duration) (*EventStream, *httptest.ResponseRecorder) {
+		t.Helper()
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Last-Event-ID", "41")
+		ctx := NewContext(rw, req, nil)
+		ContextResponse(ctx).Service = service
+		stream, err := NewEventStream(ctx, heartbeat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stream, rw
+	}
+
+	t.Run("events", func(t *testing.T) {
+		stream, rw := newStream(t, New("test"), 0)
+		if got := stream.LastEventID(); got != "41" {
+			t.Errorf("unexpected last event ID: %q", got)
+		}
+		events := []*ServerSentEvent{
+			{ID: "42", Event: "bottle", Data: map[string]string{"name": "foo"}},
+			{Retry: 3 * time.Second},
+			{Data: "multi\nline"},
+		}
+		for _, ev := range events {
+			if err := stream.Send(ev); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stream.Close()
+
+		if got := rw.Header().Get("Content-Type"); got != "text/event-stream" {
+			t.Errorf("unexpected Content-Type: %q", got)
+		}
+		want := "id: 42\nevent: bottle\ndata: {\"name\":\"foo\"}\n\n" +
+			"retry: 3000\n\n" +
+			"data: \"multi\\nline\"\n\n"
+		if got := rw.Body.String(); got != want {
+			t.Errorf("unexpected body:\nwant %q\ngot  %q", want, got)
+		}
+		if !rw.Flushed {
+			t.Error("response was not flushed")
+		}
+	})
+
+	t.Run("invalid event", func(t *testing.T) {
+		stream, _ := newStream(t, New("test"), 0)
+		defer stream.Close()
+		if err := stream.Send(&ServerSentEvent{ID: "4\n2"}); err == nil {
+			t.Error("expected an error for an invalid ID")
+		}
+		if err := stream.Send(&ServerSentEvent{Event: "bot\rtle"}); err == nil {
+			t.Error("expected an error for an invalid event type")
+		}
+	})
+
+	t.Run("heartbeat", func(t *testing.T) {
+		stream, rw := newStream(t, New("test"), time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		stream.Close()
+		if !strings.HasPrefix(rw.Body.String(), ":\n\n") {
+			t.Errorf("unexpected body: %q", rw.Body.String())
+		}
+	})
+
+	t.Run("cancel all", func(t *testing.T) {
+		service := New("test")
+		stream, _ := newStream(t, service, 0)
+		defer stream.Close()
+		service.CancelAll()
+		select {
+		case <-stream.Done():
+		case <-time.After(time.Second):
+			t.Fatal("stream not done after CancelAll")
+		}
+		if err := stream.Send(&ServerSentEvent{Data: 1}); err != context.Canceled {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		stream, _ := newStream(t, New("test"), 0)
+		stream.Close()
+		if err := stream.Send(&ServerSentEvent{Data: 1}); err != context.Canceled {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
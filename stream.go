@@ -0,0 +1,102 @@
+package shogoa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// List of the content types used to stream response bodies.
+const (
+	// NDJSONContentType is the content type of newline delimited JSON streams.
+	NDJSONContentType = "application/x-ndjson"
+	// EventStreamContentType is the content type of server-sent events streams.
+	EventStreamContentType = "text/event-stream"
+)
+
+// SendStream sends a HTTP response with the given status code whose body is the sequence of values
+// produced by seq. contentType selects the framing of the values: NDJSONContentType writes one JSON
+// value per line while EventStreamContentType writes each value as the data of a server-sent event.
+// The values are encoded with the JSON encoder registered on the service if any, encoding/json
+// otherwise. The response is flushed after each value so that clients receive values as they are
+// produced. SendStream stops and returns the context error if ctx is done before seq is exhausted,
+// server-sent events streams also stop when Service.CancelAll is called, see EventStream.
+func SendStream[T any](ctx context.Context, code int, contentType string, seq iter.Seq[T]) error {
+	switch contentType {
+	case NDJSONContentType:
+		return sendNDJSON(ctx, code, seq)
+	case EventStreamContentType:
+		stream, err := newEventStream(ctx, code, 0)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		for v := range seq {
+			if err := stream.Send(&ServerSentEvent{Data: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported stream content type %#v", contentType)
+	}
+}
+
+// sendNDJSON writes the values of seq as newline delimited JSON.
+func sendNDJSON[T any](ctx context.Context, code int, seq iter.Seq[T]) error {
+	r := ContextResponse(ctx)
+	if r == nil {
+		return fmt.Errorf("no response data in context")
+	}
+	p := jsonEncoder(r.Service)
+
+	if r.Header().Get("Content-Type") == "" {
+		r.Header().Set("Content-Type", NDJSONContentType)
+	}
+	r.Header().Set("Cache-Control", "no-cache")
+	r.WriteHeader(code)
+	if err := flush(r); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for v := range seq {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		buf.Reset()
+		var err error
+		if p != nil {
+			err = p.Encode(v, &buf)
+		} else {
+			err = json.NewEncoder(&buf).Encode(v)
+		}
+		if err != nil {
+			return err
+		}
+		// Encoders may terminate values with a newline, make sure there is exactly one.
+		buf.Truncate(len(bytes.TrimRight(buf.Bytes(), "\r\n")))
+		buf.WriteByte('\n')
+		if _, err := r.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		if err := flush(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flush sends any buffered data to the client, it is a no-op if the underlying response writer
+// does not support flushing.
+func flush(r *ResponseData) error {
+	err := http.NewResponseController(r).Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
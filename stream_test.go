@@ -0,0 +1,70 @@
+package shogoa
+
+import (
+	"context"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestSendStream(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	items := []*item{{Name: "foo"}, {Name: "bar"}}
+
+	send := func(ctx context.Context, contentType string) (*httptest.ResponseRecorder, error) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		ctx = NewContext(rw, req, nil)
+		ContextResponse(ctx).Service = New("test")
+		return rw, SendStream(ctx, 200, contentType, slices.Values(items))
+	}
+
+	t.Run("ndjson", func(t *testing.T) {
+		rw, err := send(context.Background(), NDJSONContentType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rw.Header().Get("Content-Type"); got != NDJSONContentType {
+			t.Errorf("unexpected Content-Type: %q", got)
+		}
+		if want := "{\"name\":\"foo\"}\n{\"name\":\"bar\"}\n"; rw.Body.String() != want {
+			t.Errorf("unexpected body: want %q, got %q", want, rw.Body.String())
+		}
+		if !rw.Flushed {
+			t.Error("response was not flushed")
+		}
+	})
+
+	t.Run("server-sent events", func(t *testing.T) {
+		rw, err := send(context.Background(), EventStreamContentType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rw.Header().Get("Content-Type"); got != EventStreamContentType {
+			t.Errorf("unexpected Content-Type: %q", got)
+		}
+		if want := "data: {\"name\":\"foo\"}\n\ndata: {\"name\":\"bar\"}\n\n"; rw.Body.String() != want {
+			t.Errorf("unexpected body: want %q, got %q", want, rw.Body.String())
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rw, err := send(ctx, NDJSONContentType)
+		if err != context.Canceled {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rw.Code != 200 || rw.Body.Len() != 0 {
+			t.Errorf("unexpected response: %d %q", rw.Code, rw.Body.String())
+		}
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		if _, err := send(context.Background(), "application/json"); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/shogo82148/shogoa"
+)
+
+// Middleware returns a middleware that creates a server span for each request named after the
+// controller and action handling it. The span continues the trace described by the request
+// traceparent and tracestate headers if any. The middleware adds the "trace_id" and "span_id" keys
+// to the request logger context so that all log entries of the request are correlated with the
+// trace. Mount it after the RequestID and before the LogRequest middlewares to get the IDs in the
+// request logs.
+func Middleware(tracer *Tracer) shogoa.Middleware {
+	return func(h shogoa.Handler) shogoa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if sc, ok := Extract(req.Header); ok {
+				ctx = WithRemoteSpanContext(ctx, sc)
+			}
+			ctrl, action := shogoa.ContextController(ctx), shogoa.ContextAction(ctx)
+			ctx, span := tracer.Start(ctx, ctrl+"."+action, SpanKindServer)
+			defer span.End()
+			span.SetAttribute("http.method", req.Method)
+			span.SetAttribute("http.target", req.URL.RequestURI())
+			span.SetAttribute("shogoa.controller", ctrl)
+			span.SetAttribute("shogoa.action", action)
+			ctx = shogoa.WithLogContext(ctx,
+				"trace_id", span.SpanContext.TraceID.String(),
+				"span_id", span.SpanContext.SpanID.String())
+
+			err := h(ctx, rw, req)
+
+			status := 0
+			if resp := shogoa.ContextResponse(ctx); resp != nil {
+				status = resp.Status
+			}
+			if err != nil {
+				span.SetError(err)
+				if status == 0 {
+					status = http.StatusInternalServerError
+					var serr shogoa.ServiceError
+					if errors.As(err, &serr) {
+						status = serr.ResponseStatus()
+					}
+				}
+			}
+			if status != 0 {
+				span.SetAttribute("http.status_code", status)
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shogo82148/shogoa"
+)
+
+func TestMiddleware(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+	var logs bytes.Buffer
+	service := shogoa.New("test")
+	service.WithLogger(shogoa.NewLogger(slog.NewTextHandler(&logs, nil)))
+	service.Use(Middleware(tracer))
+	ctrl := service.NewController("bottle")
+
+	var child *Span
+	handler := ctrl.MuxHandler("show", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		shogoa.LogInfo(ctx, "handling")
+		var span *Span
+		_, span = tracer.Start(ctx, "query", SpanKindInternal)
+		span.End()
+		child = span
+		return shogoa.ErrNotFound("no bottle")
+	}, nil)
+
+	t.Run("continues the incoming trace", func(t *testing.T) {
+		exporter.Reset()
+		req := httptest.NewRequest("GET", "/bottles/1", nil)
+		req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		req = req.WithContext(service.Context)
+		handler(httptest.NewRecorder(), req, nil)
+
+		spans := exporter.Spans()
+		if len(spans) != 2 {
+			t.Fatalf("unexpected number of spans: %d", len(spans))
+		}
+		server := spans[1]
+		if server.Name != "bottle.show" || server.Kind != SpanKindServer {
+			t.Errorf("unexpected server span: %s %s", server.Name, server.Kind)
+		}
+		if server.SpanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("unexpected trace ID: %s", server.SpanContext.TraceID)
+		}
+		if server.ParentSpanID.String() != "00f067aa0ba902b7" {
+			t.Errorf("unexpected parent span ID: %s", server.ParentSpanID)
+		}
+		if server.Attributes["http.status_code"] != http.StatusNotFound || server.Err == nil {
+			t.Errorf("unexpected server span status: %v %v", server.Attributes["http.status_code"], server.Err)
+		}
+		if child != spans[0] || child.ParentSpanID != server.SpanContext.SpanID {
+			t.Errorf("unexpected child span: %+v", child)
+		}
+		if want := "trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=" + server.SpanContext.SpanID.String(); !strings.Contains(logs.String(), want) {
+			t.Errorf("missing %q in logs:\n%s", want, logs.String())
+		}
+	})
+
+	t.Run("starts a new trace", func(t *testing.T) {
+		exporter.Reset()
+		handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/bottles/1", nil), nil)
+		spans := exporter.Spans()
+		if len(spans) != 2 {
+			t.Fatalf("unexpected number of spans: %d", len(spans))
+		}
+		if server := spans[1]; !server.SpanContext.IsValid() || server.ParentSpanID.IsValid() {
+			t.Errorf("unexpected root span: %+v", server)
+		}
+	})
+
+	t.Run("does not export unsampled traces", func(t *testing.T) {
+		exporter.Reset()
+		req := httptest.NewRequest("GET", "/bottles/1", nil)
+		req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+		handler(httptest.NewRecorder(), req, nil)
+		if spans := exporter.Spans(); len(spans) != 0 {
+			t.Errorf("unexpected spans: %v", spans)
+		}
+	})
+}
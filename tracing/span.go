@@ -0,0 +1,188 @@
+package tracing
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// List of span kinds.
+const (
+	// SpanKindServer is the kind of spans that cover the handling of a request by a server.
+	SpanKindServer SpanKind = "server"
+	// SpanKindClient is the kind of spans that cover a request made by a client.
+	SpanKindClient SpanKind = "client"
+	// SpanKindInternal is the kind of spans that cover an operation internal to the service.
+	SpanKindInternal SpanKind = "internal"
+)
+
+type (
+	// SpanKind describes the relationship between a span and its parent.
+	SpanKind string
+
+	// Span describes an operation within a trace.
+	Span struct {
+		// Name is the span name.
+		Name string
+		// Kind is the span kind.
+		Kind SpanKind
+		// SpanContext identifies the span.
+		SpanContext SpanContext
+		// ParentSpanID is the ID of the parent span, it is invalid for root spans.
+		ParentSpanID SpanID
+		// StartTime is the time the span started.
+		StartTime time.Time
+		// EndTime is the time the span ended.
+		EndTime time.Time
+		// Attributes describe the operation.
+		Attributes map[string]any
+		// Err is the error that caused the operation to fail if any.
+		Err error
+
+		tracer *Tracer
+		mu     sync.Mutex
+		ended  bool
+	}
+
+	// Exporter exports finished spans, for example to a tracing backend. ExportSpan is called
+	// synchronously when a sampled span ends, implementations should not block and must not modify
+	// the span.
+	Exporter interface {
+		ExportSpan(span *Span)
+	}
+
+	// Tracer creates spans and exports them when they end.
+	Tracer struct {
+		exporter Exporter
+	}
+
+	// InMemoryExporter is an exporter that keeps spans in memory, it is mainly intended for tests.
+	InMemoryExporter struct {
+		mu    sync.Mutex
+		spans []*Span
+	}
+
+	// key is the type of the context keys used by the package.
+	key int
+)
+
+const (
+	spanKey key = iota + 1
+	remoteKey
+)
+
+// NewTracer creates a tracer that exports spans with the given exporter. The exporter may be nil
+// in which case the spans are only used to propagate the trace.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// Start starts a span and returns a context containing it. The span is a child of the span in ctx
+// if any or of the remote span context set with WithRemoteSpanContext, it starts a new sampled
+// trace otherwise. Callers must call End when the operation completes.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	span := &Span{
+		Name:      name,
+		Kind:      kind,
+		StartTime: time.Now(),
+		tracer:    t,
+	}
+	if parent := ContextSpanContext(ctx); parent.IsValid() {
+		span.SpanContext = parent
+		span.ParentSpanID = parent.SpanID
+	} else {
+		span.SpanContext = SpanContext{TraceID: NewTraceID(), Flags: FlagSampled}
+	}
+	span.SpanContext.SpanID = NewSpanID()
+	return context.WithValue(ctx, spanKey, span), span
+}
+
+// SetAttribute sets an attribute of the span.
+func (s *Span) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]any)
+	}
+	s.Attributes[key] = value
+}
+
+// SetError records the error that caused the operation to fail.
+func (s *Span) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Err = err
+}
+
+// End ends the span and exports it if it is sampled. Calling End more than once has no effect.
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	if s.tracer != nil && s.tracer.exporter != nil && s.SpanContext.IsSampled() {
+		s.tracer.exporter.ExportSpan(s)
+	}
+}
+
+// Duration returns the duration of the span.
+func (s *Span) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// ContextSpan returns the span in ctx if any, nil otherwise.
+func ContextSpan(ctx context.Context) *Span {
+	if s := ctx.Value(spanKey); s != nil {
+		return s.(*Span)
+	}
+	return nil
+}
+
+// WithRemoteSpanContext returns a context containing the span context received from a remote
+// process, spans started from that context are its children.
+func WithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey, sc)
+}
+
+// ContextSpanContext returns the span context of the span in ctx if any, the remote span context
+// set with WithRemoteSpanContext otherwise. The returned span context is invalid if there is none.
+func ContextSpanContext(ctx context.Context) SpanContext {
+	if s := ContextSpan(ctx); s != nil {
+		return s.SpanContext
+	}
+	if sc, ok := ctx.Value(remoteKey).(SpanContext); ok {
+		return sc
+	}
+	return SpanContext{}
+}
+
+// NewInMemoryExporter returns an exporter that keeps spans in memory.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan records the span.
+func (e *InMemoryExporter) ExportSpan(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the spans exported so far in the order they ended.
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.spans)
+}
+
+// Reset discards the spans exported so far.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
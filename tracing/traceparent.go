@@ -0,0 +1,198 @@
+// Package tracing implements W3C Trace Context (https://www.w3.org/TR/trace-context/) propagation
+// and a minimal span model for shogoa services and clients.
+//
+// The Middleware function returns a shogoa middleware that continues the trace described by the
+// incoming traceparent and tracestate headers - or starts a new one - creates a server span for
+// each request and adds the trace and span IDs to the request logger context. The client package
+// propagates the trace of the request context to outgoing requests. Finished spans are exported
+// through the Exporter interface, InMemoryExporter keeps them in memory for tests.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceparentHeader is the name of the header that carries the trace ID, the parent span ID
+	// and the trace flags.
+	TraceparentHeader = "Traceparent"
+	// TracestateHeader is the name of the header that carries vendor specific trace data.
+	TracestateHeader = "Tracestate"
+
+	// FlagSampled is the trace flag set when the caller may have recorded the trace.
+	FlagSampled byte = 0x01
+
+	// maxTracestateMembers is the maximum number of list members in tracestate.
+	maxTracestateMembers = 32
+)
+
+type (
+	// TraceID identifies a trace.
+	TraceID [16]byte
+
+	// SpanID identifies a span within a trace.
+	SpanID [8]byte
+
+	// SpanContext is the part of a span that is propagated across process boundaries.
+	SpanContext struct {
+		// TraceID is the ID of the trace the span belongs to.
+		TraceID TraceID
+		// SpanID is the ID of the span.
+		SpanID SpanID
+		// Flags are the trace flags, see FlagSampled.
+		Flags byte
+		// TraceState is the value of the tracestate header.
+		TraceState string
+	}
+)
+
+// NewTraceID returns a random trace ID.
+func NewTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+// NewSpanID returns a random span ID.
+func NewSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+// IsValid returns true if the trace ID is not all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String returns the lowercase hex encoding of the trace ID.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid returns true if the span ID is not all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String returns the lowercase hex encoding of the span ID.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid returns true if both the trace and span IDs are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled returns true if the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent returns the value of the traceparent header for the span context.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent parses the value of a traceparent header. The trace state of the returned span
+// context is empty.
+func ParseTraceparent(v string) (SpanContext, error) {
+	var sc SpanContext
+	v = strings.TrimSpace(v)
+	if len(v) < 55 || v[2] != '-' || v[35] != '-' || v[52] != '-' {
+		return sc, fmt.Errorf("invalid traceparent %#v", v)
+	}
+	version, err := decodeHex(v[0:2])
+	if err != nil || version[0] == 0xff {
+		return sc, fmt.Errorf("invalid traceparent version %#v", v[0:2])
+	}
+	// Future versions may append fields, version 00 may not.
+	if len(v) > 55 && (version[0] == 0 || v[55] != '-') {
+		return sc, fmt.Errorf("invalid traceparent %#v", v)
+	}
+	traceID, err := decodeHex(v[3:35])
+	if err != nil {
+		return sc, fmt.Errorf("invalid trace ID %#v", v[3:35])
+	}
+	spanID, err := decodeHex(v[36:52])
+	if err != nil {
+		return sc, fmt.Errorf("invalid parent ID %#v", v[36:52])
+	}
+	flags, err := decodeHex(v[53:55])
+	if err != nil {
+		return sc, fmt.Errorf("invalid trace flags %#v", v[53:55])
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return sc, errors.New("invalid traceparent, trace and parent IDs may not be all zeros")
+	}
+	return sc, nil
+}
+
+// ParseTracestate validates and normalizes the value of the tracestate header. It returns an empty
+// string if the value is invalid, in which case the header must be discarded.
+func ParseTracestate(v string) string {
+	members := make([]string, 0, 4)
+	for _, m := range strings.Split(v, ",") {
+		m = strings.Trim(m, " \t")
+		if m == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(m, "=")
+		if !ok || key == "" || value == "" || len(key) > 256 || len(value) > 256 {
+			return ""
+		}
+		if strings.ContainsAny(key, " \t") || strings.ContainsAny(value, ",=") {
+			return ""
+		}
+		members = append(members, m)
+	}
+	if len(members) > maxTracestateMembers {
+		return ""
+	}
+	return strings.Join(members, ",")
+}
+
+// Extract returns the span context described by the traceparent and tracestate headers. It returns
+// false if there is no valid traceparent header.
+func Extract(h http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(h.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = ParseTracestate(strings.Join(h.Values(TracestateHeader), ","))
+	return sc, true
+}
+
+// Inject sets the traceparent and tracestate headers describing the span context.
+func Inject(sc SpanContext, h http.Header) {
+	if !sc.IsValid() {
+		return
+	}
+	h.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		h.Set(TracestateHeader, sc.TraceState)
+	} else {
+		h.Del(TracestateHeader)
+	}
+}
+
+// decodeHex decodes lowercase hexadecimal strings.
+func decodeHex(s string) ([]byte, error) {
+	if strings.ToLower(s) != s {
+		return nil, errors.New("hex string must be lowercase")
+	}
+	return hex.DecodeString(s)
+}
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	valid := []struct {
+		value   string
+		traceID string
+		spanID  string
+		sampled bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", false},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+	}
+	for _, c := range valid {
+		t.Run(c.value, func(t *testing.T) {
+			sc, err := ParseTraceparent(c.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sc.TraceID.String() != c.traceID || sc.SpanID.String() != c.spanID || sc.IsSampled() != c.sampled {
+				t.Errorf("unexpected span context: %+v", sc)
+			}
+		})
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01",
+	}
+	for _, v := range invalid {
+		t.Run(v, func(t *testing.T) {
+			if _, err := ParseTraceparent(v); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseTracestate(t *testing.T) {
+	cases := map[string]string{
+		"rojo=00f067aa0ba902b7":                  "rojo=00f067aa0ba902b7",
+		"rojo=00f067aa0ba902b7 ,, congo=t61rcWk": "rojo=00f067aa0ba902b7,congo=t61rcWk",
+		"rojo":                                   "",
+		"=value":                                 "",
+		"ro jo=1":                                "",
+	}
+	for in, want := range cases {
+		if got := ParseTracestate(in); got != want {
+			t.Errorf("ParseTracestate(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	sc := SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID(), Flags: FlagSampled, TraceState: "congo=t61rcWk"}
+	h := make(http.Header)
+	Inject(sc, h)
+	got, ok := Extract(h)
+	if !ok {
+		t.Fatalf("failed to extract %v", h)
+	}
+	if got != sc {
+		t.Errorf("unexpected span context: want %+v, got %+v", sc, got)
+	}
+
+	h = make(http.Header)
+	Inject(SpanContext{}, h)
+	if len(h) != 0 {
+		t.Errorf("invalid span context injected: %v", h)
+	}
+}